@@ -0,0 +1,163 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"task-manager/entity"
+)
+
+type AttachmentService interface {
+	TaskAttachments(ctx context.Context, taskID int64) ([]entity.Attachment, error)
+	UploadAttachment(ctx context.Context, taskID int64, name string, r io.Reader) (entity.Attachment, error)
+	DownloadAttachment(ctx context.Context, taskID int64, attachmentID int64) (entity.Attachment, io.ReadCloser, error)
+	DeleteAttachment(ctx context.Context, taskID int64, attachmentID int64) error
+}
+
+type AttachmentHandler struct {
+	attachment AttachmentService
+}
+
+func NewAttachmentHandler(attachment AttachmentService) *AttachmentHandler {
+	return &AttachmentHandler{attachment: attachment}
+}
+
+// multipartOverhead is room for multipart headers and boundaries on top of the file size limit.
+const multipartOverhead = 1 << 20
+
+func (h *AttachmentHandler) TaskAttachments(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	taskID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		sendError(ctx, w, entity.ErrBadRequest)
+		return
+	}
+
+	attachments, err := h.attachment.TaskAttachments(ctx, taskID)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	if attachments == nil {
+		attachments = []entity.Attachment{}
+	}
+
+	sendResponse(w, attachments)
+}
+
+// UploadAttachment streams the "file" part of a multipart/form-data request to the storage without buffering it.
+func (h *AttachmentHandler) UploadAttachment(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	taskID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		sendError(ctx, w, entity.ErrBadRequest)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, entity.MaxAttachmentSize+multipartOverhead)
+
+	mr, err := r.MultipartReader()
+	if err != nil {
+		sendError(ctx, w, fmt.Errorf("%w: %w", entity.ErrBadRequest, err))
+		return
+	}
+
+	for {
+		part, err := mr.NextPart()
+		if errors.Is(err, io.EOF) {
+			sendError(ctx, w, fmt.Errorf("%w: file part is missing", entity.ErrBadRequest))
+			return
+		}
+
+		if err != nil {
+			sendError(ctx, w, uploadError(err))
+			return
+		}
+
+		if part.FormName() != "file" {
+			continue
+		}
+
+		attachment, err := h.attachment.UploadAttachment(ctx, taskID, part.FileName(), part)
+		if err != nil {
+			sendError(ctx, w, uploadError(err))
+			return
+		}
+
+		sendResponse(w, attachment)
+		return
+	}
+}
+
+// uploadError reports hitting the request body limit as a bad request.
+func uploadError(err error) error {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return fmt.Errorf("%w: file is larger than %d bytes", entity.ErrBadRequest, entity.MaxAttachmentSize)
+	}
+
+	return err
+}
+
+func (h *AttachmentHandler) DownloadAttachment(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	taskID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		sendError(ctx, w, entity.ErrBadRequest)
+		return
+	}
+
+	attachmentID, err := strconv.ParseInt(r.PathValue("attachment_id"), 10, 64)
+	if err != nil {
+		sendError(ctx, w, entity.ErrBadRequest)
+		return
+	}
+
+	attachment, content, err := h.attachment.DownloadAttachment(ctx, taskID, attachmentID)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+	defer content.Close()
+
+	w.Header().Set("Content-Type", attachment.ContentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(attachment.Size, 10))
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Name}))
+
+	_, err = io.Copy(w, content)
+	if err != nil {
+		entity.CtxLogger(ctx).Error("attachment download error", "error", err)
+	}
+}
+
+func (h *AttachmentHandler) DeleteAttachment(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	taskID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		sendError(ctx, w, entity.ErrBadRequest)
+		return
+	}
+
+	attachmentID, err := strconv.ParseInt(r.PathValue("attachment_id"), 10, 64)
+	if err != nil {
+		sendError(ctx, w, entity.ErrBadRequest)
+		return
+	}
+
+	err = h.attachment.DeleteAttachment(ctx, taskID, attachmentID)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,132 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"task-manager/entity"
+)
+
+type CommentService interface {
+	TaskComments(ctx context.Context, taskID int64, pr entity.PageRequest) (entity.Page[entity.Comment], error)
+	CreateComment(ctx context.Context, taskID int64, body string) (entity.Comment, error)
+	UpdateComment(ctx context.Context, taskID int64, commentID int64, body string) (entity.Comment, error)
+	DeleteComment(ctx context.Context, taskID int64, commentID int64) error
+}
+
+type CommentHandler struct {
+	comment CommentService
+}
+
+func NewCommentHandler(comment CommentService) *CommentHandler {
+	return &CommentHandler{comment: comment}
+}
+
+type CommentRequest struct {
+	Body string `json:"body"`
+}
+
+func (h *CommentHandler) TaskComments(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	taskID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		sendError(ctx, w, entity.ErrBadRequest)
+		return
+	}
+
+	pr, err := pageRequest(r)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	comments, err := h.comment.TaskComments(ctx, taskID, pr)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	sendPage(w, comments)
+}
+
+func (h *CommentHandler) CreateComment(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	taskID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		sendError(ctx, w, entity.ErrBadRequest)
+		return
+	}
+
+	var request CommentRequest
+	err = json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	comment, err := h.comment.CreateComment(ctx, taskID, request.Body)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	sendResponse(w, comment)
+}
+
+func (h *CommentHandler) UpdateComment(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	taskID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		sendError(ctx, w, entity.ErrBadRequest)
+		return
+	}
+
+	commentID, err := strconv.ParseInt(r.PathValue("comment_id"), 10, 64)
+	if err != nil {
+		sendError(ctx, w, entity.ErrBadRequest)
+		return
+	}
+
+	var request CommentRequest
+	err = json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	comment, err := h.comment.UpdateComment(ctx, taskID, commentID, request.Body)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	sendResponse(w, comment)
+}
+
+func (h *CommentHandler) DeleteComment(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	taskID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		sendError(ctx, w, entity.ErrBadRequest)
+		return
+	}
+
+	commentID, err := strconv.ParseInt(r.PathValue("comment_id"), 10, 64)
+	if err != nil {
+		sendError(ctx, w, entity.ErrBadRequest)
+		return
+	}
+
+	err = h.comment.DeleteComment(ctx, taskID, commentID)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
@@ -3,7 +3,9 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"strconv"
 	"task-manager/entity"
@@ -11,13 +13,17 @@ import (
 
 type ProjectService interface {
 	CreateProject(ctx context.Context, project entity.Project) (entity.Project, error)
+	CloneProject(ctx context.Context, projectID int64, clone entity.ProjectClone) (entity.Project, error)
 	DeleteProject(ctx context.Context, projectID int64) error
 
 	ProjectByID(ctx context.Context, id int64) (entity.Project, error)
-	UserProjects(ctx context.Context) ([]entity.Project, error)
+	UserProjects(ctx context.Context, pr entity.PageRequest) (entity.Page[entity.Project], error)
+	ProjectHistory(ctx context.Context, projectID int64, pr entity.PageRequest) (entity.Page[entity.Change], error)
+	ProjectActivity(ctx context.Context, projectID int64, pr entity.PageRequest) (entity.Page[entity.Activity], error)
+	UserActivity(ctx context.Context, pr entity.PageRequest) (entity.Page[entity.Activity], error)
 
 	AddProjectMember(ctx context.Context, code string) error
-	InviteMemberRequest(ctx context.Context, projectID int64, email string) error
+	InviteMemberRequest(ctx context.Context, projectID int64, email string, role entity.Role) error
 	SendInvite(ctx context.Context, email string, code string, projectName string) error
 }
 
@@ -49,16 +55,48 @@ func (h *ProjectHandler) CreateProject(w http.ResponseWriter, r *http.Request) {
 	sendResponse(w, project)
 }
 
+func (h *ProjectHandler) CloneProject(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	projectID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		sendError(ctx, w, entity.ErrBadRequest)
+		return
+	}
+
+	// the body with clone options is optional
+	var clone entity.ProjectClone
+	err = json.NewDecoder(r.Body).Decode(&clone)
+	if err != nil && !errors.Is(err, io.EOF) {
+		sendError(ctx, w, err)
+		return
+	}
+
+	project, err := h.project.CloneProject(ctx, projectID, clone)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	sendResponse(w, project)
+}
+
 func (h *ProjectHandler) UserProjects(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
-	projects, err := h.project.UserProjects(ctx)
+	pr, err := pageRequest(r)
 	if err != nil {
 		sendError(ctx, w, err)
 		return
 	}
 
-	sendResponse(w, projects)
+	projects, err := h.project.UserProjects(ctx, pr)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	sendPage(w, projects)
 }
 
 func (h *ProjectHandler) ProjectByID(w http.ResponseWriter, r *http.Request) {
@@ -80,6 +118,72 @@ func (h *ProjectHandler) ProjectByID(w http.ResponseWriter, r *http.Request) {
 	sendResponse(w, project)
 }
 
+func (h *ProjectHandler) ProjectHistory(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	projectID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		sendError(ctx, w, entity.ErrBadRequest)
+		return
+	}
+
+	pr, err := pageRequest(r)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	changes, err := h.project.ProjectHistory(ctx, projectID, pr)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	sendPage(w, changes)
+}
+
+func (h *ProjectHandler) ProjectActivity(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	projectID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		sendError(ctx, w, entity.ErrBadRequest)
+		return
+	}
+
+	pr, err := pageRequest(r)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	activities, err := h.project.ProjectActivity(ctx, projectID, pr)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	sendPage(w, activities)
+}
+
+func (h *ProjectHandler) UserActivity(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	pr, err := pageRequest(r)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	activities, err := h.project.UserActivity(ctx, pr)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	sendPage(w, activities)
+}
+
 func (h *ProjectHandler) DeleteProject(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
@@ -100,8 +204,9 @@ func (h *ProjectHandler) DeleteProject(w http.ResponseWriter, r *http.Request) {
 }
 
 type InviteMemberRequest struct {
-	ProjectID int64  `json:"project_id"`
-	Email     string `json:"email"`
+	ProjectID int64       `json:"project_id"`
+	Email     string      `json:"email"`
+	Role      entity.Role `json:"role"`
 }
 
 func (h *ProjectHandler) AcceptProjectInvitation(w http.ResponseWriter, r *http.Request) {
@@ -128,7 +233,7 @@ func (h *ProjectHandler) InviteMember(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = h.project.InviteMemberRequest(ctx, request.ProjectID, request.Email)
+	err = h.project.InviteMemberRequest(ctx, request.ProjectID, request.Email, request.Role)
 	if err != nil {
 		sendError(ctx, w, err)
 		return
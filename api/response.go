@@ -4,7 +4,9 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
 	"task-manager/entity"
 )
 
@@ -49,3 +51,41 @@ func sendResponse(w http.ResponseWriter, v any) {
 		w.WriteHeader(http.StatusInternalServerError)
 	}
 }
+
+// Page is an envelope of every paginated listing, NextCursor is null on the last page.
+type Page[T any] struct {
+	Items      []T     `json:"items"`
+	NextCursor *string `json:"next_cursor"`
+}
+
+func sendPage[T any](w http.ResponseWriter, p entity.Page[T]) {
+	page := Page[T]{Items: p.Items}
+
+	if page.Items == nil {
+		page.Items = []T{}
+	}
+
+	if p.NextCursor != "" {
+		page.NextCursor = &p.NextCursor
+	}
+
+	sendResponse(w, page)
+}
+
+// pageRequest reads limit and cursor query parameters.
+func pageRequest(r *http.Request) (entity.PageRequest, error) {
+	query := r.URL.Query()
+
+	pr := entity.PageRequest{Cursor: query.Get("cursor")}
+
+	if v := query.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > entity.MaxPageLimit {
+			return entity.PageRequest{}, fmt.Errorf("%w: limit must be from 1 to %d", entity.ErrBadRequest, entity.MaxPageLimit)
+		}
+
+		pr.Limit = limit
+	}
+
+	return pr, nil
+}
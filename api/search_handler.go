@@ -0,0 +1,49 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"task-manager/entity"
+)
+
+type SearchService interface {
+	Search(ctx context.Context, text string, pr entity.PageRequest) ([]entity.SearchResult, error)
+}
+
+type SearchHandler struct {
+	search SearchService
+}
+
+func NewSearchHandler(search SearchService) *SearchHandler {
+	return &SearchHandler{search: search}
+}
+
+// Search returns the most relevant results only, the limit parameter caps their number.
+// Results aren't paged, so a cursor is rejected rather than ignored.
+func (h *SearchHandler) Search(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	pr, err := pageRequest(r)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	if pr.Cursor != "" {
+		sendError(ctx, w, fmt.Errorf("%w: search results aren't paged, use limit", entity.ErrBadRequest))
+		return
+	}
+
+	results, err := h.search.Search(ctx, r.URL.Query().Get("q"), pr)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	if results == nil {
+		results = []entity.SearchResult{}
+	}
+
+	sendResponse(w, results)
+}
@@ -12,11 +12,16 @@ type Server struct {
 	projHdr *ProjectHandler
 	userHdr *UserHandler
 	authHdr *AuthHandler
+	srchHdr *SearchHandler
+	cmntHdr *CommentHandler
+	atchHdr *AttachmentHandler
+	timeHdr *TimeHandler
+	tmplHdr *TemplateHandler
 	mw      *Middleware
 }
 
 // NewServer returns http router to work with.
-func NewServer(t *TaskHandler, p *ProjectHandler, u *UserHandler, a *AuthHandler, port string, mw *Middleware) *Server {
+func NewServer(t *TaskHandler, p *ProjectHandler, u *UserHandler, a *AuthHandler, sr *SearchHandler, c *CommentHandler, at *AttachmentHandler, tm *TimeHandler, tp *TemplateHandler, port string, mw *Middleware) *Server {
 	return &Server{
 		port:    port,
 		router:  http.NewServeMux(),
@@ -24,6 +29,11 @@ func NewServer(t *TaskHandler, p *ProjectHandler, u *UserHandler, a *AuthHandler
 		projHdr: p,
 		userHdr: u,
 		authHdr: a,
+		srchHdr: sr,
+		cmntHdr: c,
+		atchHdr: at,
+		timeHdr: tm,
+		tmplHdr: tp,
 		mw:      mw,
 	}
 }
@@ -35,6 +45,7 @@ func (s *Server) setRoutes() {
 	//s.router.HandleFunc("DELETE /users/{id}", s.h.EditUser)
 	s.router.HandleFunc("GET /users/{id}", s.userHdr.UserByID)
 	s.router.Handle("GET /projects/{project_id}/users", s.mw.Auth(s.userHdr.ProjectUsers))
+	s.router.Handle("PUT /projects/{project_id}/users/{user_id}/role", s.mw.Auth(s.userHdr.ChangeMemberRole))
 
 	// auth routes
 	s.router.HandleFunc("POST /users", s.authHdr.Registration)
@@ -44,8 +55,12 @@ func (s *Server) setRoutes() {
 	// project routes
 	s.router.Handle("POST /projects", s.mw.Auth(s.projHdr.CreateProject))
 	s.router.Handle("DELETE /projects/{id}", s.mw.Auth(s.projHdr.DeleteProject))
+	s.router.Handle("POST /projects/{id}/clone", s.mw.Auth(s.projHdr.CloneProject))
 	s.router.Handle("GET /projects", s.mw.Auth(s.projHdr.UserProjects))
 	s.router.Handle("GET /projects/{id}", s.mw.Auth(s.projHdr.ProjectByID))
+	s.router.Handle("GET /projects/{id}/history", s.mw.Auth(s.projHdr.ProjectHistory))
+	s.router.Handle("GET /projects/{id}/activity", s.mw.Auth(s.projHdr.ProjectActivity))
+	s.router.Handle("GET /activity", s.mw.Auth(s.projHdr.UserActivity))
 	//s.router.HandleFunc("POST /projects", s.h.EditProject)
 	s.router.HandleFunc("GET /projects/invite", s.projHdr.AcceptProjectInvitation)
 	s.router.Handle("POST /projects/invite", s.mw.Auth(s.projHdr.InviteMember))
@@ -53,8 +68,82 @@ func (s *Server) setRoutes() {
 	// task routes
 	s.router.Handle("POST /tasks", s.mw.Auth(s.taskHdr.CreateTask))
 	s.router.Handle("GET /tasks/{id}", s.mw.Auth(s.taskHdr.TaskByID))
+	s.router.Handle("PUT /tasks/{id}", s.mw.Auth(s.taskHdr.UpdateTask))
+	s.router.Handle("PATCH /tasks/{id}", s.mw.Auth(s.taskHdr.UpdateTask))
+	s.router.Handle("DELETE /tasks/{id}", s.mw.Auth(s.taskHdr.DeleteTask))
 	s.router.Handle("GET /projects/{project_id}/tasks", s.mw.Auth(s.taskHdr.ProjectTasks))
 	s.router.Handle("GET /tasks", s.mw.Auth(s.taskHdr.UserTasks))
+	s.router.Handle("PATCH /tasks/{id}/status", s.mw.Auth(s.taskHdr.ChangeTaskStatus))
+	s.router.Handle("POST /tasks/{id}/move", s.mw.Auth(s.taskHdr.MoveTask))
+	s.router.Handle("POST /tasks/transfer", s.mw.Auth(s.taskHdr.TransferTasks))
+	s.router.Handle("POST /tasks/{id}/assignees", s.mw.Auth(s.taskHdr.AssignTask))
+	s.router.Handle("DELETE /tasks/{id}/assignees/{user_id}", s.mw.Auth(s.taskHdr.UnassignTask))
+	s.router.Handle("GET /tasks/{id}/subtasks", s.mw.Auth(s.taskHdr.ChildTasks))
+	s.router.Handle("GET /tasks/{id}/tree", s.mw.Auth(s.taskHdr.TaskTree))
+	s.router.Handle("GET /tasks/{id}/history", s.mw.Auth(s.taskHdr.TaskHistory))
+	s.router.Handle("GET /tasks/{id}/recurrence", s.mw.Auth(s.taskHdr.TaskRecurrence))
+	s.router.Handle("PUT /tasks/{id}/recurrence", s.mw.Auth(s.taskHdr.SetTaskRecurrence))
+	s.router.Handle("DELETE /tasks/{id}/recurrence", s.mw.Auth(s.taskHdr.DeleteTaskRecurrence))
+	s.router.Handle("POST /tasks/{id}/blockers", s.mw.Auth(s.taskHdr.AddBlocker))
+	s.router.Handle("DELETE /tasks/{id}/blockers/{blocker_id}", s.mw.Auth(s.taskHdr.RemoveBlocker))
+	s.router.Handle("GET /projects/{project_id}/workflow", s.mw.Auth(s.taskHdr.ProjectWorkflow))
+	s.router.Handle("PUT /projects/{project_id}/workflow", s.mw.Auth(s.taskHdr.UpdateProjectWorkflow))
+
+	// comment routes
+	s.router.Handle("GET /tasks/{id}/comments", s.mw.Auth(s.cmntHdr.TaskComments))
+	s.router.Handle("POST /tasks/{id}/comments", s.mw.Auth(s.cmntHdr.CreateComment))
+	s.router.Handle("PATCH /tasks/{id}/comments/{comment_id}", s.mw.Auth(s.cmntHdr.UpdateComment))
+	s.router.Handle("DELETE /tasks/{id}/comments/{comment_id}", s.mw.Auth(s.cmntHdr.DeleteComment))
+
+	// attachment routes
+	s.router.Handle("GET /tasks/{id}/attachments", s.mw.Auth(s.atchHdr.TaskAttachments))
+	s.router.Handle("POST /tasks/{id}/attachments", s.mw.Auth(s.atchHdr.UploadAttachment))
+	s.router.Handle("GET /tasks/{id}/attachments/{attachment_id}", s.mw.Auth(s.atchHdr.DownloadAttachment))
+	s.router.Handle("DELETE /tasks/{id}/attachments/{attachment_id}", s.mw.Auth(s.atchHdr.DeleteAttachment))
+
+	// time tracking routes
+	s.router.Handle("POST /tasks/{id}/timer", s.mw.Auth(s.timeHdr.StartTimer))
+	s.router.Handle("GET /timer", s.mw.Auth(s.timeHdr.RunningTimer))
+	s.router.Handle("POST /timer/stop", s.mw.Auth(s.timeHdr.StopTimer))
+	s.router.Handle("GET /tasks/{id}/time-entries", s.mw.Auth(s.timeHdr.TaskTimeEntries))
+	s.router.Handle("POST /tasks/{id}/time-entries", s.mw.Auth(s.timeHdr.CreateTimeEntry))
+	s.router.Handle("DELETE /tasks/{id}/time-entries/{entry_id}", s.mw.Auth(s.timeHdr.DeleteTimeEntry))
+	s.router.Handle("GET /tasks/{id}/time-total", s.mw.Auth(s.timeHdr.TaskTimeTotal))
+	s.router.Handle("GET /projects/{project_id}/time-total", s.mw.Auth(s.timeHdr.ProjectTimeTotal))
+	s.router.Handle("GET /time-total", s.mw.Auth(s.timeHdr.UserTimeTotal))
+
+	// template routes
+	s.router.Handle("GET /templates", s.mw.Auth(s.tmplHdr.UserTemplates))
+	s.router.Handle("POST /templates", s.mw.Auth(s.tmplHdr.CreateTemplate))
+	s.router.Handle("GET /templates/{id}", s.mw.Auth(s.tmplHdr.TemplateByID))
+	s.router.Handle("DELETE /templates/{id}", s.mw.Auth(s.tmplHdr.DeleteTemplate))
+	s.router.Handle("POST /projects/{project_id}/templates", s.mw.Auth(s.tmplHdr.SaveProjectTemplate))
+	s.router.Handle("POST /templates/{id}/projects", s.mw.Auth(s.tmplHdr.CreateProjectFromTemplate))
+	s.router.Handle("POST /templates/{id}/tasks", s.mw.Auth(s.tmplHdr.ApplyTemplate))
+
+	// label routes
+	s.router.Handle("GET /projects/{project_id}/labels", s.mw.Auth(s.taskHdr.ProjectLabels))
+	s.router.Handle("POST /projects/{project_id}/labels", s.mw.Auth(s.taskHdr.CreateLabel))
+	s.router.Handle("PATCH /projects/{project_id}/labels/{label_id}", s.mw.Auth(s.taskHdr.UpdateLabel))
+	s.router.Handle("DELETE /projects/{project_id}/labels/{label_id}", s.mw.Auth(s.taskHdr.DeleteLabel))
+	s.router.Handle("POST /tasks/{id}/labels", s.mw.Auth(s.taskHdr.AttachLabel))
+	s.router.Handle("DELETE /tasks/{id}/labels/{label_id}", s.mw.Auth(s.taskHdr.DetachLabel))
+
+	// checklist routes
+	s.router.Handle("GET /tasks/{id}/checklist", s.mw.Auth(s.taskHdr.TaskChecklist))
+	s.router.Handle("POST /tasks/{id}/checklist", s.mw.Auth(s.taskHdr.AddChecklistItem))
+	s.router.Handle("PUT /tasks/{id}/checklist/order", s.mw.Auth(s.taskHdr.ReorderChecklist))
+	s.router.Handle("POST /tasks/{id}/checklist/{item_id}/toggle", s.mw.Auth(s.taskHdr.ToggleChecklistItem))
+	s.router.Handle("DELETE /tasks/{id}/checklist/{item_id}", s.mw.Auth(s.taskHdr.DeleteChecklistItem))
+
+	// custom field routes
+	s.router.Handle("GET /projects/{project_id}/fields", s.mw.Auth(s.taskHdr.ProjectCustomFields))
+	s.router.Handle("POST /projects/{project_id}/fields", s.mw.Auth(s.taskHdr.CreateCustomField))
+	s.router.Handle("PATCH /projects/{project_id}/fields/{field_id}", s.mw.Auth(s.taskHdr.UpdateCustomField))
+	s.router.Handle("DELETE /projects/{project_id}/fields/{field_id}", s.mw.Auth(s.taskHdr.DeleteCustomField))
+
+	// search routes
+	s.router.Handle("GET /search", s.mw.Auth(s.srchHdr.Search))
 }
 
 func (s *Server) Start() error {
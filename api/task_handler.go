@@ -3,16 +3,56 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"task-manager/entity"
+	"time"
 )
 
 type TaskService interface {
 	CreateTask(ctx context.Context, cTask entity.TaskToCreate) (entity.Task, error)
 	TaskByID(ctx context.Context, id int64) (entity.Task, error)
-	ProjectTasks(ctx context.Context, projectID int64) ([]entity.Task, error)
-	UserTasks(ctx context.Context) ([]entity.Task, error)
+	ProjectTasks(ctx context.Context, projectID int64, tq entity.TaskQuery) (entity.Page[entity.Task], error)
+	UserTasks(ctx context.Context, tq entity.TaskQuery) (entity.Page[entity.Task], error)
+	UpdateTask(ctx context.Context, id int64, upd entity.TaskToUpdate) (entity.Task, error)
+	DeleteTask(ctx context.Context, id int64, subtasks string) error
+	ChildTasks(ctx context.Context, id int64, tq entity.TaskQuery) (entity.Page[entity.Task], error)
+	TaskTree(ctx context.Context, id int64) (entity.TaskTree, error)
+	AddBlocker(ctx context.Context, taskID int64, blockerID int64) (entity.Task, error)
+	RemoveBlocker(ctx context.Context, taskID int64, blockerID int64) error
+	AssignedTasks(ctx context.Context, tq entity.TaskQuery) (entity.Page[entity.Task], error)
+	AssignTask(ctx context.Context, taskID int64, userID int64) (entity.Task, error)
+	UnassignTask(ctx context.Context, taskID int64, userID int64) error
+	ChangeTaskStatus(ctx context.Context, id int64, status string) (entity.Task, error)
+	MoveTask(ctx context.Context, id int64, move entity.TaskMove) (entity.Task, error)
+	TransferTasks(ctx context.Context, transfer entity.TaskTransfer) ([]entity.Task, error)
+	SetTaskRecurrence(ctx context.Context, taskID int64, rule string) (entity.Recurrence, error)
+	TaskRecurrence(ctx context.Context, taskID int64) (entity.Recurrence, error)
+	DeleteTaskRecurrence(ctx context.Context, taskID int64) error
+	TaskHistory(ctx context.Context, taskID int64, pr entity.PageRequest) (entity.Page[entity.Change], error)
+
+	ProjectWorkflow(ctx context.Context, projectID int64) (entity.Workflow, error)
+	UpdateProjectWorkflow(ctx context.Context, workflow entity.Workflow) (entity.Workflow, error)
+
+	ProjectLabels(ctx context.Context, projectID int64) ([]entity.Label, error)
+	CreateLabel(ctx context.Context, label entity.Label) (entity.Label, error)
+	UpdateLabel(ctx context.Context, projectID int64, labelID int64, upd entity.LabelToUpdate) (entity.Label, error)
+	DeleteLabel(ctx context.Context, projectID int64, labelID int64) error
+	AttachLabel(ctx context.Context, taskID int64, labelID int64) (entity.Task, error)
+	DetachLabel(ctx context.Context, taskID int64, labelID int64) error
+
+	ProjectCustomFields(ctx context.Context, projectID int64) ([]entity.CustomField, error)
+	CreateCustomField(ctx context.Context, field entity.CustomField) (entity.CustomField, error)
+	UpdateCustomField(ctx context.Context, projectID int64, fieldID int64, upd entity.CustomFieldToUpdate) (entity.CustomField, error)
+	DeleteCustomField(ctx context.Context, projectID int64, fieldID int64) error
+
+	TaskChecklist(ctx context.Context, taskID int64) ([]entity.ChecklistItem, error)
+	AddChecklistItem(ctx context.Context, taskID int64, ic entity.ChecklistItemToCreate) (entity.ChecklistItem, error)
+	ToggleChecklistItem(ctx context.Context, taskID int64, itemID int64) (entity.ChecklistItem, error)
+	ReorderChecklist(ctx context.Context, taskID int64, order entity.ChecklistOrder) ([]entity.ChecklistItem, error)
+	DeleteChecklistItem(ctx context.Context, taskID int64, itemID int64) error
 }
 
 type TaskHandler struct {
@@ -62,6 +102,57 @@ func (h *TaskHandler) TaskByID(w http.ResponseWriter, r *http.Request) {
 	sendResponse(w, task)
 }
 
+// UpdateTask handles both PUT and PATCH, PUT requires every editable field to be present.
+func (h *TaskHandler) UpdateTask(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	qID := r.PathValue("id")
+	id, err := strconv.ParseInt(qID, 10, 64)
+	if err != nil {
+		sendError(ctx, w, entity.ErrBadRequest)
+		return
+	}
+
+	var upd entity.TaskToUpdate
+	err = json.NewDecoder(r.Body).Decode(&upd)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	if r.Method == http.MethodPut && (upd.Name == nil || upd.Description == nil) {
+		sendError(ctx, w, fmt.Errorf("%w: name and description are required", entity.ErrBadRequest))
+		return
+	}
+
+	task, err := h.task.UpdateTask(ctx, id, upd)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	sendResponse(w, task)
+}
+
+func (h *TaskHandler) DeleteTask(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	qID := r.PathValue("id")
+	id, err := strconv.ParseInt(qID, 10, 64)
+	if err != nil {
+		sendError(ctx, w, entity.ErrBadRequest)
+		return
+	}
+
+	err = h.task.DeleteTask(ctx, id, r.URL.Query().Get("subtasks"))
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
 func (h *TaskHandler) ProjectTasks(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	qID := r.PathValue("project_id")
@@ -72,19 +163,215 @@ func (h *TaskHandler) ProjectTasks(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	tasks, err := h.task.ProjectTasks(ctx, projectID)
+	tq, err := taskQuery(r)
 	if err != nil {
 		sendError(ctx, w, err)
 		return
 	}
 
-	sendResponse(w, tasks)
+	tasks, err := h.task.ProjectTasks(ctx, projectID, tq)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	sendPage(w, tasks)
 }
 
 func (h *TaskHandler) UserTasks(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
-	tasks, err := h.task.UserTasks(ctx)
+	tq, err := taskQuery(r)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	var tasks entity.Page[entity.Task]
+
+	switch r.URL.Query().Get("assignee") {
+	case "":
+		tasks, err = h.task.UserTasks(ctx, tq)
+	case "me":
+		tasks, err = h.task.AssignedTasks(ctx, tq)
+	default:
+		err = fmt.Errorf("%w: only assignee=me is supported", entity.ErrBadRequest)
+	}
+
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	sendPage(w, tasks)
+}
+
+// taskQuery reads task listing parameters from the query string, times are RFC 3339 with a zone offset.
+func taskQuery(r *http.Request) (entity.TaskQuery, error) {
+	query := r.URL.Query()
+
+	pr, err := pageRequest(r)
+	if err != nil {
+		return entity.TaskQuery{}, err
+	}
+
+	tq := entity.TaskQuery{Sort: query.Get("sort"), Page: pr}
+
+	// filter=field:op:value, e.g. filter=name:contains:report or filter=created_at:gte:2024-05-01T00:00:00Z
+	for _, v := range query["filter"] {
+		parts := strings.SplitN(v, ":", 3)
+		if len(parts) != 3 {
+			return entity.TaskQuery{}, fmt.Errorf("%w: filter must look like field:op:value", entity.ErrBadRequest)
+		}
+
+		tq.Filters = append(tq.Filters, entity.Filter{Field: parts[0], Op: parts[1], Value: parts[2]})
+	}
+
+	switch query.Get("order") {
+	case "", "asc":
+	case "desc":
+		tq.Desc = true
+	default:
+		return entity.TaskQuery{}, fmt.Errorf("%w: order must be asc or desc", entity.ErrBadRequest)
+	}
+
+	for param, dest := range map[string]**time.Time{"due_from": &tq.DueFrom, "due_to": &tq.DueTo} {
+		v := query.Get(param)
+		if v == "" {
+			continue
+		}
+
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return entity.TaskQuery{}, fmt.Errorf("%w: invalid %s, RFC 3339 expected", entity.ErrBadRequest, param)
+		}
+
+		*dest = &t
+	}
+
+	return tq, nil
+}
+
+type AssignRequest struct {
+	UserID int64 `json:"user_id"`
+}
+
+func (h *TaskHandler) AssignTask(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	qID := r.PathValue("id")
+	id, err := strconv.ParseInt(qID, 10, 64)
+	if err != nil {
+		sendError(ctx, w, entity.ErrBadRequest)
+		return
+	}
+
+	var request AssignRequest
+	err = json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	task, err := h.task.AssignTask(ctx, id, request.UserID)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	sendResponse(w, task)
+}
+
+func (h *TaskHandler) UnassignTask(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		sendError(ctx, w, entity.ErrBadRequest)
+		return
+	}
+
+	userID, err := strconv.ParseInt(r.PathValue("user_id"), 10, 64)
+	if err != nil {
+		sendError(ctx, w, entity.ErrBadRequest)
+		return
+	}
+
+	err = h.task.UnassignTask(ctx, id, userID)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+type ChangeStatusRequest struct {
+	Status string `json:"status"`
+}
+
+func (h *TaskHandler) ChangeTaskStatus(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	qID := r.PathValue("id")
+	id, err := strconv.ParseInt(qID, 10, 64)
+	if err != nil {
+		sendError(ctx, w, entity.ErrBadRequest)
+		return
+	}
+
+	var request ChangeStatusRequest
+	err = json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	task, err := h.task.ChangeTaskStatus(ctx, id, request.Status)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	sendResponse(w, task)
+}
+
+func (h *TaskHandler) MoveTask(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		sendError(ctx, w, entity.ErrBadRequest)
+		return
+	}
+
+	var move entity.TaskMove
+	err = json.NewDecoder(r.Body).Decode(&move)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	task, err := h.task.MoveTask(ctx, id, move)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	sendResponse(w, task)
+}
+
+func (h *TaskHandler) TransferTasks(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var transfer entity.TaskTransfer
+	err := json.NewDecoder(r.Body).Decode(&transfer)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	tasks, err := h.task.TransferTasks(ctx, transfer)
 	if err != nil {
 		sendError(ctx, w, err)
 		return
@@ -92,3 +379,619 @@ func (h *TaskHandler) UserTasks(w http.ResponseWriter, r *http.Request) {
 
 	sendResponse(w, tasks)
 }
+
+func (h *TaskHandler) ProjectWorkflow(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	qID := r.PathValue("project_id")
+	projectID, err := strconv.ParseInt(qID, 10, 64)
+	if err != nil {
+		sendError(ctx, w, entity.ErrBadRequest)
+		return
+	}
+
+	workflow, err := h.task.ProjectWorkflow(ctx, projectID)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	sendResponse(w, workflow)
+}
+
+func (h *TaskHandler) UpdateProjectWorkflow(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	qID := r.PathValue("project_id")
+	projectID, err := strconv.ParseInt(qID, 10, 64)
+	if err != nil {
+		sendError(ctx, w, entity.ErrBadRequest)
+		return
+	}
+
+	var workflow entity.Workflow
+	err = json.NewDecoder(r.Body).Decode(&workflow)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	workflow.ProjectID = projectID
+
+	workflow, err = h.task.UpdateProjectWorkflow(ctx, workflow)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	sendResponse(w, workflow)
+}
+
+func (h *TaskHandler) ProjectLabels(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	projectID, err := strconv.ParseInt(r.PathValue("project_id"), 10, 64)
+	if err != nil {
+		sendError(ctx, w, entity.ErrBadRequest)
+		return
+	}
+
+	labels, err := h.task.ProjectLabels(ctx, projectID)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	if labels == nil {
+		labels = []entity.Label{}
+	}
+
+	sendResponse(w, labels)
+}
+
+func (h *TaskHandler) CreateLabel(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	projectID, err := strconv.ParseInt(r.PathValue("project_id"), 10, 64)
+	if err != nil {
+		sendError(ctx, w, entity.ErrBadRequest)
+		return
+	}
+
+	var label entity.Label
+	err = json.NewDecoder(r.Body).Decode(&label)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	label.ProjectID = projectID
+
+	label, err = h.task.CreateLabel(ctx, label)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	sendResponse(w, label)
+}
+
+func (h *TaskHandler) UpdateLabel(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	projectID, err := strconv.ParseInt(r.PathValue("project_id"), 10, 64)
+	if err != nil {
+		sendError(ctx, w, entity.ErrBadRequest)
+		return
+	}
+
+	labelID, err := strconv.ParseInt(r.PathValue("label_id"), 10, 64)
+	if err != nil {
+		sendError(ctx, w, entity.ErrBadRequest)
+		return
+	}
+
+	var upd entity.LabelToUpdate
+	err = json.NewDecoder(r.Body).Decode(&upd)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	label, err := h.task.UpdateLabel(ctx, projectID, labelID, upd)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	sendResponse(w, label)
+}
+
+func (h *TaskHandler) DeleteLabel(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	projectID, err := strconv.ParseInt(r.PathValue("project_id"), 10, 64)
+	if err != nil {
+		sendError(ctx, w, entity.ErrBadRequest)
+		return
+	}
+
+	labelID, err := strconv.ParseInt(r.PathValue("label_id"), 10, 64)
+	if err != nil {
+		sendError(ctx, w, entity.ErrBadRequest)
+		return
+	}
+
+	err = h.task.DeleteLabel(ctx, projectID, labelID)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *TaskHandler) ProjectCustomFields(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	projectID, err := strconv.ParseInt(r.PathValue("project_id"), 10, 64)
+	if err != nil {
+		sendError(ctx, w, entity.ErrBadRequest)
+		return
+	}
+
+	fields, err := h.task.ProjectCustomFields(ctx, projectID)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	if fields == nil {
+		fields = []entity.CustomField{}
+	}
+
+	sendResponse(w, fields)
+}
+
+func (h *TaskHandler) CreateCustomField(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	projectID, err := strconv.ParseInt(r.PathValue("project_id"), 10, 64)
+	if err != nil {
+		sendError(ctx, w, entity.ErrBadRequest)
+		return
+	}
+
+	var field entity.CustomField
+	err = json.NewDecoder(r.Body).Decode(&field)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	field.ProjectID = projectID
+
+	field, err = h.task.CreateCustomField(ctx, field)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	sendResponse(w, field)
+}
+
+func (h *TaskHandler) UpdateCustomField(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	projectID, err := strconv.ParseInt(r.PathValue("project_id"), 10, 64)
+	if err != nil {
+		sendError(ctx, w, entity.ErrBadRequest)
+		return
+	}
+
+	fieldID, err := strconv.ParseInt(r.PathValue("field_id"), 10, 64)
+	if err != nil {
+		sendError(ctx, w, entity.ErrBadRequest)
+		return
+	}
+
+	var upd entity.CustomFieldToUpdate
+	err = json.NewDecoder(r.Body).Decode(&upd)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	field, err := h.task.UpdateCustomField(ctx, projectID, fieldID, upd)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	sendResponse(w, field)
+}
+
+func (h *TaskHandler) DeleteCustomField(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	projectID, err := strconv.ParseInt(r.PathValue("project_id"), 10, 64)
+	if err != nil {
+		sendError(ctx, w, entity.ErrBadRequest)
+		return
+	}
+
+	fieldID, err := strconv.ParseInt(r.PathValue("field_id"), 10, 64)
+	if err != nil {
+		sendError(ctx, w, entity.ErrBadRequest)
+		return
+	}
+
+	err = h.task.DeleteCustomField(ctx, projectID, fieldID)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+type AttachLabelRequest struct {
+	LabelID int64 `json:"label_id"`
+}
+
+func (h *TaskHandler) AttachLabel(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		sendError(ctx, w, entity.ErrBadRequest)
+		return
+	}
+
+	var request AttachLabelRequest
+	err = json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	task, err := h.task.AttachLabel(ctx, id, request.LabelID)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	sendResponse(w, task)
+}
+
+func (h *TaskHandler) DetachLabel(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		sendError(ctx, w, entity.ErrBadRequest)
+		return
+	}
+
+	labelID, err := strconv.ParseInt(r.PathValue("label_id"), 10, 64)
+	if err != nil {
+		sendError(ctx, w, entity.ErrBadRequest)
+		return
+	}
+
+	err = h.task.DetachLabel(ctx, id, labelID)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *TaskHandler) TaskChecklist(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		sendError(ctx, w, entity.ErrBadRequest)
+		return
+	}
+
+	items, err := h.task.TaskChecklist(ctx, id)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	if items == nil {
+		items = []entity.ChecklistItem{}
+	}
+
+	sendResponse(w, items)
+}
+
+func (h *TaskHandler) AddChecklistItem(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		sendError(ctx, w, entity.ErrBadRequest)
+		return
+	}
+
+	var ic entity.ChecklistItemToCreate
+	err = json.NewDecoder(r.Body).Decode(&ic)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	item, err := h.task.AddChecklistItem(ctx, id, ic)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	sendResponse(w, item)
+}
+
+func (h *TaskHandler) ToggleChecklistItem(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		sendError(ctx, w, entity.ErrBadRequest)
+		return
+	}
+
+	itemID, err := strconv.ParseInt(r.PathValue("item_id"), 10, 64)
+	if err != nil {
+		sendError(ctx, w, entity.ErrBadRequest)
+		return
+	}
+
+	item, err := h.task.ToggleChecklistItem(ctx, id, itemID)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	sendResponse(w, item)
+}
+
+func (h *TaskHandler) ReorderChecklist(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		sendError(ctx, w, entity.ErrBadRequest)
+		return
+	}
+
+	var order entity.ChecklistOrder
+	err = json.NewDecoder(r.Body).Decode(&order)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	items, err := h.task.ReorderChecklist(ctx, id, order)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	if items == nil {
+		items = []entity.ChecklistItem{}
+	}
+
+	sendResponse(w, items)
+}
+
+func (h *TaskHandler) DeleteChecklistItem(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		sendError(ctx, w, entity.ErrBadRequest)
+		return
+	}
+
+	itemID, err := strconv.ParseInt(r.PathValue("item_id"), 10, 64)
+	if err != nil {
+		sendError(ctx, w, entity.ErrBadRequest)
+		return
+	}
+
+	err = h.task.DeleteChecklistItem(ctx, id, itemID)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *TaskHandler) ChildTasks(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		sendError(ctx, w, entity.ErrBadRequest)
+		return
+	}
+
+	tq, err := taskQuery(r)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	tasks, err := h.task.ChildTasks(ctx, id, tq)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	sendPage(w, tasks)
+}
+
+func (h *TaskHandler) TaskHistory(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		sendError(ctx, w, entity.ErrBadRequest)
+		return
+	}
+
+	pr, err := pageRequest(r)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	changes, err := h.task.TaskHistory(ctx, id, pr)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	sendPage(w, changes)
+}
+
+func (h *TaskHandler) TaskTree(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		sendError(ctx, w, entity.ErrBadRequest)
+		return
+	}
+
+	tree, err := h.task.TaskTree(ctx, id)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	sendResponse(w, tree)
+}
+
+type SetRecurrenceRequest struct {
+	Rule string `json:"rule"`
+}
+
+func (h *TaskHandler) SetTaskRecurrence(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		sendError(ctx, w, entity.ErrBadRequest)
+		return
+	}
+
+	var request SetRecurrenceRequest
+	err = json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	rec, err := h.task.SetTaskRecurrence(ctx, id, request.Rule)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	sendResponse(w, rec)
+}
+
+func (h *TaskHandler) TaskRecurrence(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		sendError(ctx, w, entity.ErrBadRequest)
+		return
+	}
+
+	rec, err := h.task.TaskRecurrence(ctx, id)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	sendResponse(w, rec)
+}
+
+func (h *TaskHandler) DeleteTaskRecurrence(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		sendError(ctx, w, entity.ErrBadRequest)
+		return
+	}
+
+	err = h.task.DeleteTaskRecurrence(ctx, id)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+type AddBlockerRequest struct {
+	BlockerID int64 `json:"blocker_id"`
+}
+
+func (h *TaskHandler) AddBlocker(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		sendError(ctx, w, entity.ErrBadRequest)
+		return
+	}
+
+	var request AddBlockerRequest
+	err = json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	task, err := h.task.AddBlocker(ctx, id, request.BlockerID)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	sendResponse(w, task)
+}
+
+func (h *TaskHandler) RemoveBlocker(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		sendError(ctx, w, entity.ErrBadRequest)
+		return
+	}
+
+	blockerID, err := strconv.ParseInt(r.PathValue("blocker_id"), 10, 64)
+	if err != nil {
+		sendError(ctx, w, entity.ErrBadRequest)
+		return
+	}
+
+	err = h.task.RemoveBlocker(ctx, id, blockerID)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
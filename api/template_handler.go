@@ -0,0 +1,191 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"task-manager/entity"
+)
+
+type TemplateService interface {
+	CreateTemplate(ctx context.Context, tmpl entity.ProjectTemplate) (entity.ProjectTemplate, error)
+	SaveProjectTemplate(ctx context.Context, projectID int64, name string) (entity.ProjectTemplate, error)
+	TemplateByID(ctx context.Context, id int64) (entity.ProjectTemplate, error)
+	UserTemplates(ctx context.Context, pr entity.PageRequest) (entity.Page[entity.ProjectTemplate], error)
+	DeleteTemplate(ctx context.Context, id int64) error
+	CreateProjectFromTemplate(ctx context.Context, templateID int64, name string) (entity.Project, error)
+	ApplyTemplate(ctx context.Context, templateID int64, projectID int64) ([]entity.Task, error)
+}
+
+type TemplateHandler struct {
+	template TemplateService
+}
+
+func NewTemplateHandler(template TemplateService) *TemplateHandler {
+	return &TemplateHandler{template: template}
+}
+
+func (h *TemplateHandler) CreateTemplate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var tmpl entity.ProjectTemplate
+	err := json.NewDecoder(r.Body).Decode(&tmpl)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	tmpl, err = h.template.CreateTemplate(ctx, tmpl)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	sendResponse(w, tmpl)
+}
+
+type SaveTemplateRequest struct {
+	Name string `json:"name"`
+}
+
+func (h *TemplateHandler) SaveProjectTemplate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	projectID, err := strconv.ParseInt(r.PathValue("project_id"), 10, 64)
+	if err != nil {
+		sendError(ctx, w, entity.ErrBadRequest)
+		return
+	}
+
+	var request SaveTemplateRequest
+	err = json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	tmpl, err := h.template.SaveProjectTemplate(ctx, projectID, request.Name)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	sendResponse(w, tmpl)
+}
+
+func (h *TemplateHandler) TemplateByID(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		sendError(ctx, w, entity.ErrBadRequest)
+		return
+	}
+
+	tmpl, err := h.template.TemplateByID(ctx, id)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	sendResponse(w, tmpl)
+}
+
+func (h *TemplateHandler) UserTemplates(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	pr, err := pageRequest(r)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	templates, err := h.template.UserTemplates(ctx, pr)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	sendPage(w, templates)
+}
+
+func (h *TemplateHandler) DeleteTemplate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		sendError(ctx, w, entity.ErrBadRequest)
+		return
+	}
+
+	err = h.template.DeleteTemplate(ctx, id)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+type ProjectFromTemplateRequest struct {
+	Name string `json:"name"`
+}
+
+func (h *TemplateHandler) CreateProjectFromTemplate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		sendError(ctx, w, entity.ErrBadRequest)
+		return
+	}
+
+	var request ProjectFromTemplateRequest
+	err = json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	project, err := h.template.CreateProjectFromTemplate(ctx, id, request.Name)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	sendResponse(w, project)
+}
+
+type ApplyTemplateRequest struct {
+	ProjectID int64 `json:"project_id"`
+}
+
+func (h *TemplateHandler) ApplyTemplate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		sendError(ctx, w, entity.ErrBadRequest)
+		return
+	}
+
+	var request ApplyTemplateRequest
+	err = json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	tasks, err := h.template.ApplyTemplate(ctx, id, request.ProjectID)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	if tasks == nil {
+		tasks = []entity.Task{}
+	}
+
+	sendResponse(w, tasks)
+}
@@ -0,0 +1,264 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"task-manager/entity"
+	"time"
+)
+
+type TimeService interface {
+	StartTimer(ctx context.Context, taskID int64, note string) (entity.TimeEntry, error)
+	StopTimer(ctx context.Context) (entity.TimeEntry, error)
+	RunningTimer(ctx context.Context) (entity.TimeEntry, error)
+	CreateTimeEntry(ctx context.Context, taskID int64, tc entity.TimeEntryToCreate) (entity.TimeEntry, error)
+	TaskTimeEntries(ctx context.Context, taskID int64, pr entity.PageRequest) (entity.Page[entity.TimeEntry], error)
+	DeleteTimeEntry(ctx context.Context, taskID int64, entryID int64) error
+	TaskTimeTotal(ctx context.Context, taskID int64, tq entity.TimeQuery) (entity.TimeTotal, error)
+	ProjectTimeTotal(ctx context.Context, projectID int64, tq entity.TimeQuery) (entity.TimeTotal, error)
+	UserTimeTotal(ctx context.Context, tq entity.TimeQuery) (entity.TimeTotal, error)
+}
+
+type TimeHandler struct {
+	time TimeService
+}
+
+func NewTimeHandler(time TimeService) *TimeHandler {
+	return &TimeHandler{time: time}
+}
+
+type StartTimerRequest struct {
+	Note string `json:"note"`
+}
+
+func (h *TimeHandler) StartTimer(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	taskID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		sendError(ctx, w, entity.ErrBadRequest)
+		return
+	}
+
+	// the body with a note is optional
+	var request StartTimerRequest
+	err = json.NewDecoder(r.Body).Decode(&request)
+	if err != nil && !errors.Is(err, io.EOF) {
+		sendError(ctx, w, err)
+		return
+	}
+
+	entry, err := h.time.StartTimer(ctx, taskID, request.Note)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	sendResponse(w, entry)
+}
+
+func (h *TimeHandler) StopTimer(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	entry, err := h.time.StopTimer(ctx)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	sendResponse(w, entry)
+}
+
+func (h *TimeHandler) RunningTimer(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	entry, err := h.time.RunningTimer(ctx)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	sendResponse(w, entry)
+}
+
+func (h *TimeHandler) CreateTimeEntry(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	taskID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		sendError(ctx, w, entity.ErrBadRequest)
+		return
+	}
+
+	var tc entity.TimeEntryToCreate
+	err = json.NewDecoder(r.Body).Decode(&tc)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	entry, err := h.time.CreateTimeEntry(ctx, taskID, tc)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	sendResponse(w, entry)
+}
+
+func (h *TimeHandler) TaskTimeEntries(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	taskID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		sendError(ctx, w, entity.ErrBadRequest)
+		return
+	}
+
+	pr, err := pageRequest(r)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	entries, err := h.time.TaskTimeEntries(ctx, taskID, pr)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	sendPage(w, entries)
+}
+
+func (h *TimeHandler) DeleteTimeEntry(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	taskID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		sendError(ctx, w, entity.ErrBadRequest)
+		return
+	}
+
+	entryID, err := strconv.ParseInt(r.PathValue("entry_id"), 10, 64)
+	if err != nil {
+		sendError(ctx, w, entity.ErrBadRequest)
+		return
+	}
+
+	err = h.time.DeleteTimeEntry(ctx, taskID, entryID)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *TimeHandler) TaskTimeTotal(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	taskID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		sendError(ctx, w, entity.ErrBadRequest)
+		return
+	}
+
+	tq, err := timeQuery(r)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	total, err := h.time.TaskTimeTotal(ctx, taskID, tq)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	sendResponse(w, total)
+}
+
+func (h *TimeHandler) ProjectTimeTotal(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	projectID, err := strconv.ParseInt(r.PathValue("project_id"), 10, 64)
+	if err != nil {
+		sendError(ctx, w, entity.ErrBadRequest)
+		return
+	}
+
+	tq, err := timeQuery(r)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	total, err := h.time.ProjectTimeTotal(ctx, projectID, tq)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	sendResponse(w, total)
+}
+
+// UserTimeTotal counts time of the current user only, so user_id is rejected rather than ignored.
+func (h *TimeHandler) UserTimeTotal(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	tq, err := timeQuery(r)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	if tq.UserID != nil {
+		sendError(ctx, w, fmt.Errorf("%w: user_id isn't supported, the total is of the current user", entity.ErrBadRequest))
+		return
+	}
+
+	total, err := h.time.UserTimeTotal(ctx, tq)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	sendResponse(w, total)
+}
+
+// timeQuery reads from and to as RFC 3339 times and user_id query parameters.
+func timeQuery(r *http.Request) (entity.TimeQuery, error) {
+	query := r.URL.Query()
+
+	var tq entity.TimeQuery
+
+	for param, dest := range map[string]**time.Time{"from": &tq.From, "to": &tq.To} {
+		v := query.Get(param)
+		if v == "" {
+			continue
+		}
+
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return entity.TimeQuery{}, fmt.Errorf("%w: invalid %s, RFC 3339 expected", entity.ErrBadRequest, param)
+		}
+
+		*dest = &t
+	}
+
+	if v := query.Get("user_id"); v != "" {
+		userID, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return entity.TimeQuery{}, fmt.Errorf("%w: invalid user_id", entity.ErrBadRequest)
+		}
+
+		tq.UserID = &userID
+	}
+
+	return tq, nil
+}
@@ -2,6 +2,7 @@ package api
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
 	"strconv"
 	"task-manager/entity"
@@ -9,7 +10,8 @@ import (
 
 type UserService interface {
 	UserByID(ctx context.Context, id int64) (entity.User, error)
-	ProjectUsers(ctx context.Context, projectID int64) ([]entity.User, error)
+	ProjectUsers(ctx context.Context, projectID int64, pr entity.PageRequest) (entity.Page[entity.ProjectMember], error)
+	ChangeMemberRole(ctx context.Context, projectID int64, userID int64, role entity.Role) error
 
 	DeleteUser(ctx context.Context, id int64) error
 }
@@ -72,11 +74,52 @@ func (h *UserHandler) ProjectUsers(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	users, err := h.user.ProjectUsers(ctx, projectID)
+	pr, err := pageRequest(r)
 	if err != nil {
 		sendError(ctx, w, err)
 		return
 	}
 
-	sendResponse(w, users)
+	users, err := h.user.ProjectUsers(ctx, projectID, pr)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	sendPage(w, users)
+}
+
+type ChangeRoleRequest struct {
+	Role entity.Role `json:"role"`
+}
+
+func (h *UserHandler) ChangeMemberRole(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	projectID, err := strconv.ParseInt(r.PathValue("project_id"), 10, 64)
+	if err != nil {
+		sendError(ctx, w, entity.ErrBadRequest)
+		return
+	}
+
+	userID, err := strconv.ParseInt(r.PathValue("user_id"), 10, 64)
+	if err != nil {
+		sendError(ctx, w, entity.ErrBadRequest)
+		return
+	}
+
+	var request ChangeRoleRequest
+	err = json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	err = h.user.ChangeMemberRole(ctx, projectID, userID, request.Role)
+	if err != nil {
+		sendError(ctx, w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
 }
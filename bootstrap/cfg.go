@@ -8,15 +8,16 @@ import (
 )
 
 type Config struct {
-	DBHost     string `env:"DB_HOST"`
-	DBPort     string `env:"DB_PORT"`
-	DBUser     string `env:"DB_USER"`
-	DBPassword string `env:"DB_PASS"`
-	DBName     string `env:"DB_NAME"`
-	HTTPPort   string `env:"HTTP_PORT"`
-	RedisAddr  string `env:"REDIS_ADDR"`
-	KafkaAddr  string `env:"KAFKA_ADDR"`
-	KafkaTopic string `env:"KAFKA_TOPIC"`
+	DBHost         string `env:"DB_HOST"`
+	DBPort         string `env:"DB_PORT"`
+	DBUser         string `env:"DB_USER"`
+	DBPassword     string `env:"DB_PASS"`
+	DBName         string `env:"DB_NAME"`
+	HTTPPort       string `env:"HTTP_PORT"`
+	RedisAddr      string `env:"REDIS_ADDR"`
+	KafkaAddr      string `env:"KAFKA_ADDR"`
+	KafkaTopic     string `env:"KAFKA_TOPIC"`
+	AttachmentsDir string `env:"ATTACHMENTS_DIR"`
 }
 
 func NewConfig() (*Config, error) {
@@ -83,6 +84,11 @@ func (c *Config) Validate() []error {
 		errorList = append(errorList, err)
 	}
 
+	if c.AttachmentsDir == "" {
+		err := errors.New("invalid attachments directory field \n")
+		errorList = append(errorList, err)
+	}
+
 	if len(errorList) != 0 {
 		return errorList
 	}
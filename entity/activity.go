@@ -0,0 +1,25 @@
+package entity
+
+import "time"
+
+const (
+	ActivityProjectCreated = "project_created"
+	ActivityTaskCreated    = "task_created"
+	ActivityTaskUpdated    = "task_updated"
+	ActivityInvitationSent = "invitation_sent"
+	ActivityMemberJoined   = "member_joined"
+)
+
+// Activity is an entry of the activity feed. Subject is the task name for task activities
+// and the invited user name for invitations.
+type Activity struct {
+	ID          int64     `json:"id"`
+	ProjectID   int64     `json:"project_id"`
+	ProjectName string    `json:"project_name"`
+	ActorID     *int64    `json:"actor_id"`
+	ActorName   string    `json:"actor_name"`
+	Type        string    `json:"type"`
+	TaskID      *int64    `json:"task_id"`
+	Subject     string    `json:"subject"`
+	CreatedAt   time.Time `json:"created_at"`
+}
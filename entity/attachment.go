@@ -0,0 +1,35 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+)
+
+const MaxAttachmentSize = 10 << 20
+
+// AttachmentTypes are content types files may have, the type is detected from the file content.
+var AttachmentTypes = []string{
+	"image/png", "image/jpeg", "image/gif", "image/webp",
+	"application/pdf", "application/zip", "text/plain",
+}
+
+type Attachment struct {
+	ID          int64     `json:"id"`
+	TaskID      int64     `json:"task_id"`
+	UserID      int64     `json:"user_id"`
+	Name        string    `json:"name"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	StorageKey  string    `json:"-"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func ValidateAttachmentType(contentType string) error {
+	for _, t := range AttachmentTypes {
+		if t == contentType {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: %s files can't be attached", ErrBadRequest, contentType)
+}
@@ -0,0 +1,79 @@
+package entity
+
+import (
+	"fmt"
+	"slices"
+	"time"
+	"unicode/utf8"
+)
+
+const MaxChecklistItemText = 500
+
+// ChecklistItem is a to-do inside a task, lighter than a subtask. Items are ordered by Position,
+// ResponsibleID is an optional project member in charge of the item.
+type ChecklistItem struct {
+	ID            int64     `json:"id"`
+	TaskID        int64     `json:"task_id"`
+	Text          string    `json:"text"`
+	Checked       bool      `json:"checked"`
+	ResponsibleID *int64    `json:"responsible_id"`
+	Position      int       `json:"position"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type ChecklistItemToCreate struct {
+	Text          string `json:"text"`
+	ResponsibleID *int64 `json:"responsible_id"`
+}
+
+func (ic *ChecklistItemToCreate) Validate() error {
+	if ic.Text == "" {
+		return fmt.Errorf("%w: empty checklist item text", ErrBadRequest)
+	}
+
+	if utf8.RuneCountInString(ic.Text) > MaxChecklistItemText {
+		return fmt.Errorf("%w: checklist item text is longer than %d characters", ErrBadRequest, MaxChecklistItemText)
+	}
+
+	return nil
+}
+
+// ChecklistOrder lists ids of all items of a task checklist in their new order.
+type ChecklistOrder struct {
+	ItemIDs []int64 `json:"item_ids"`
+}
+
+// Validate checks the order is a permutation of the given items.
+func (o *ChecklistOrder) Validate(items []ChecklistItem) error {
+	if len(o.ItemIDs) != len(items) {
+		return fmt.Errorf("%w: order must list all %d checklist items", ErrBadRequest, len(items))
+	}
+
+	for i, id := range o.ItemIDs {
+		if slices.Contains(o.ItemIDs[:i], id) {
+			return fmt.Errorf("%w: checklist item %d is listed twice", ErrBadRequest, id)
+		}
+
+		if !slices.ContainsFunc(items, func(item ChecklistItem) bool { return item.ID == id }) {
+			return fmt.Errorf("%w: checklist item %d doesn't belong to the task", ErrBadRequest, id)
+		}
+	}
+
+	return nil
+}
+
+// ChecklistProgress counts checked checklist items of a task, Percent is rounded down and 0 for an empty checklist.
+type ChecklistProgress struct {
+	Checked int `json:"checked"`
+	Total   int `json:"total"`
+	Percent int `json:"percent"`
+}
+
+func NewChecklistProgress(checked int, total int) ChecklistProgress {
+	p := ChecklistProgress{Checked: checked, Total: total}
+	if total > 0 {
+		p.Percent = 100 * checked / total
+	}
+
+	return p
+}
@@ -0,0 +1,56 @@
+package entity
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const MaxCommentLength = 10000
+
+type Comment struct {
+	ID        int64      `json:"id"`
+	TaskID    int64      `json:"task_id"`
+	UserID    int64      `json:"user_id"`
+	Body      string     `json:"body"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt *time.Time `json:"updated_at"`
+}
+
+func ValidateCommentBody(body string) error {
+	if strings.TrimSpace(body) == "" {
+		return fmt.Errorf("%w: empty comment", ErrBadRequest)
+	}
+
+	if utf8.RuneCountInString(body) > MaxCommentLength {
+		return fmt.Errorf("%w: comment is longer than %d characters", ErrBadRequest, MaxCommentLength)
+	}
+
+	return nil
+}
+
+// mentionPattern matches @name at the start of the text or after a space, so emails aren't taken for mentions.
+var mentionPattern = regexp.MustCompile(`(?:^|\s)@([\p{L}\p{N}_.\-]+)`)
+
+// Mentions returns distinct user names mentioned in the text as @name, trailing punctuation isn't part of a name.
+func Mentions(text string) []string {
+	var names []string
+
+	seen := make(map[string]bool)
+
+	for _, m := range mentionPattern.FindAllStringSubmatch(text, -1) {
+		name := strings.TrimRight(m[1], ".-")
+		key := strings.ToLower(name)
+
+		if name == "" || seen[key] {
+			continue
+		}
+
+		seen[key] = true
+		names = append(names, name)
+	}
+
+	return names
+}
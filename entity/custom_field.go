@@ -0,0 +1,184 @@
+package entity
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	CustomFieldText        = "text"
+	CustomFieldNumber      = "number"
+	CustomFieldDate        = "date"
+	CustomFieldSelect      = "select"
+	CustomFieldMultiSelect = "multi_select"
+	CustomFieldUser        = "user"
+)
+
+const (
+	MaxCustomFieldText    = 1000
+	CustomFieldDateLayout = "2006-01-02"
+)
+
+// CustomField is a field a project defines for its tasks. Options are choices of select fields.
+type CustomField struct {
+	ID        int64    `json:"id"`
+	ProjectID int64    `json:"project_id"`
+	Name      string   `json:"name"`
+	Type      string   `json:"type"`
+	Options   []string `json:"options"`
+}
+
+func (f *CustomField) Validate() error {
+	if f.Name == "" {
+		return fmt.Errorf("%w: empty field name", ErrBadRequest)
+	}
+
+	switch f.Type {
+	case CustomFieldSelect, CustomFieldMultiSelect:
+		return validateFieldOptions(f.Options)
+	case CustomFieldText, CustomFieldNumber, CustomFieldDate, CustomFieldUser:
+		if len(f.Options) != 0 {
+			return fmt.Errorf("%w: only select fields have options", ErrBadRequest)
+		}
+
+		return nil
+	default:
+		return fmt.Errorf("%w: unknown field type %q", ErrBadRequest, f.Type)
+	}
+}
+
+// CustomFieldToUpdate holds fields to change, nil fields are left untouched. The type can't be changed.
+type CustomFieldToUpdate struct {
+	Name    *string   `json:"name"`
+	Options *[]string `json:"options"`
+}
+
+func (fu *CustomFieldToUpdate) Validate(f CustomField) error {
+	if fu.Name != nil && *fu.Name == "" {
+		return fmt.Errorf("%w: empty field name", ErrBadRequest)
+	}
+
+	if fu.Options == nil {
+		return nil
+	}
+
+	if f.Type != CustomFieldSelect && f.Type != CustomFieldMultiSelect {
+		return fmt.Errorf("%w: only select fields have options", ErrBadRequest)
+	}
+
+	return validateFieldOptions(*fu.Options)
+}
+
+func validateFieldOptions(options []string) error {
+	if len(options) == 0 {
+		return fmt.Errorf("%w: select fields need options", ErrBadRequest)
+	}
+
+	for i, o := range options {
+		if o == "" {
+			return fmt.Errorf("%w: empty field option", ErrBadRequest)
+		}
+
+		if slices.Contains(options[:i], o) {
+			return fmt.Errorf("%w: duplicate field option %q", ErrBadRequest, o)
+		}
+	}
+
+	return nil
+}
+
+// NormalizeValue checks the JSON value fits the field type and returns it in compact form:
+// text and select values are strings, numbers are numbers, dates are "YYYY-MM-DD" strings,
+// multi select values are arrays of distinct options and user values are user IDs. JSON null means no value.
+func (f *CustomField) NormalizeValue(raw json.RawMessage) (json.RawMessage, error) {
+	if len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+		return nil, nil
+	}
+
+	var v any
+
+	switch f.Type {
+	case CustomFieldText:
+		var s string
+		if json.Unmarshal(raw, &s) != nil || utf8.RuneCountInString(s) > MaxCustomFieldText {
+			return nil, f.invalid(fmt.Sprintf("a string up to %d characters", MaxCustomFieldText))
+		}
+
+		v = s
+	case CustomFieldNumber:
+		var n float64
+		if json.Unmarshal(raw, &n) != nil || math.IsInf(n, 0) || math.IsNaN(n) {
+			return nil, f.invalid("a number")
+		}
+
+		v = n
+	case CustomFieldDate:
+		var s string
+		if json.Unmarshal(raw, &s) != nil {
+			return nil, f.invalid("a YYYY-MM-DD date")
+		}
+
+		if _, err := time.Parse(CustomFieldDateLayout, s); err != nil {
+			return nil, f.invalid("a YYYY-MM-DD date")
+		}
+
+		v = s
+	case CustomFieldSelect:
+		var s string
+		if json.Unmarshal(raw, &s) != nil || !slices.Contains(f.Options, s) {
+			return nil, f.invalid("one of its options")
+		}
+
+		v = s
+	case CustomFieldMultiSelect:
+		var values []string
+		if json.Unmarshal(raw, &values) != nil {
+			return nil, f.invalid("an array of its options")
+		}
+
+		var distinct []string
+		for _, s := range values {
+			if !slices.Contains(f.Options, s) {
+				return nil, f.invalid("an array of its options")
+			}
+
+			if !slices.Contains(distinct, s) {
+				distinct = append(distinct, s)
+			}
+		}
+
+		if len(distinct) == 0 {
+			return nil, nil
+		}
+
+		v = distinct
+	case CustomFieldUser:
+		var id int64
+		if json.Unmarshal(raw, &id) != nil {
+			return nil, f.invalid("a user ID")
+		}
+
+		v = id
+	}
+
+	return json.Marshal(v)
+}
+
+// UserID returns the user a user field value refers to.
+func (f *CustomField) UserID(value json.RawMessage) (int64, bool) {
+	var id int64
+	if f.Type != CustomFieldUser || json.Unmarshal(value, &id) != nil {
+		return 0, false
+	}
+
+	return id, true
+}
+
+func (f *CustomField) invalid(expected string) error {
+	return fmt.Errorf("%w: field %q expects %s", ErrBadRequest, f.Name, expected)
+}
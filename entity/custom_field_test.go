@@ -0,0 +1,65 @@
+package entity
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+)
+
+func TestCustomField_NormalizeValue(t *testing.T) {
+	long, err := json.Marshal(strings.Repeat("a", MaxCustomFieldText+1))
+	require.NoError(t, err)
+
+	tests := []struct {
+		typ  string
+		raw  string
+		want string
+	}{
+		{CustomFieldText, `"text"`, `"text"`},
+		{CustomFieldNumber, `1.50`, `1.5`},
+		{CustomFieldDate, `"2026-01-31"`, `"2026-01-31"`},
+		{CustomFieldSelect, `"x"`, `"x"`},
+		{CustomFieldMultiSelect, `["y","x","y"]`, `["y","x"]`},
+		{CustomFieldUser, `7`, `7`},
+		// null and an empty selection remove the value
+		{CustomFieldText, `null`, ``},
+		{CustomFieldMultiSelect, `[]`, ``},
+	}
+
+	for _, tt := range tests {
+		f := CustomField{Name: "field", Type: tt.typ, Options: []string{"x", "y"}}
+
+		value, err := f.NormalizeValue(json.RawMessage(tt.raw))
+		require.NoError(t, err, tt.raw)
+
+		if tt.want == "" {
+			require.Nil(t, value, tt.raw)
+			continue
+		}
+
+		require.JSONEq(t, tt.want, string(value))
+	}
+
+	invalid := []struct {
+		typ string
+		raw string
+	}{
+		{CustomFieldText, `5`},
+		{CustomFieldText, string(long)},
+		{CustomFieldNumber, `"5"`},
+		{CustomFieldDate, `"2026-02-30"`},
+		{CustomFieldDate, `"31.01.2026"`},
+		{CustomFieldSelect, `"z"`},
+		{CustomFieldMultiSelect, `"x"`},
+		{CustomFieldMultiSelect, `["x","z"]`},
+		{CustomFieldUser, `"7"`},
+	}
+
+	for _, tt := range invalid {
+		f := CustomField{Name: "field", Type: tt.typ, Options: []string{"x", "y"}}
+
+		_, err := f.NormalizeValue(json.RawMessage(tt.raw))
+		require.ErrorIs(t, err, ErrBadRequest, tt.raw)
+	}
+}
@@ -0,0 +1,20 @@
+package entity
+
+const (
+	OpEq       = "eq"
+	OpNe       = "ne"
+	OpLt       = "lt"
+	OpLte      = "lte"
+	OpGt       = "gt"
+	OpGte      = "gte"
+	OpContains = "contains"
+	OpIn       = "in"
+)
+
+// Filter is a single condition of a listing, all filters of a listing are combined with AND.
+// Value is kept as text and gets parsed according to the field type, in takes comma separated values.
+type Filter struct {
+	Field string
+	Op    string
+	Value string
+}
@@ -0,0 +1,38 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Fields of changes recording creation and deletion, other changes are named after the changed field.
+const (
+	FieldCreated = "created"
+	FieldDeleted = "deleted"
+)
+
+// Change is an entry of the project or task history. Old and new values are JSON,
+// null old value means the thing has been added, null new value means it has been removed.
+type Change struct {
+	ID        int64           `json:"id"`
+	ProjectID int64           `json:"project_id"`
+	TaskID    *int64          `json:"task_id"`
+	ActorID   *int64          `json:"actor_id"`
+	Field     string          `json:"field"`
+	OldValue  json.RawMessage `json:"old_value"`
+	NewValue  json.RawMessage `json:"new_value"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+func NewChange(field string, oldValue any, newValue any) Change {
+	return Change{Field: field, OldValue: jsonValue(oldValue), NewValue: jsonValue(newValue)}
+}
+
+func jsonValue(v any) json.RawMessage {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return json.RawMessage("null")
+	}
+
+	return b
+}
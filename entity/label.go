@@ -0,0 +1,52 @@
+package entity
+
+import (
+	"fmt"
+	"regexp"
+)
+
+const DefaultLabelColor = "#9e9e9e"
+
+var labelColor = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// Label belongs to the catalog of a project and can be attached to any task of that project.
+type Label struct {
+	ID        int64  `json:"id"`
+	ProjectID int64  `json:"project_id"`
+	Name      string `json:"name"`
+	Color     string `json:"color"`
+}
+
+func (l *Label) Validate() error {
+	if l.Name == "" {
+		return fmt.Errorf("%w: empty label name", ErrBadRequest)
+	}
+
+	return validateLabelColor(l.Color)
+}
+
+// LabelToUpdate holds fields to change, nil fields are left untouched.
+type LabelToUpdate struct {
+	Name  *string `json:"name"`
+	Color *string `json:"color"`
+}
+
+func (lu *LabelToUpdate) Validate() error {
+	if lu.Name != nil && *lu.Name == "" {
+		return fmt.Errorf("%w: empty label name", ErrBadRequest)
+	}
+
+	if lu.Color != nil {
+		return validateLabelColor(*lu.Color)
+	}
+
+	return nil
+}
+
+func validateLabelColor(color string) error {
+	if !labelColor.MatchString(color) {
+		return fmt.Errorf("%w: label color must look like #rrggbb", ErrBadRequest)
+	}
+
+	return nil
+}
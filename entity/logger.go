@@ -5,6 +5,12 @@ import (
 	"log/slog"
 )
 
+// CtxLogger returns the request logger, the default one outside of requests.
 func CtxLogger(ctx context.Context) *slog.Logger {
-	return ctx.Value("logger").(*slog.Logger)
+	l, ok := ctx.Value("logger").(*slog.Logger)
+	if !ok {
+		return slog.Default()
+	}
+
+	return l
 }
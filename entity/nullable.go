@@ -0,0 +1,34 @@
+package entity
+
+import "encoding/json"
+
+// Nullable tells apart a JSON field that's missing from one explicitly set to null,
+// so partial updates are able to clear a value.
+type Nullable[T any] struct {
+	Set   bool
+	Value *T
+}
+
+func NewNullable[T any](v *T) Nullable[T] {
+	return Nullable[T]{Set: true, Value: v}
+}
+
+func (n *Nullable[T]) UnmarshalJSON(b []byte) error {
+	n.Set = true
+
+	if string(b) == "null" {
+		n.Value = nil
+		return nil
+	}
+
+	var v T
+
+	err := json.Unmarshal(b, &v)
+	if err != nil {
+		return err
+	}
+
+	n.Value = &v
+
+	return nil
+}
@@ -0,0 +1,30 @@
+package entity
+
+const (
+	DefaultPageLimit = 50
+	MaxPageLimit     = 200
+)
+
+// PageRequest asks for up to Limit items following the one Cursor points at, empty Cursor means the first page.
+type PageRequest struct {
+	Limit  int
+	Cursor string
+}
+
+// Size returns the number of items to load, defaulted and capped.
+func (p PageRequest) Size() int {
+	switch {
+	case p.Limit <= 0:
+		return DefaultPageLimit
+	case p.Limit > MaxPageLimit:
+		return MaxPageLimit
+	default:
+		return p.Limit
+	}
+}
+
+// Page is a part of a listing with an opaque cursor to the next part, NextCursor is empty on the last page.
+type Page[T any] struct {
+	Items      []T
+	NextCursor string
+}
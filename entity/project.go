@@ -8,3 +8,12 @@ type Project struct {
 	UserID    int64     `json:"user_id,omitempty"`
 	CreatedAt time.Time `json:"created_at"`
 }
+
+// ProjectClone describes a copy of a project. Name defaults to the source name with a suffix,
+// ResetCreators makes the caller the creator of every copied task and IncludeMembers copies memberships,
+// otherwise only the caller is a member of the copy.
+type ProjectClone struct {
+	Name           string `json:"name"`
+	ResetCreators  bool   `json:"reset_creators"`
+	IncludeMembers bool   `json:"include_members"`
+}
@@ -0,0 +1,80 @@
+package entity
+
+import (
+	"fmt"
+	"strings"
+)
+
+// rankDigits are digits of ranks in ascending byte order. Ranks are compared as plain strings
+// and never end with the zero digit, so there is always room for a rank between two others.
+const rankDigits = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+// TaskMove places a task into the Status column between AfterID and BeforeID.
+// Empty Status keeps the current one, with no neighbours the task goes to the end of the column.
+type TaskMove struct {
+	Status   string `json:"status"`
+	AfterID  *int64 `json:"after_id"`
+	BeforeID *int64 `json:"before_id"`
+}
+
+// RankBetween returns a rank sorting after prev and before next, empty prev means the start
+// and empty next means the end of the column.
+func RankBetween(prev string, next string) (string, error) {
+	if next != "" && prev >= next {
+		return "", fmt.Errorf("%w: rank %q is not before %q", ErrBadRequest, prev, next)
+	}
+
+	return midRank(prev, next), nil
+}
+
+func midRank(a string, b string) string {
+	if b != "" {
+		n := 0
+		for n < len(b) && rankDigit(a, n) == b[n] {
+			n++
+		}
+
+		if n > 0 {
+			rest := ""
+			if n < len(a) {
+				rest = a[n:]
+			}
+
+			return b[:n] + midRank(rest, b[n:])
+		}
+	}
+
+	lo := 0
+	if a != "" {
+		lo = strings.IndexByte(rankDigits, a[0])
+	}
+
+	hi := len(rankDigits)
+	if b != "" {
+		hi = strings.IndexByte(rankDigits, b[0])
+	}
+
+	if hi-lo > 1 {
+		return string(rankDigits[(lo+hi)/2])
+	}
+
+	if len(b) > 1 {
+		return b[:1]
+	}
+
+	rest := ""
+	if len(a) > 1 {
+		rest = a[1:]
+	}
+
+	return string(rankDigits[lo]) + midRank(rest, "")
+}
+
+// rankDigit returns i-th digit of the rank, missing digits are zeros.
+func rankDigit(rank string, i int) byte {
+	if i < len(rank) {
+		return rank[i]
+	}
+
+	return rankDigits[0]
+}
@@ -0,0 +1,52 @@
+package entity
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestRankBetween(t *testing.T) {
+	tests := []struct {
+		prev string
+		next string
+		want string
+	}{
+		{"", "", "i"},
+		{"i", "", "r"},
+		{"", "i", "9"},
+		{"a", "b", "ai"},
+		{"az", "b", "azi"},
+		{"a", "a1", "a0i"},
+		{"z", "", "zi"},
+		{"", "01", "00i"},
+	}
+
+	for _, tt := range tests {
+		rank, err := RankBetween(tt.prev, tt.next)
+		require.NoError(t, err)
+		require.Equal(t, tt.want, rank)
+		require.Greater(t, rank, tt.prev)
+
+		if tt.next != "" {
+			require.Less(t, rank, tt.next)
+		}
+	}
+
+	for _, tt := range [][2]string{{"b", "a"}, {"a", "a"}} {
+		_, err := RankBetween(tt[0], tt[1])
+		require.ErrorIs(t, err, ErrBadRequest)
+	}
+
+	// there is always room at the start of the column
+	first := ""
+	for range 100 {
+		rank, err := RankBetween("", first)
+		require.NoError(t, err)
+
+		if first != "" {
+			require.Less(t, rank, first)
+		}
+
+		first = rank
+	}
+}
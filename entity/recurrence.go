@@ -0,0 +1,195 @@
+package entity
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	FreqDaily   = "DAILY"
+	FreqWeekly  = "WEEKLY"
+	FreqMonthly = "MONTHLY"
+)
+
+var rruleWeekdays = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+// Recurrence repeats the template task by Rule. The template is the first occurrence due at StartsAt,
+// NextAt is the due date of the next occurrence to create, nil once the series has ended.
+type Recurrence struct {
+	TaskID      int64      `json:"task_id"`
+	Rule        string     `json:"rule"`
+	StartsAt    time.Time  `json:"starts_at"`
+	NextAt      *time.Time `json:"next_at"`
+	Occurrences int        `json:"occurrences"`
+	LastTaskID  *int64     `json:"last_task_id"`
+}
+
+// RRule is the supported subset of RFC 5545 recurrence rules: FREQ of DAILY, WEEKLY or MONTHLY,
+// INTERVAL, BYDAY without ordinals for daily and weekly rules, UNTIL and COUNT.
+type RRule struct {
+	Freq     string
+	Interval int
+	ByDay    []time.Weekday
+	Until    *time.Time
+	Count    int
+}
+
+func ParseRRule(s string) (RRule, error) {
+	r := RRule{Interval: 1}
+
+	for _, part := range strings.Split(strings.TrimPrefix(strings.TrimSpace(s), "RRULE:"), ";") {
+		name, value, ok := strings.Cut(part, "=")
+		if !ok || value == "" {
+			return RRule{}, fmt.Errorf("%w: invalid rule part %q", ErrBadRequest, part)
+		}
+
+		var err error
+
+		switch strings.ToUpper(name) {
+		case "FREQ":
+			r.Freq = strings.ToUpper(value)
+		case "INTERVAL":
+			r.Interval, err = strconv.Atoi(value)
+			if err != nil || r.Interval < 1 {
+				return RRule{}, fmt.Errorf("%w: invalid INTERVAL %q", ErrBadRequest, value)
+			}
+		case "COUNT":
+			r.Count, err = strconv.Atoi(value)
+			if err != nil || r.Count < 1 {
+				return RRule{}, fmt.Errorf("%w: invalid COUNT %q", ErrBadRequest, value)
+			}
+		case "UNTIL":
+			until, err := parseRRuleTime(value)
+			if err != nil {
+				return RRule{}, err
+			}
+
+			r.Until = &until
+		case "BYDAY":
+			for _, day := range strings.Split(strings.ToUpper(value), ",") {
+				wd, ok := rruleWeekdays[day]
+				if !ok {
+					return RRule{}, fmt.Errorf("%w: invalid BYDAY day %q", ErrBadRequest, day)
+				}
+
+				if !slices.Contains(r.ByDay, wd) {
+					r.ByDay = append(r.ByDay, wd)
+				}
+			}
+		default:
+			return RRule{}, fmt.Errorf("%w: unsupported rule part %q", ErrBadRequest, name)
+		}
+	}
+
+	switch r.Freq {
+	case FreqDaily, FreqWeekly:
+	case FreqMonthly:
+		if len(r.ByDay) != 0 {
+			return RRule{}, fmt.Errorf("%w: BYDAY is not supported for monthly rules", ErrBadRequest)
+		}
+	default:
+		return RRule{}, fmt.Errorf("%w: FREQ must be one of %s, %s, %s", ErrBadRequest, FreqDaily, FreqWeekly, FreqMonthly)
+	}
+
+	if r.Until != nil && r.Count != 0 {
+		return RRule{}, fmt.Errorf("%w: UNTIL and COUNT can't be used together", ErrBadRequest)
+	}
+
+	return r, nil
+}
+
+func parseRRuleTime(value string) (time.Time, error) {
+	for _, layout := range []string{"20060102T150405Z", "20060102"} {
+		t, err := time.Parse(layout, value)
+		if err == nil {
+			if len(value) == len("20060102") {
+				t = t.Add(24*time.Hour - time.Second)
+			}
+
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("%w: invalid UNTIL %q", ErrBadRequest, value)
+}
+
+// Next returns the occurrence following prev in the series starting at start, n is the number of
+// occurrences up to prev including it. False means the series has ended.
+func (r RRule) Next(start time.Time, prev time.Time, n int) (time.Time, bool) {
+	if r.Count != 0 && n >= r.Count {
+		return time.Time{}, false
+	}
+
+	next, ok := r.step(start, prev)
+	if !ok || r.Until != nil && next.After(*r.Until) {
+		return time.Time{}, false
+	}
+
+	return next, true
+}
+
+func (r RRule) step(start time.Time, prev time.Time) (time.Time, bool) {
+	switch r.Freq {
+	case FreqDaily:
+		// weekdays of every interval days repeat after 7 steps
+		next := prev
+		for range 7 {
+			next = next.AddDate(0, 0, r.Interval)
+			if r.matchesDay(next) {
+				return next, true
+			}
+		}
+	case FreqWeekly:
+		if len(r.ByDay) == 0 {
+			return prev.AddDate(0, 0, 7*r.Interval), true
+		}
+
+		next := prev
+		for range 7 * (r.Interval + 1) {
+			next = next.AddDate(0, 0, 1)
+			weeks := daysBetween(weekStart(start), weekStart(next)) / 7
+			if weeks%r.Interval == 0 && r.matchesDay(next) {
+				return next, true
+			}
+		}
+	case FreqMonthly:
+		// months shorter than the start day are skipped
+		for i := 1; i <= 12; i++ {
+			next := time.Date(prev.Year(), prev.Month()+time.Month(i*r.Interval), start.Day(),
+				start.Hour(), start.Minute(), start.Second(), start.Nanosecond(), start.Location())
+			if next.Day() == start.Day() {
+				return next, true
+			}
+		}
+	}
+
+	return time.Time{}, false
+}
+
+func (r RRule) matchesDay(t time.Time) bool {
+	return len(r.ByDay) == 0 || slices.Contains(r.ByDay, t.Weekday())
+}
+
+// weekStart returns Monday of the week of t, weeks start on Monday as RFC 5545 defaults to.
+func weekStart(t time.Time) time.Time {
+	return t.AddDate(0, 0, -(int(t.Weekday())+6)%7)
+}
+
+// daysBetween counts calendar days from a to b regardless of DST shifts.
+func daysBetween(a time.Time, b time.Time) int {
+	da := time.Date(a.Year(), a.Month(), a.Day(), 0, 0, 0, 0, time.UTC)
+	db := time.Date(b.Year(), b.Month(), b.Day(), 0, 0, 0, 0, time.UTC)
+
+	return int(db.Sub(da).Hours() / 24)
+}
@@ -0,0 +1,137 @@
+package entity
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestParseRRule(t *testing.T) {
+	until := time.Date(2026, 1, 31, 23, 59, 59, 0, time.UTC)
+	untilTime := time.Date(2026, 1, 31, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		rule string
+		want RRule
+	}{
+		{
+			name: "daily",
+			rule: "FREQ=DAILY",
+			want: RRule{Freq: FreqDaily, Interval: 1},
+		},
+		{
+			name: "weekly by day with interval",
+			rule: "RRULE:FREQ=weekly;INTERVAL=2;BYDAY=MO,we,MO",
+			want: RRule{Freq: FreqWeekly, Interval: 2, ByDay: []time.Weekday{time.Monday, time.Wednesday}},
+		},
+		{
+			name: "monthly count",
+			rule: "FREQ=MONTHLY;COUNT=3",
+			want: RRule{Freq: FreqMonthly, Interval: 1, Count: 3},
+		},
+		{
+			name: "until date lasts the whole day",
+			rule: "FREQ=DAILY;UNTIL=20260131",
+			want: RRule{Freq: FreqDaily, Interval: 1, Until: &until},
+		},
+		{
+			name: "until time",
+			rule: "FREQ=DAILY;UNTIL=20260131T120000Z",
+			want: RRule{Freq: FreqDaily, Interval: 1, Until: &untilTime},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := ParseRRule(tt.rule)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, r)
+		})
+	}
+
+	for _, rule := range []string{
+		"",
+		"FREQ=YEARLY",
+		"INTERVAL=2",
+		"FREQ=DAILY;INTERVAL=0",
+		"FREQ=DAILY;COUNT=0",
+		"FREQ=DAILY;UNTIL=tomorrow",
+		"FREQ=DAILY;BYDAY=XX",
+		"FREQ=DAILY;BYDAY=1MO",
+		"FREQ=DAILY;BYSETPOS=1",
+		"FREQ=DAILY;COUNT",
+		"FREQ=MONTHLY;BYDAY=MO",
+		"FREQ=DAILY;COUNT=2;UNTIL=20260131",
+	} {
+		_, err := ParseRRule(rule)
+		require.ErrorIs(t, err, ErrBadRequest, rule)
+	}
+}
+
+func TestRRule_Next(t *testing.T) {
+	// 2026-01-05 is a Monday
+	day := func(month time.Month, d int) time.Time {
+		return time.Date(2026, month, d, 9, 0, 0, 0, time.UTC)
+	}
+
+	start := day(1, 5)
+
+	tests := []struct {
+		name  string
+		rule  string
+		start time.Time
+		prev  time.Time
+		n     int
+		want  time.Time
+		ok    bool
+	}{
+		{name: "daily", rule: "FREQ=DAILY", start: start, prev: start, n: 1, want: day(1, 6), ok: true},
+		{name: "daily interval by day", rule: "FREQ=DAILY;INTERVAL=2;BYDAY=MO,WE,FR", start: start, prev: start, n: 1, want: day(1, 7), ok: true},
+		{name: "daily interval skips other days", rule: "FREQ=DAILY;INTERVAL=2;BYDAY=MO,WE,FR", start: start, prev: day(1, 9), n: 3, want: day(1, 19), ok: true},
+		{name: "weekly", rule: "FREQ=WEEKLY;INTERVAL=2", start: start, prev: start, n: 1, want: day(1, 19), ok: true},
+		{name: "weekly by day within the week", rule: "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR", start: start, prev: start, n: 1, want: day(1, 9), ok: true},
+		{name: "weekly by day skips weeks", rule: "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR", start: start, prev: day(1, 9), n: 2, want: day(1, 19), ok: true},
+		{name: "weekly by day later than start", rule: "FREQ=WEEKLY;INTERVAL=2;BYDAY=TU", start: start, prev: day(1, 6), n: 2, want: day(1, 20), ok: true},
+		{name: "monthly", rule: "FREQ=MONTHLY;INTERVAL=2", start: day(1, 15), prev: day(1, 15), n: 1, want: day(3, 15), ok: true},
+		{name: "monthly skips short months", rule: "FREQ=MONTHLY", start: day(1, 31), prev: day(1, 31), n: 1, want: day(3, 31), ok: true},
+		{name: "monthly keeps the start day", rule: "FREQ=MONTHLY", start: day(1, 31), prev: day(3, 31), n: 2, want: day(5, 31), ok: true},
+		{name: "count not reached", rule: "FREQ=DAILY;COUNT=3", start: start, prev: day(1, 6), n: 2, want: day(1, 7), ok: true},
+		{name: "count reached", rule: "FREQ=DAILY;COUNT=3", start: start, prev: day(1, 7), n: 3},
+		{name: "until not passed", rule: "FREQ=DAILY;UNTIL=20260106", start: start, prev: start, n: 1, want: day(1, 6), ok: true},
+		{name: "until passed", rule: "FREQ=DAILY;UNTIL=20260106", start: start, prev: day(1, 6), n: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := ParseRRule(tt.rule)
+			require.NoError(t, err)
+
+			next, ok := r.Next(tt.start, tt.prev, tt.n)
+			require.Equal(t, tt.ok, ok)
+			require.Equal(t, tt.want, next)
+		})
+	}
+}
+
+func TestRRule_step(t *testing.T) {
+	start := time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC)
+
+	// every 7 days always lands on Monday
+	r, err := ParseRRule("FREQ=DAILY;INTERVAL=7;BYDAY=TU")
+	require.NoError(t, err)
+
+	_, ok := r.step(start, start)
+	require.False(t, ok)
+
+	_, ok = RRule{Freq: "YEARLY", Interval: 1}.step(start, start)
+	require.False(t, ok)
+
+	// the time of day comes from the start even if prev drifted
+	r, err = ParseRRule("FREQ=MONTHLY")
+	require.NoError(t, err)
+
+	next, ok := r.step(start, start.Add(time.Hour))
+	require.True(t, ok)
+	require.Equal(t, time.Date(2026, 2, 5, 9, 0, 0, 0, time.UTC), next)
+}
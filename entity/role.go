@@ -0,0 +1,75 @@
+package entity
+
+import "fmt"
+
+type Role string
+
+const (
+	RoleOwner  Role = "owner"
+	RoleAdmin  Role = "admin"
+	RoleMember Role = "member"
+	RoleViewer Role = "viewer"
+)
+
+type Permission string
+
+const (
+	PermViewProject    Permission = "view_project"
+	PermEditTasks      Permission = "edit_tasks"
+	PermDeleteTasks    Permission = "delete_tasks"
+	PermInviteMembers  Permission = "invite_members"
+	PermManageRoles    Permission = "manage_roles"
+	PermManageWorkflow Permission = "manage_workflow"
+	PermManageLabels   Permission = "manage_labels"
+	PermManageFields   Permission = "manage_fields"
+	PermDeleteProject  Permission = "delete_project"
+)
+
+// permissions is a matrix of what every project role is allowed to do.
+var permissions = map[Role][]Permission{
+	RoleOwner: {
+		PermViewProject, PermEditTasks, PermDeleteTasks, PermInviteMembers,
+		PermManageRoles, PermManageWorkflow, PermManageLabels, PermManageFields, PermDeleteProject,
+	},
+	RoleAdmin: {
+		PermViewProject, PermEditTasks, PermDeleteTasks, PermInviteMembers,
+		PermManageRoles, PermManageWorkflow, PermManageLabels, PermManageFields,
+	},
+	RoleMember: {PermViewProject, PermEditTasks, PermManageLabels},
+	RoleViewer: {PermViewProject},
+}
+
+func (r Role) Can(p Permission) bool {
+	for _, v := range permissions[r] {
+		if v == p {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ValidateAssignable checks the role may be given to a member by invitation or role change.
+// Ownership belongs to the project creator and can't be handed out.
+func (r Role) ValidateAssignable() error {
+	switch r {
+	case RoleAdmin, RoleMember, RoleViewer:
+		return nil
+	case RoleOwner:
+		return fmt.Errorf("%w: owner role can't be assigned", ErrBadRequest)
+	default:
+		return fmt.Errorf("%w: unknown role %q", ErrBadRequest, r)
+	}
+}
+
+// Membership is a user's role in a project.
+type Membership struct {
+	ProjectID int64 `json:"project_id"`
+	UserID    int64 `json:"user_id"`
+	Role      Role  `json:"role"`
+}
+
+type ProjectMember struct {
+	User
+	Role Role `json:"role"`
+}
@@ -0,0 +1,18 @@
+package entity
+
+const (
+	SearchTypeTask    = "task"
+	SearchTypeProject = "project"
+)
+
+// SearchResult is a task or a project matching a search query.
+// Highlight and Snippet mark matched words with <mark></mark>, Snippet is empty for projects.
+type SearchResult struct {
+	Type      string  `json:"type"`
+	ID        int64   `json:"id"`
+	ProjectID int64   `json:"project_id"`
+	Name      string  `json:"name"`
+	Highlight string  `json:"highlight"`
+	Snippet   string  `json:"snippet,omitempty"`
+	Rank      float64 `json:"rank"`
+}
@@ -1,18 +1,163 @@
 package entity
 
-import "time"
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+)
 
 type Task struct {
-	ID          int64     `json:"id"`
-	Name        string    `json:"name"`
-	Description string    `json:"description"`
-	UserID      int64     `json:"user_id"`
-	ProjectID   int64     `json:"project_id"`
-	CreatedAt   time.Time `json:"created_at"`
+	ID          int64             `json:"id"`
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	UserID      int64             `json:"user_id"`
+	ProjectID   int64             `json:"project_id"`
+	ParentID    *int64            `json:"parent_id"`
+	Status      string            `json:"status"`
+	Priority    Priority          `json:"priority"`
+	Assignees   []int64           `json:"assignees"`
+	Labels      []Label           `json:"labels"`
+	BlockedBy   []int64           `json:"blocked_by"`
+	Blocking    []int64           `json:"blocking"`
+	DueAt       *time.Time        `json:"due_at"`
+	Overdue     bool              `json:"overdue"`
+	Progress    Progress          `json:"progress"`
+	Checklist   ChecklistProgress `json:"checklist"`
+	Rank        string            `json:"rank"`
+	CreatedAt   time.Time         `json:"created_at"`
+	// CustomFields holds values of the project custom fields by field id.
+	CustomFields map[int64]json.RawMessage `json:"custom_fields"`
+}
+
+// Progress counts done subtasks of a task among all its subtasks at any depth.
+type Progress struct {
+	Done  int `json:"done"`
+	Total int `json:"total"`
 }
 
 type TaskToCreate struct {
-	Name        string `json:"name"`
-	ProjectID   int64  `json:"project_id"`
-	Description string `json:"description"`
+	Name         string                    `json:"name"`
+	ProjectID    int64                     `json:"project_id"`
+	ParentID     *int64                    `json:"parent_id"`
+	Description  string                    `json:"description"`
+	Priority     Priority                  `json:"priority"`
+	DueAt        *time.Time                `json:"due_at"`
+	CustomFields map[int64]json.RawMessage `json:"custom_fields"`
+}
+
+// TaskToUpdate holds fields to change, nil fields are left untouched.
+type TaskToUpdate struct {
+	Name        *string             `json:"name"`
+	Description *string             `json:"description"`
+	Status      *string             `json:"status"`
+	Priority    *Priority           `json:"priority"`
+	DueAt       Nullable[time.Time] `json:"due_at"`
+	ParentID    Nullable[int64]     `json:"parent_id"`
+	// CustomFields sets values by field id, null removes a value.
+	CustomFields map[int64]json.RawMessage `json:"custom_fields"`
+}
+
+func (tu *TaskToUpdate) Validate() error {
+	if tu.Name != nil && *tu.Name == "" {
+		return fmt.Errorf("%w: invalid name field", ErrBadRequest)
+	}
+
+	if tu.Status != nil && *tu.Status == "" {
+		return fmt.Errorf("%w: invalid status field", ErrBadRequest)
+	}
+
+	if tu.Priority != nil {
+		return tu.Priority.Validate()
+	}
+
+	return nil
+}
+
+func (tu *TaskToUpdate) IsEmpty() bool {
+	return tu.Name == nil && tu.Description == nil && tu.Status == nil && tu.Priority == nil && !tu.DueAt.Set && !tu.ParentID.Set &&
+		len(tu.CustomFields) == 0
+}
+
+const MaxTransferTasks = 100
+
+// TaskTransfer moves tasks with all their subtasks to the project with ProjectID.
+type TaskTransfer struct {
+	ProjectID int64   `json:"project_id"`
+	TaskIDs   []int64 `json:"task_ids"`
+}
+
+func (tt *TaskTransfer) Validate() error {
+	if len(tt.TaskIDs) == 0 {
+		return fmt.Errorf("%w: no tasks to move", ErrBadRequest)
+	}
+
+	if len(tt.TaskIDs) > MaxTransferTasks {
+		return fmt.Errorf("%w: can't move more than %d tasks at once", ErrBadRequest, MaxTransferTasks)
+	}
+
+	for i, id := range tt.TaskIDs {
+		if slices.Contains(tt.TaskIDs[:i], id) {
+			return fmt.Errorf("%w: task %d is listed twice", ErrBadRequest, id)
+		}
+	}
+
+	return nil
+}
+
+type Priority int
+
+const (
+	PriorityLow Priority = iota + 1
+	PriorityMedium
+	PriorityHigh
+	PriorityUrgent
+)
+
+func (p Priority) Validate() error {
+	if p < PriorityLow || p > PriorityUrgent {
+		return fmt.Errorf("%w: priority must be from %d to %d", ErrBadRequest, PriorityLow, PriorityUrgent)
+	}
+
+	return nil
+}
+
+const (
+	SortByPriority  = "priority"
+	SortByCreatedAt = "created_at"
+	SortByDueAt     = "due_at"
+	SortByName      = "name"
+	SortByRank      = "rank"
+)
+
+// TaskQuery narrows down, orders and paginates task listings.
+// Tasks are sorted by creation time when Sort is empty, tasks without due date go last when sorting by it.
+type TaskQuery struct {
+	DueFrom *time.Time
+	DueTo   *time.Time
+	Filters []Filter
+	Sort    string
+	Desc    bool
+	Page    PageRequest
+}
+
+// Reminder is a notification about a task approaching or passing its due date.
+type Reminder struct {
+	TaskID    int64
+	TaskName  string
+	DueAt     time.Time
+	Threshold ReminderThreshold
+	Receivers []string
+}
+
+// ReminderThreshold is how long before the due date a reminder is sent, zero Before means the task is overdue.
+type ReminderThreshold struct {
+	Name   string
+	Before time.Duration
+}
+
+var ReminderThresholds = []ReminderThreshold{
+	{Name: "24h", Before: 24 * time.Hour},
+	{Name: "1h", Before: time.Hour},
+	{Name: "overdue"},
 }
@@ -0,0 +1,49 @@
+package entity
+
+import "fmt"
+
+// Ways to deal with subtasks of a deleted task: delete them along with it
+// or hand them over to the parent of the deleted task.
+const (
+	SubtasksCascade  = "cascade"
+	SubtasksReparent = "reparent"
+)
+
+func ValidateSubtasksMode(mode string) error {
+	switch mode {
+	case SubtasksCascade, SubtasksReparent:
+		return nil
+	default:
+		return fmt.Errorf("%w: subtasks must be %s or %s", ErrBadRequest, SubtasksCascade, SubtasksReparent)
+	}
+}
+
+// TaskTree is a task with its subtasks at any depth.
+type TaskTree struct {
+	Task
+	Subtasks []TaskTree `json:"subtasks"`
+}
+
+// BuildTaskTree puts descendants of root under their parents keeping their order.
+func BuildTaskTree(root Task, descendants []Task) TaskTree {
+	children := make(map[int64][]Task)
+
+	for _, t := range descendants {
+		if t.ParentID != nil {
+			children[*t.ParentID] = append(children[*t.ParentID], t)
+		}
+	}
+
+	var build func(t Task) TaskTree
+	build = func(t Task) TaskTree {
+		tree := TaskTree{Task: t, Subtasks: []TaskTree{}}
+
+		for _, child := range children[t.ID] {
+			tree.Subtasks = append(tree.Subtasks, build(child))
+		}
+
+		return tree
+	}
+
+	return build(root)
+}
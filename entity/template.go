@@ -0,0 +1,129 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+)
+
+const MaxTemplateTasks = 1000
+
+// ProjectTemplate is a saved project structure new projects can be created from: a workflow and
+// tasks listed in board order, so instantiated tasks keep their order within status columns.
+type ProjectTemplate struct {
+	ID          int64          `json:"id"`
+	UserID      int64          `json:"user_id"`
+	Name        string         `json:"name"`
+	Statuses    []Status       `json:"statuses"`
+	Transitions []Transition   `json:"transitions"`
+	Tasks       []TemplateTask `json:"tasks"`
+	CreatedAt   time.Time      `json:"created_at"`
+}
+
+// TemplateTask is a task of a template, Parent is the index of its parent task in the template.
+type TemplateTask struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Priority    Priority `json:"priority"`
+	Status      string   `json:"status"`
+	Parent      *int     `json:"parent"`
+}
+
+// NewProjectTemplate captures the workflow and tasks of a project, tasks must be in board order.
+func NewProjectTemplate(name string, w Workflow, tasks []Task) ProjectTemplate {
+	index := make(map[int64]int, len(tasks))
+	for i, t := range tasks {
+		index[t.ID] = i
+	}
+
+	tmpl := ProjectTemplate{
+		Name:        name,
+		Statuses:    w.Statuses,
+		Transitions: w.Transitions,
+		Tasks:       make([]TemplateTask, len(tasks)),
+	}
+
+	for i, t := range tasks {
+		tmpl.Tasks[i] = TemplateTask{Name: t.Name, Description: t.Description, Priority: t.Priority, Status: t.Status}
+
+		if t.ParentID != nil {
+			if parent, ok := index[*t.ParentID]; ok {
+				tmpl.Tasks[i].Parent = &parent
+			}
+		}
+	}
+
+	return tmpl
+}
+
+// Workflow returns the template workflow for the project.
+func (pt *ProjectTemplate) Workflow(projectID int64) Workflow {
+	return Workflow{ProjectID: projectID, Statuses: pt.Statuses, Transitions: pt.Transitions}
+}
+
+// Validate checks the template and fills defaults: the default workflow when it has no statuses,
+// the initial status and medium priority for tasks without them.
+func (pt *ProjectTemplate) Validate() error {
+	if pt.Name == "" {
+		return fmt.Errorf("%w: empty template name", ErrBadRequest)
+	}
+
+	if len(pt.Statuses) == 0 {
+		w := DefaultWorkflow(0)
+		pt.Statuses, pt.Transitions = w.Statuses, w.Transitions
+	}
+
+	w := pt.Workflow(0)
+
+	err := w.Validate()
+	if err != nil {
+		return err
+	}
+
+	if len(pt.Tasks) > MaxTemplateTasks {
+		return fmt.Errorf("%w: template can't have more than %d tasks", ErrBadRequest, MaxTemplateTasks)
+	}
+
+	for i := range pt.Tasks {
+		t := &pt.Tasks[i]
+
+		if t.Name == "" {
+			return fmt.Errorf("%w: template task %d has empty name", ErrBadRequest, i)
+		}
+
+		if t.Priority == 0 {
+			t.Priority = PriorityMedium
+		}
+
+		err = t.Priority.Validate()
+		if err != nil {
+			return err
+		}
+
+		if t.Status == "" {
+			t.Status = w.Initial()
+		}
+
+		if !w.HasStatus(t.Status) {
+			return fmt.Errorf("%w: template task %d has unknown status %q", ErrBadRequest, i, t.Status)
+		}
+
+		if t.Parent != nil && (*t.Parent < 0 || *t.Parent >= len(pt.Tasks)) {
+			return fmt.Errorf("%w: template task %d has unknown parent %d", ErrBadRequest, i, *t.Parent)
+		}
+	}
+
+	// walking up from any task must reach the top within len(Tasks) steps
+	for i := range pt.Tasks {
+		parent := pt.Tasks[i].Parent
+
+		for steps := 0; parent != nil; steps++ {
+			if steps == len(pt.Tasks) {
+				return fmt.Errorf("%w: template task %d is its own ancestor", ErrBadRequest, i)
+			}
+
+			parent = pt.Tasks[*parent].Parent
+		}
+	}
+
+	return nil
+}
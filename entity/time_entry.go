@@ -0,0 +1,73 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	MaxTimeEntryDuration = 24 * time.Hour
+	MaxTimeEntryNote     = 1000
+)
+
+// TimeEntry is time a user spent on a task. EndedAt is nil while the timer runs,
+// Duration is in seconds and counts up to now for a running timer.
+type TimeEntry struct {
+	ID        int64      `json:"id"`
+	TaskID    int64      `json:"task_id"`
+	UserID    int64      `json:"user_id"`
+	StartedAt time.Time  `json:"started_at"`
+	EndedAt   *time.Time `json:"ended_at"`
+	Duration  int64      `json:"duration"`
+	Note      string     `json:"note"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// TimeEntryToCreate is a manual entry, Duration is in seconds.
+type TimeEntryToCreate struct {
+	StartedAt time.Time `json:"started_at"`
+	Duration  int64     `json:"duration"`
+	Note      string    `json:"note"`
+}
+
+func (tc *TimeEntryToCreate) Validate(now time.Time) error {
+	if tc.StartedAt.IsZero() {
+		return fmt.Errorf("%w: invalid started_at field", ErrBadRequest)
+	}
+
+	if tc.Duration <= 0 || tc.Duration > int64(MaxTimeEntryDuration/time.Second) {
+		return fmt.Errorf("%w: duration must be from 1 to %d seconds", ErrBadRequest, int64(MaxTimeEntryDuration/time.Second))
+	}
+
+	if tc.EndedAt().After(now) {
+		return fmt.Errorf("%w: time entry can't end in the future", ErrBadRequest)
+	}
+
+	return ValidateTimeEntryNote(tc.Note)
+}
+
+func (tc *TimeEntryToCreate) EndedAt() time.Time {
+	return tc.StartedAt.Add(time.Duration(tc.Duration) * time.Second)
+}
+
+func ValidateTimeEntryNote(note string) error {
+	if utf8.RuneCountInString(note) > MaxTimeEntryNote {
+		return fmt.Errorf("%w: note is longer than %d characters", ErrBadRequest, MaxTimeEntryNote)
+	}
+
+	return nil
+}
+
+// TimeQuery narrows down time totals to entries started in [From, To) and to the user if UserID is set.
+type TimeQuery struct {
+	From   *time.Time
+	To     *time.Time
+	UserID *int64
+}
+
+// TimeTotal sums up durations of time entries in seconds.
+type TimeTotal struct {
+	Seconds int64 `json:"seconds"`
+	Entries int   `json:"entries"`
+}
@@ -0,0 +1,126 @@
+package entity
+
+import "fmt"
+
+const (
+	StatusTodo       = "todo"
+	StatusInProgress = "in_progress"
+	StatusReview     = "review"
+	StatusDone       = "done"
+)
+
+type Status struct {
+	Name string `json:"name"`
+	Done bool   `json:"done"`
+}
+
+type Transition struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// Workflow is a set of task statuses of a project and transitions allowed between them.
+// The first status is the one new tasks start with.
+type Workflow struct {
+	ProjectID   int64        `json:"project_id"`
+	Statuses    []Status     `json:"statuses"`
+	Transitions []Transition `json:"transitions"`
+}
+
+// DefaultWorkflow returns workflow every new project starts with.
+func DefaultWorkflow(projectID int64) Workflow {
+	return Workflow{
+		ProjectID: projectID,
+		Statuses: []Status{
+			{Name: StatusTodo},
+			{Name: StatusInProgress},
+			{Name: StatusReview},
+			{Name: StatusDone, Done: true},
+		},
+		Transitions: []Transition{
+			{From: StatusTodo, To: StatusInProgress},
+			{From: StatusInProgress, To: StatusTodo},
+			{From: StatusInProgress, To: StatusReview},
+			{From: StatusReview, To: StatusInProgress},
+			{From: StatusReview, To: StatusDone},
+			{From: StatusDone, To: StatusTodo},
+		},
+	}
+}
+
+func (w Workflow) Validate() error {
+	if len(w.Statuses) == 0 {
+		return fmt.Errorf("%w: workflow must have at least one status", ErrBadRequest)
+	}
+
+	names := make(map[string]bool, len(w.Statuses))
+	hasDone := false
+
+	for _, s := range w.Statuses {
+		if s.Name == "" {
+			return fmt.Errorf("%w: empty status name", ErrBadRequest)
+		}
+
+		if names[s.Name] {
+			return fmt.Errorf("%w: duplicate status %q", ErrBadRequest, s.Name)
+		}
+
+		names[s.Name] = true
+		hasDone = hasDone || s.Done
+	}
+
+	if !hasDone {
+		return fmt.Errorf("%w: workflow must have at least one done status", ErrBadRequest)
+	}
+
+	for _, t := range w.Transitions {
+		if !names[t.From] || !names[t.To] {
+			return fmt.Errorf("%w: transition %q -> %q uses unknown status", ErrBadRequest, t.From, t.To)
+		}
+
+		if t.From == t.To {
+			return fmt.Errorf("%w: transition %q -> %q leads to the same status", ErrBadRequest, t.From, t.To)
+		}
+	}
+
+	return nil
+}
+
+// Initial returns status new tasks are created with.
+func (w Workflow) Initial() string {
+	if len(w.Statuses) == 0 {
+		return ""
+	}
+
+	return w.Statuses[0].Name
+}
+
+func (w Workflow) HasStatus(name string) bool {
+	for _, s := range w.Statuses {
+		if s.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (w Workflow) IsDone(name string) bool {
+	for _, s := range w.Statuses {
+		if s.Name == name {
+			return s.Done
+		}
+	}
+
+	return false
+}
+
+func (w Workflow) CanTransition(from string, to string) bool {
+	for _, t := range w.Transitions {
+		if t.From == from && t.To == to {
+			return true
+		}
+	}
+
+	return false
+}
@@ -47,6 +47,19 @@ func main() {
 	userRepo := repository.NewUserRepository(db)
 	authRepo := repository.NewAuthRepository(db)
 	taskRepo := repository.NewTaskRepository(db)
+	searchRepo := repository.NewSearchRepository(db)
+	commentRepo := repository.NewCommentRepository(db)
+	attachmentRepo := repository.NewAttachmentRepository(db)
+	historyRepo := repository.NewHistoryRepository(db)
+	activityRepo := repository.NewActivityRepository(db)
+	timeRepo := repository.NewTimeRepository(db)
+	templateRepo := repository.NewTemplateRepository(db)
+
+	storage, err := repository.NewLocalStorage(cfg.AttachmentsDir)
+	if err != nil {
+		logger.Error("Problem with attachment storage", "error", err)
+		return
+	}
 
 	client, err := bootstrap.RedisConnect(cfg.RedisAddr)
 	if err != nil {
@@ -57,18 +70,28 @@ func main() {
 
 	cache := repository.NewRedisCache(userRepo, client)
 
-	userServ := service.NewUserService(cache, authRepo, projRepo, kafkaConn)
+	userServ := service.NewUserService(cache, authRepo, projRepo, historyRepo, kafkaConn)
 	authServ := service.NewAuthService(authRepo, userRepo, kafkaConn)
-	projServ := service.NewProjectRepository(authRepo, projRepo, taskRepo, userRepo, kafkaConn)
+	projServ := service.NewProjectRepository(authRepo, projRepo, taskRepo, userRepo, historyRepo, activityRepo, kafkaConn)
+	searchServ := service.NewSearchService(searchRepo)
+	commentServ := service.NewCommentService(commentRepo, projRepo, taskRepo, userRepo, kafkaConn)
+	attachmentServ := service.NewAttachmentService(attachmentRepo, storage, projRepo, taskRepo)
+	timeServ := service.NewTimeService(timeRepo, projRepo, taskRepo)
+	templateServ := service.NewTemplateService(templateRepo, projRepo, taskRepo, historyRepo, activityRepo)
 
 	taskHandler := api.NewTaskHandler(projServ)
 	projectHandler := api.NewProjectHandler(projServ)
 	userHandler := api.NewUserHandler(userServ)
 	authHandler := api.NewAuthHandler(authServ)
+	searchHandler := api.NewSearchHandler(searchServ)
+	commentHandler := api.NewCommentHandler(commentServ)
+	attachmentHandler := api.NewAttachmentHandler(attachmentServ)
+	timeHandler := api.NewTimeHandler(timeServ)
+	templateHandler := api.NewTemplateHandler(templateServ)
 
 	mw := api.NewMiddleware(authServ, logger)
 
-	server := api.NewServer(taskHandler, projectHandler, userHandler, authHandler, cfg.HTTPPort, mw)
+	server := api.NewServer(taskHandler, projectHandler, userHandler, authHandler, searchHandler, commentHandler, attachmentHandler, timeHandler, templateHandler, cfg.HTTPPort, mw)
 
 	go func() {
 		for {
@@ -81,6 +104,39 @@ func main() {
 		}
 	}()
 
+	go func() {
+		for {
+			err := projServ.SendDueReminders(context.Background(), *logger)
+			if err != nil {
+				logger.Error("Due reminder sender error", "error", err)
+			}
+
+			time.Sleep(time.Minute)
+		}
+	}()
+
+	go func() {
+		for {
+			err := projServ.GenerateRecurringTasks(context.Background(), *logger)
+			if err != nil {
+				logger.Error("Recurring task generator error", "error", err)
+			}
+
+			time.Sleep(time.Minute)
+		}
+	}()
+
+	go func() {
+		for {
+			err := attachmentServ.DeleteBlobs(context.Background(), *logger)
+			if err != nil {
+				logger.Error("Attachment blob cleanup error", "error", err)
+			}
+
+			time.Sleep(time.Minute)
+		}
+	}()
+
 	err = server.Start()
 	if err != nil {
 		logger.Error("server start error", "error", err)
@@ -0,0 +1,10 @@
+-- +goose Up
+ALTER TABLE projects_users ADD COLUMN role TEXT NOT NULL DEFAULT 'member';
+
+UPDATE projects_users pu SET role = 'owner' FROM projects p WHERE p.id = pu.project_id AND p.user_id = pu.user_id;
+
+ALTER TABLE invitation_codes ADD COLUMN role TEXT NOT NULL DEFAULT 'member';
+
+-- +goose Down
+ALTER TABLE invitation_codes DROP COLUMN role;
+ALTER TABLE projects_users DROP COLUMN role;
@@ -0,0 +1,16 @@
+-- +goose Up
+ALTER TABLE tasks ADD COLUMN due_at TIMESTAMPTZ;
+
+CREATE INDEX tasks_due_at_idx ON tasks(due_at) WHERE due_at IS NOT NULL;
+
+CREATE TABLE task_reminders(
+    task_id BIGINT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    threshold TEXT NOT NULL,
+    due_at TIMESTAMPTZ NOT NULL,
+    sent_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (task_id, threshold, due_at)
+);
+
+-- +goose Down
+DROP TABLE task_reminders;
+ALTER TABLE tasks DROP COLUMN due_at;
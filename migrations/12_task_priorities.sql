@@ -0,0 +1,8 @@
+-- +goose Up
+ALTER TABLE tasks ADD COLUMN priority SMALLINT NOT NULL DEFAULT 2;
+
+CREATE INDEX tasks_project_id_priority_idx ON tasks(project_id, priority, id);
+
+-- +goose Down
+DROP INDEX tasks_project_id_priority_idx;
+ALTER TABLE tasks DROP COLUMN priority;
@@ -0,0 +1,17 @@
+-- +goose Up
+ALTER TABLE tasks ADD COLUMN search tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('english', name), 'A') || setweight(to_tsvector('english', description), 'B')
+) STORED;
+
+ALTER TABLE projects ADD COLUMN search tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('english', name), 'A')
+) STORED;
+
+CREATE INDEX tasks_search_idx ON tasks USING GIN(search);
+CREATE INDEX projects_search_idx ON projects USING GIN(search);
+
+-- +goose Down
+DROP INDEX projects_search_idx;
+DROP INDEX tasks_search_idx;
+ALTER TABLE projects DROP COLUMN search;
+ALTER TABLE tasks DROP COLUMN search;
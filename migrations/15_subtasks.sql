@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE tasks ADD COLUMN parent_task_id BIGINT REFERENCES tasks(id) ON DELETE CASCADE;
+
+CREATE INDEX tasks_parent_task_id_idx ON tasks(parent_task_id);
+
+-- +goose Down
+ALTER TABLE tasks DROP COLUMN parent_task_id;
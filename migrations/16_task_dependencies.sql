@@ -0,0 +1,12 @@
+-- +goose Up
+CREATE TABLE task_dependencies(
+    task_id BIGINT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    blocker_id BIGINT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    PRIMARY KEY (task_id, blocker_id),
+    CHECK (task_id <> blocker_id)
+);
+
+CREATE INDEX task_dependencies_blocker_id_idx ON task_dependencies(blocker_id);
+
+-- +goose Down
+DROP TABLE task_dependencies;
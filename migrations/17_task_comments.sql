@@ -0,0 +1,14 @@
+-- +goose Up
+CREATE TABLE task_comments(
+    id BIGSERIAL PRIMARY KEY,
+    task_id BIGINT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    body TEXT NOT NULL,
+    created_at timestamptz NOT NULL,
+    updated_at timestamptz
+);
+
+CREATE INDEX task_comments_task_id_idx ON task_comments(task_id, id);
+
+-- +goose Down
+DROP TABLE task_comments;
@@ -0,0 +1,38 @@
+-- +goose Up
+CREATE TABLE task_attachments(
+    id BIGSERIAL PRIMARY KEY,
+    task_id BIGINT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    content_type TEXT NOT NULL,
+    size BIGINT NOT NULL,
+    storage_key TEXT NOT NULL UNIQUE,
+    created_at timestamptz NOT NULL
+);
+
+CREATE INDEX task_attachments_task_id_idx ON task_attachments(task_id);
+
+-- blobs of deleted attachments wait here until they are removed from storage,
+-- so blobs are cleaned up whatever deleted the rows: a task, a project or a user
+CREATE TABLE blob_deletions(
+    storage_key TEXT PRIMARY KEY,
+    created_at timestamptz NOT NULL DEFAULT NOW()
+);
+
+-- +goose StatementBegin
+CREATE FUNCTION enqueue_blob_deletion() RETURNS trigger AS $$
+BEGIN
+    INSERT INTO blob_deletions(storage_key) VALUES (OLD.storage_key) ON CONFLICT DO NOTHING;
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER task_attachments_blob_deletion AFTER DELETE ON task_attachments
+    FOR EACH ROW EXECUTE FUNCTION enqueue_blob_deletion();
+
+-- +goose Down
+DROP TRIGGER task_attachments_blob_deletion ON task_attachments;
+DROP FUNCTION enqueue_blob_deletion();
+DROP TABLE blob_deletions;
+DROP TABLE task_attachments;
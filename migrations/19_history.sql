@@ -0,0 +1,18 @@
+-- +goose Up
+-- history is append-only, task_id has no foreign key so changes of deleted tasks stay in the project history
+CREATE TABLE history(
+    id BIGSERIAL PRIMARY KEY,
+    project_id BIGINT NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    task_id BIGINT,
+    actor_id BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    field TEXT NOT NULL,
+    old_value JSONB NOT NULL,
+    new_value JSONB NOT NULL,
+    created_at timestamptz NOT NULL
+);
+
+CREATE INDEX history_project_id_idx ON history(project_id, id);
+CREATE INDEX history_task_id_idx ON history(task_id, id) WHERE task_id IS NOT NULL;
+
+-- +goose Down
+DROP TABLE history;
@@ -0,0 +1,18 @@
+-- +goose Up
+-- activities is a denormalized feed, names are copied at the time of the event so reading it needs no joins
+CREATE TABLE activities(
+    id BIGSERIAL PRIMARY KEY,
+    project_id BIGINT NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    project_name TEXT NOT NULL,
+    actor_id BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    actor_name TEXT NOT NULL,
+    type TEXT NOT NULL,
+    task_id BIGINT,
+    subject TEXT NOT NULL DEFAULT '',
+    created_at timestamptz NOT NULL
+);
+
+CREATE INDEX activities_project_id_idx ON activities(project_id, id);
+
+-- +goose Down
+DROP TABLE activities;
@@ -0,0 +1,17 @@
+-- +goose Up
+-- rank orders tasks within a board column, ranks are compared bytewise and end with a non-zero digit
+ALTER TABLE tasks ADD COLUMN rank TEXT COLLATE "C";
+
+UPDATE tasks t SET rank = r.rank
+FROM (
+    SELECT id, lpad(row_number() OVER (PARTITION BY project_id ORDER BY created_at, id)::text, 10, '0') || 'i' AS rank
+    FROM tasks
+) r
+WHERE t.id = r.id;
+
+ALTER TABLE tasks ALTER COLUMN rank SET NOT NULL;
+
+CREATE INDEX tasks_rank_idx ON tasks(project_id, status, rank);
+
+-- +goose Down
+ALTER TABLE tasks DROP COLUMN rank;
@@ -0,0 +1,15 @@
+-- +goose Up
+-- task_id is the template task, occurrences are new tasks copied from it, last_task_id is the latest of them
+CREATE TABLE task_recurrences(
+    task_id BIGINT PRIMARY KEY REFERENCES tasks(id) ON DELETE CASCADE,
+    rule TEXT NOT NULL,
+    starts_at timestamptz NOT NULL,
+    next_at timestamptz,
+    occurrences INT NOT NULL DEFAULT 1,
+    last_task_id BIGINT REFERENCES tasks(id) ON DELETE SET NULL
+);
+
+CREATE INDEX task_recurrences_next_at_idx ON task_recurrences(next_at) WHERE next_at IS NOT NULL;
+
+-- +goose Down
+DROP TABLE task_recurrences;
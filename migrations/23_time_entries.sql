@@ -0,0 +1,18 @@
+-- +goose Up
+-- ended_at is NULL while the timer runs, a user can have only one running timer
+CREATE TABLE time_entries(
+    id BIGSERIAL PRIMARY KEY,
+    task_id BIGINT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    started_at timestamptz NOT NULL,
+    ended_at timestamptz CHECK (ended_at >= started_at),
+    note TEXT NOT NULL DEFAULT '',
+    created_at timestamptz NOT NULL
+);
+
+CREATE UNIQUE INDEX time_entries_running_idx ON time_entries(user_id) WHERE ended_at IS NULL;
+CREATE INDEX time_entries_task_id_idx ON time_entries(task_id, started_at);
+CREATE INDEX time_entries_user_id_idx ON time_entries(user_id, started_at);
+
+-- +goose Down
+DROP TABLE time_entries;
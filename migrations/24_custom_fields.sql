@@ -0,0 +1,23 @@
+-- +goose Up
+CREATE TABLE custom_fields(
+    id BIGSERIAL PRIMARY KEY,
+    project_id BIGINT NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    type TEXT NOT NULL,
+    options TEXT[] NOT NULL DEFAULT '{}',
+    UNIQUE (project_id, name)
+);
+
+-- values are validated against the field type by the service, so fields don't need a column each
+CREATE TABLE task_field_values(
+    task_id BIGINT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    field_id BIGINT NOT NULL REFERENCES custom_fields(id) ON DELETE CASCADE,
+    value JSONB NOT NULL,
+    PRIMARY KEY (task_id, field_id)
+);
+
+CREATE INDEX task_field_values_field_id_idx ON task_field_values(field_id);
+
+-- +goose Down
+DROP TABLE task_field_values;
+DROP TABLE custom_fields;
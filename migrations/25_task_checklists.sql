@@ -0,0 +1,15 @@
+-- +goose Up
+CREATE TABLE checklist_items(
+    id BIGSERIAL PRIMARY KEY,
+    task_id BIGINT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    text TEXT NOT NULL,
+    checked BOOLEAN NOT NULL DEFAULT FALSE,
+    responsible_id BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    position INT NOT NULL,
+    created_at timestamptz NOT NULL
+);
+
+CREATE INDEX checklist_items_task_id_idx ON checklist_items(task_id, position);
+
+-- +goose Down
+DROP TABLE checklist_items;
@@ -0,0 +1,16 @@
+-- +goose Up
+-- a template is a snapshot instantiated as a whole, so its workflow and tasks are kept as JSON documents
+CREATE TABLE project_templates(
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    statuses JSONB NOT NULL,
+    transitions JSONB NOT NULL,
+    tasks JSONB NOT NULL,
+    created_at timestamptz NOT NULL
+);
+
+CREATE INDEX project_templates_user_id_idx ON project_templates(user_id, id);
+
+-- +goose Down
+DROP TABLE project_templates;
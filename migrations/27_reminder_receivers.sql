@@ -0,0 +1,13 @@
+-- +goose Up
+-- reminders are recorded per receiver so a failed send is retried only for those who missed it,
+-- an empty receiver marks reminders sent to everyone before the split
+ALTER TABLE task_reminders ADD COLUMN receiver TEXT NOT NULL DEFAULT '';
+ALTER TABLE task_reminders DROP CONSTRAINT task_reminders_pkey;
+ALTER TABLE task_reminders ADD PRIMARY KEY (task_id, threshold, due_at, receiver);
+
+-- +goose Down
+DELETE FROM task_reminders a USING task_reminders b
+WHERE a.task_id = b.task_id AND a.threshold = b.threshold AND a.due_at = b.due_at AND a.receiver > b.receiver;
+ALTER TABLE task_reminders DROP CONSTRAINT task_reminders_pkey;
+ALTER TABLE task_reminders DROP COLUMN receiver;
+ALTER TABLE task_reminders ADD PRIMARY KEY (task_id, threshold, due_at);
@@ -0,0 +1,35 @@
+-- +goose Up
+CREATE TABLE project_statuses(
+    project_id BIGINT NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    position INT NOT NULL,
+    done BOOLEAN NOT NULL DEFAULT FALSE,
+    PRIMARY KEY (project_id, name)
+);
+
+CREATE TABLE project_transitions(
+    project_id BIGINT NOT NULL,
+    from_status TEXT NOT NULL,
+    to_status TEXT NOT NULL,
+    PRIMARY KEY (project_id, from_status, to_status),
+    FOREIGN KEY (project_id, from_status) REFERENCES project_statuses(project_id, name) ON DELETE CASCADE,
+    FOREIGN KEY (project_id, to_status) REFERENCES project_statuses(project_id, name) ON DELETE CASCADE
+);
+
+ALTER TABLE tasks ADD COLUMN status TEXT NOT NULL DEFAULT 'todo';
+
+INSERT INTO project_statuses(project_id, name, position, done)
+SELECT p.id, s.name, s.position, s.done
+FROM projects p
+    CROSS JOIN (VALUES ('todo', 0, FALSE), ('in_progress', 1, FALSE), ('review', 2, FALSE), ('done', 3, TRUE)) AS s(name, position, done);
+
+INSERT INTO project_transitions(project_id, from_status, to_status)
+SELECT p.id, t.from_status, t.to_status
+FROM projects p
+    CROSS JOIN (VALUES ('todo', 'in_progress'), ('in_progress', 'todo'), ('in_progress', 'review'),
+                       ('review', 'in_progress'), ('review', 'done'), ('done', 'todo')) AS t(from_status, to_status);
+
+-- +goose Down
+ALTER TABLE tasks DROP COLUMN status;
+DROP TABLE project_transitions;
+DROP TABLE project_statuses;
@@ -0,0 +1,11 @@
+-- +goose Up
+CREATE TABLE task_assignees(
+    task_id BIGINT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    PRIMARY KEY (task_id, user_id)
+);
+
+CREATE INDEX task_assignees_user_id_idx ON task_assignees(user_id);
+
+-- +goose Down
+DROP TABLE task_assignees;
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"task-manager/entity"
+)
+
+type ActivityRepository struct {
+	db *sql.DB
+}
+
+func NewActivityRepository(database *sql.DB) *ActivityRepository {
+	return &ActivityRepository{db: database}
+}
+
+// AddActivity appends the activity to the feed, project and actor names are taken from their current rows.
+func (r *ActivityRepository) AddActivity(ctx context.Context, a entity.Activity) error {
+	q := `INSERT INTO activities(project_id, project_name, actor_id, actor_name, type, task_id, subject, created_at)
+	SELECT p.id, p.name, u.id, u.name, $3, $4, $5, $6 FROM projects p, users u WHERE p.id = $1 AND u.id = $2`
+
+	res, err := r.db.ExecContext(ctx, q, a.ProjectID, a.ActorID, a.Type, a.TaskID, a.Subject, a.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return entity.ErrNotFound
+	}
+
+	return nil
+}
+
+// ProjectActivity returns the project feed, the latest first.
+func (r *ActivityRepository) ProjectActivity(ctx context.Context, projectID int64, pr entity.PageRequest) (entity.Page[entity.Activity], error) {
+	var b queryBuilder
+	b.and("a.project_id = " + b.arg(projectID))
+
+	return r.activities(ctx, "", &b, pr)
+}
+
+// UserActivity returns the feed of all projects the user is a member of, the latest first.
+func (r *ActivityRepository) UserActivity(ctx context.Context, userID int64, pr entity.PageRequest) (entity.Page[entity.Activity], error) {
+	var b queryBuilder
+	b.and("pu.user_id = " + b.arg(userID))
+
+	return r.activities(ctx, " JOIN projects_users pu ON pu.project_id = a.project_id", &b, pr)
+}
+
+func (r *ActivityRepository) activities(ctx context.Context, join string, b *queryBuilder, pr entity.PageRequest) (entity.Page[entity.Activity], error) {
+	if pr.Cursor != "" {
+		c, err := decodeCursor(pr.Cursor, "")
+		if err != nil {
+			return entity.Page[entity.Activity]{}, err
+		}
+
+		b.and("a.id < " + b.arg(c.ID))
+	}
+
+	limit := pr.Size()
+
+	q := fmt.Sprintf(`SELECT a.id, a.project_id, a.project_name, a.actor_id, a.actor_name, a.type, a.task_id, a.subject, a.created_at
+	FROM activities a%s%s ORDER BY a.id DESC LIMIT %d`, join, b.whereClause(), limit+1)
+
+	rows, err := r.db.QueryContext(ctx, q, b.args...)
+	if err != nil {
+		return entity.Page[entity.Activity]{}, err
+	}
+	defer rows.Close()
+
+	var page entity.Page[entity.Activity]
+
+	for rows.Next() {
+		var a entity.Activity
+
+		err = rows.Scan(&a.ID, &a.ProjectID, &a.ProjectName, &a.ActorID, &a.ActorName, &a.Type, &a.TaskID, &a.Subject, &a.CreatedAt)
+		if err != nil {
+			return entity.Page[entity.Activity]{}, err
+		}
+
+		page.Items = append(page.Items, a)
+	}
+
+	if err = rows.Err(); err != nil {
+		return entity.Page[entity.Activity]{}, err
+	}
+
+	if len(page.Items) > limit {
+		page.Items = page.Items[:limit]
+		page.NextCursor = encodeCursor(cursor{ID: page.Items[limit-1].ID})
+	}
+
+	return page, nil
+}
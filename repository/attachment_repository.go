@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"task-manager/entity"
+)
+
+type AttachmentRepository struct {
+	db *sql.DB
+}
+
+func NewAttachmentRepository(database *sql.DB) *AttachmentRepository {
+	return &AttachmentRepository{db: database}
+}
+
+func (r *AttachmentRepository) CreateAttachment(ctx context.Context, a entity.Attachment) (entity.Attachment, error) {
+	q := `INSERT INTO task_attachments(task_id, user_id, name, content_type, size, storage_key, created_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id`
+
+	err := r.db.QueryRowContext(ctx, q, a.TaskID, a.UserID, a.Name, a.ContentType, a.Size, a.StorageKey, a.CreatedAt).Scan(&a.ID)
+	if err != nil {
+		return entity.Attachment{}, err
+	}
+
+	return a, nil
+}
+
+func (r *AttachmentRepository) AttachmentByID(ctx context.Context, id int64) (a entity.Attachment, err error) {
+	q := "SELECT id, task_id, user_id, name, content_type, size, storage_key, created_at FROM task_attachments WHERE id = $1"
+
+	err = r.db.QueryRowContext(ctx, q, id).Scan(&a.ID, &a.TaskID, &a.UserID, &a.Name, &a.ContentType, &a.Size, &a.StorageKey, &a.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entity.Attachment{}, entity.ErrNotFound
+		}
+
+		return entity.Attachment{}, err
+	}
+
+	return a, nil
+}
+
+func (r *AttachmentRepository) TaskAttachments(ctx context.Context, taskID int64) (attachments []entity.Attachment, err error) {
+	q := "SELECT id, task_id, user_id, name, content_type, size, storage_key, created_at FROM task_attachments WHERE task_id = $1 ORDER BY id"
+
+	rows, err := r.db.QueryContext(ctx, q, taskID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var a entity.Attachment
+
+		err = rows.Scan(&a.ID, &a.TaskID, &a.UserID, &a.Name, &a.ContentType, &a.Size, &a.StorageKey, &a.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		attachments = append(attachments, a)
+	}
+
+	return attachments, rows.Err()
+}
+
+// DeleteAttachment deletes attachment metadata, its blob is queued for deletion by a trigger.
+func (r *AttachmentRepository) DeleteAttachment(ctx context.Context, id int64) error {
+	q := "DELETE FROM task_attachments WHERE id = $1"
+
+	res, err := r.db.ExecContext(ctx, q, id)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return entity.ErrNotFound
+	}
+
+	return nil
+}
+
+// BlobsToDelete returns up to limit storage keys of blobs left behind by deleted attachments.
+func (r *AttachmentRepository) BlobsToDelete(ctx context.Context, limit int) (keys []string, err error) {
+	q := "SELECT storage_key FROM blob_deletions ORDER BY created_at LIMIT $1"
+
+	rows, err := r.db.QueryContext(ctx, q, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var key string
+
+		err = rows.Scan(&key)
+		if err != nil {
+			return nil, err
+		}
+
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
+
+// MarkBlobDeleted removes the key from the deletion queue once the blob is gone from storage.
+func (r *AttachmentRepository) MarkBlobDeleted(ctx context.Context, key string) error {
+	q := "DELETE FROM blob_deletions WHERE storage_key = $1"
+
+	_, err := r.db.ExecContext(ctx, q, key)
+	return err
+}
@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"task-manager/entity"
+	"time"
+)
+
+type CommentRepository struct {
+	db *sql.DB
+}
+
+func NewCommentRepository(database *sql.DB) *CommentRepository {
+	return &CommentRepository{db: database}
+}
+
+func (r *CommentRepository) CreateComment(ctx context.Context, c entity.Comment) (entity.Comment, error) {
+	q := "INSERT INTO task_comments(task_id, user_id, body, created_at) VALUES ($1, $2, $3, $4) RETURNING id"
+
+	err := r.db.QueryRowContext(ctx, q, c.TaskID, c.UserID, c.Body, c.CreatedAt).Scan(&c.ID)
+	if err != nil {
+		return entity.Comment{}, err
+	}
+
+	return c, nil
+}
+
+func (r *CommentRepository) CommentByID(ctx context.Context, id int64) (c entity.Comment, err error) {
+	q := "SELECT id, task_id, user_id, body, created_at, updated_at FROM task_comments WHERE id = $1"
+
+	err = r.db.QueryRowContext(ctx, q, id).Scan(&c.ID, &c.TaskID, &c.UserID, &c.Body, &c.CreatedAt, &c.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entity.Comment{}, entity.ErrNotFound
+		}
+
+		return entity.Comment{}, err
+	}
+
+	return c, nil
+}
+
+// TaskComments returns comments of the task, the oldest first.
+func (r *CommentRepository) TaskComments(ctx context.Context, taskID int64, pr entity.PageRequest) (entity.Page[entity.Comment], error) {
+	var b queryBuilder
+	b.and("task_id = " + b.arg(taskID))
+
+	if pr.Cursor != "" {
+		c, err := decodeCursor(pr.Cursor, "")
+		if err != nil {
+			return entity.Page[entity.Comment]{}, err
+		}
+
+		b.and("id > " + b.arg(c.ID))
+	}
+
+	limit := pr.Size()
+
+	q := fmt.Sprintf("SELECT id, task_id, user_id, body, created_at, updated_at FROM task_comments%s ORDER BY id LIMIT %d",
+		b.whereClause(), limit+1)
+
+	rows, err := r.db.QueryContext(ctx, q, b.args...)
+	if err != nil {
+		return entity.Page[entity.Comment]{}, err
+	}
+	defer rows.Close()
+
+	var page entity.Page[entity.Comment]
+
+	for rows.Next() {
+		var c entity.Comment
+
+		err = rows.Scan(&c.ID, &c.TaskID, &c.UserID, &c.Body, &c.CreatedAt, &c.UpdatedAt)
+		if err != nil {
+			return entity.Page[entity.Comment]{}, err
+		}
+
+		page.Items = append(page.Items, c)
+	}
+
+	if err = rows.Err(); err != nil {
+		return entity.Page[entity.Comment]{}, err
+	}
+
+	if len(page.Items) > limit {
+		page.Items = page.Items[:limit]
+		page.NextCursor = encodeCursor(cursor{ID: page.Items[limit-1].ID})
+	}
+
+	return page, nil
+}
+
+func (r *CommentRepository) UpdateComment(ctx context.Context, id int64, body string, updatedAt time.Time) error {
+	q := "UPDATE task_comments SET body = $1, updated_at = $2 WHERE id = $3"
+
+	res, err := r.db.ExecContext(ctx, q, body, updatedAt, id)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return entity.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *CommentRepository) DeleteComment(ctx context.Context, id int64) error {
+	q := "DELETE FROM task_comments WHERE id = $1"
+
+	res, err := r.db.ExecContext(ctx, q, id)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return entity.ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"task-manager/entity"
+)
+
+// cursor points at the last item of a page. Value is the sort key of the item as text,
+// Sort is the sort the cursor was made for, so it can't be reused with another one.
+type cursor struct {
+	Sort  string `json:"s,omitempty"`
+	Value string `json:"v,omitempty"`
+	ID    int64  `json:"id"`
+}
+
+func encodeCursor(c cursor) string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeCursor(s string, sort string) (cursor, error) {
+	var c cursor
+
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return cursor{}, fmt.Errorf("%w: invalid cursor", entity.ErrBadRequest)
+	}
+
+	err = json.Unmarshal(b, &c)
+	if err != nil {
+		return cursor{}, fmt.Errorf("%w: invalid cursor", entity.ErrBadRequest)
+	}
+
+	if c.Sort != sort {
+		return cursor{}, fmt.Errorf("%w: cursor was made for another sort", entity.ErrBadRequest)
+	}
+
+	return c, nil
+}
@@ -0,0 +1,228 @@
+package repository
+
+import (
+	"fmt"
+	"github.com/lib/pq"
+	"math"
+	"strconv"
+	"strings"
+	"task-manager/entity"
+	"time"
+)
+
+type filterType int
+
+const (
+	filterText filterType = iota
+	filterInt
+	filterTime
+	filterNumber
+	filterDate
+)
+
+// filterOps lists operators every type of field supports.
+var filterOps = map[filterType][]string{
+	filterText:   {entity.OpEq, entity.OpNe, entity.OpContains, entity.OpIn},
+	filterInt:    {entity.OpEq, entity.OpNe, entity.OpLt, entity.OpLte, entity.OpGt, entity.OpGte, entity.OpIn},
+	filterTime:   {entity.OpLt, entity.OpLte, entity.OpGt, entity.OpGte},
+	filterNumber: {entity.OpEq, entity.OpNe, entity.OpLt, entity.OpLte, entity.OpGt, entity.OpGte, entity.OpIn},
+	filterDate:   {entity.OpEq, entity.OpNe, entity.OpLt, entity.OpLte, entity.OpGt, entity.OpGte, entity.OpIn},
+}
+
+var filterComparisons = map[string]string{
+	entity.OpEq:  "=",
+	entity.OpNe:  "<>",
+	entity.OpLt:  "<",
+	entity.OpLte: "<=",
+	entity.OpGt:  ">",
+	entity.OpGte: ">=",
+}
+
+// filterField describes how a filter field maps to SQL. The value is compared with expr, when exists is set
+// the comparison is put inside it instead, so related rows can be matched with an EXISTS subquery.
+type filterField struct {
+	expr   string
+	typ    filterType
+	exists string
+}
+
+// taskFilterFields are fields tasks can be filtered by, new task columns become filterable once added here.
+var taskFilterFields = map[string]filterField{
+	"name":        {expr: "t.name", typ: filterText},
+	"description": {expr: "t.description", typ: filterText},
+	"status":      {expr: "t.status", typ: filterText},
+	"priority":    {expr: "t.priority", typ: filterInt},
+	"creator":     {expr: "t.user_id", typ: filterInt},
+	"project":     {expr: "t.project_id", typ: filterInt},
+	"parent":      {expr: "t.parent_task_id", typ: filterInt},
+	"created_at":  {expr: "t.created_at", typ: filterTime},
+	"due_at":      {expr: "t.due_at", typ: filterTime},
+	"assignee": {
+		expr:   "ta.user_id",
+		typ:    filterInt,
+		exists: "EXISTS(SELECT 1 FROM task_assignees ta WHERE ta.task_id = t.id AND %s)",
+	},
+	"label": {
+		expr:   "tl.label_id",
+		typ:    filterInt,
+		exists: "EXISTS(SELECT 1 FROM task_labels tl WHERE tl.task_id = t.id AND %s)",
+	},
+}
+
+// customFieldPrefix starts names of custom field filters and sorts, followed by the field id.
+const customFieldPrefix = "custom."
+
+func customFieldID(name string) (int64, bool) {
+	id, err := strconv.ParseInt(strings.TrimPrefix(name, customFieldPrefix), 10, 64)
+	return id, err == nil && strings.HasPrefix(name, customFieldPrefix)
+}
+
+// customFilterField maps a custom field of type typ to its value. Scalar values are read with a subquery,
+// so a task without the value compares as NULL; multi select fields match tasks having any of the options.
+func customFilterField(id int64, typ string) filterField {
+	value := fmt.Sprintf("(SELECT fv.value FROM task_field_values fv WHERE fv.task_id = t.id AND fv.field_id = %d)", id)
+
+	switch typ {
+	case entity.CustomFieldNumber:
+		return filterField{expr: "(" + value + ")::numeric", typ: filterNumber}
+	case entity.CustomFieldDate:
+		return filterField{expr: "(" + value + " #>> '{}')::date", typ: filterDate}
+	case entity.CustomFieldUser:
+		return filterField{expr: "(" + value + ")::bigint", typ: filterInt}
+	case entity.CustomFieldMultiSelect:
+		return filterField{
+			expr: "fo.opt",
+			typ:  filterText,
+			exists: fmt.Sprintf("EXISTS(SELECT 1 FROM task_field_values fv, jsonb_array_elements_text(fv.value) fo(opt) "+
+				"WHERE fv.task_id = t.id AND fv.field_id = %d AND %%s)", id),
+		}
+	default:
+		return filterField{expr: "(" + value + " #>> '{}')", typ: filterText}
+	}
+}
+
+func filterNames(filters []entity.Filter) []string {
+	names := make([]string, len(filters))
+	for i, f := range filters {
+		names[i] = f.Field
+	}
+
+	return names
+}
+
+// applyFilters adds every filter to b as an AND condition. Values are always passed as arguments,
+// unknown fields, unsupported operators and malformed values are reported as ErrBadRequest.
+func applyFilters(b *queryBuilder, fields map[string]filterField, filters []entity.Filter) error {
+	for _, f := range filters {
+		field, ok := fields[f.Field]
+		if !ok {
+			return fmt.Errorf("%w: unknown filter field %q", entity.ErrBadRequest, f.Field)
+		}
+
+		if !supportsOp(field.typ, f.Op) {
+			return fmt.Errorf("%w: filter field %q doesn't support %q", entity.ErrBadRequest, f.Field, f.Op)
+		}
+
+		cond, err := filterCondition(b, field, f)
+		if err != nil {
+			return err
+		}
+
+		b.and(cond)
+	}
+
+	return nil
+}
+
+func filterCondition(b *queryBuilder, field filterField, f entity.Filter) (string, error) {
+	var cond string
+
+	switch f.Op {
+	case entity.OpContains:
+		cond = fmt.Sprintf("strpos(lower(%s), lower(%s)) > 0", field.expr, b.arg(f.Value))
+	case entity.OpIn:
+		var values []any
+
+		for _, v := range strings.Split(f.Value, ",") {
+			value, err := parseFilterValue(field.typ, f, v)
+			if err != nil {
+				return "", err
+			}
+
+			values = append(values, value)
+		}
+
+		cond = fmt.Sprintf("%s = ANY(%s)", field.expr, b.arg(pq.Array(values)))
+	default:
+		value, err := parseFilterValue(field.typ, f, f.Value)
+		if err != nil {
+			return "", err
+		}
+
+		op := filterComparisons[f.Op]
+
+		// related rows are matched by equality, ne means none of them is equal
+		if field.exists != "" && f.Op == entity.OpNe {
+			op = "="
+		}
+
+		cond = fmt.Sprintf("%s %s %s", field.expr, op, b.arg(value))
+	}
+
+	if field.exists == "" {
+		return cond, nil
+	}
+
+	cond = fmt.Sprintf(field.exists, cond)
+
+	if f.Op == entity.OpNe {
+		cond = "NOT " + cond
+	}
+
+	return cond, nil
+}
+
+func parseFilterValue(typ filterType, f entity.Filter, v string) (any, error) {
+	switch typ {
+	case filterInt:
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: filter field %q expects a number", entity.ErrBadRequest, f.Field)
+		}
+
+		return n, nil
+	case filterTime:
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return nil, fmt.Errorf("%w: filter field %q expects RFC 3339 time", entity.ErrBadRequest, f.Field)
+		}
+
+		return t, nil
+	case filterNumber:
+		n, err := strconv.ParseFloat(v, 64)
+		if err != nil || math.IsInf(n, 0) || math.IsNaN(n) {
+			return nil, fmt.Errorf("%w: filter field %q expects a number", entity.ErrBadRequest, f.Field)
+		}
+
+		return n, nil
+	case filterDate:
+		_, err := time.Parse(entity.CustomFieldDateLayout, v)
+		if err != nil {
+			return nil, fmt.Errorf("%w: filter field %q expects a YYYY-MM-DD date", entity.ErrBadRequest, f.Field)
+		}
+
+		return v, nil
+	default:
+		return v, nil
+	}
+}
+
+func supportsOp(typ filterType, op string) bool {
+	for _, v := range filterOps[typ] {
+		if v == op {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"task-manager/entity"
+)
+
+type HistoryRepository struct {
+	db *sql.DB
+}
+
+func NewHistoryRepository(database *sql.DB) *HistoryRepository {
+	return &HistoryRepository{db: database}
+}
+
+// AddChanges appends changes to the history in one transaction.
+func (r *HistoryRepository) AddChanges(ctx context.Context, changes []entity.Change) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	q := `INSERT INTO history(project_id, task_id, actor_id, field, old_value, new_value, created_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7)`
+
+	for _, c := range changes {
+		_, err = tx.ExecContext(ctx, q, c.ProjectID, c.TaskID, c.ActorID, c.Field, string(c.OldValue), string(c.NewValue), c.CreatedAt)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// TaskHistory returns changes of the task, the latest first.
+func (r *HistoryRepository) TaskHistory(ctx context.Context, taskID int64, pr entity.PageRequest) (entity.Page[entity.Change], error) {
+	var b queryBuilder
+	b.and("task_id = " + b.arg(taskID))
+
+	return r.history(ctx, &b, pr)
+}
+
+// ProjectHistory returns changes of the project and all its tasks, deleted ones included, the latest first.
+func (r *HistoryRepository) ProjectHistory(ctx context.Context, projectID int64, pr entity.PageRequest) (entity.Page[entity.Change], error) {
+	var b queryBuilder
+	b.and("project_id = " + b.arg(projectID))
+
+	return r.history(ctx, &b, pr)
+}
+
+func (r *HistoryRepository) history(ctx context.Context, b *queryBuilder, pr entity.PageRequest) (entity.Page[entity.Change], error) {
+	if pr.Cursor != "" {
+		c, err := decodeCursor(pr.Cursor, "")
+		if err != nil {
+			return entity.Page[entity.Change]{}, err
+		}
+
+		b.and("id < " + b.arg(c.ID))
+	}
+
+	limit := pr.Size()
+
+	q := fmt.Sprintf(`SELECT id, project_id, task_id, actor_id, field, old_value, new_value, created_at
+	FROM history%s ORDER BY id DESC LIMIT %d`, b.whereClause(), limit+1)
+
+	rows, err := r.db.QueryContext(ctx, q, b.args...)
+	if err != nil {
+		return entity.Page[entity.Change]{}, err
+	}
+	defer rows.Close()
+
+	var page entity.Page[entity.Change]
+
+	for rows.Next() {
+		var c entity.Change
+
+		err = rows.Scan(&c.ID, &c.ProjectID, &c.TaskID, &c.ActorID, &c.Field, &c.OldValue, &c.NewValue, &c.CreatedAt)
+		if err != nil {
+			return entity.Page[entity.Change]{}, err
+		}
+
+		page.Items = append(page.Items, c)
+	}
+
+	if err = rows.Err(); err != nil {
+		return entity.Page[entity.Change]{}, err
+	}
+
+	if len(page.Items) > limit {
+		page.Items = page.Items[:limit]
+		page.NextCursor = encodeCursor(cursor{ID: page.Items[limit-1].ID})
+	}
+
+	return page, nil
+}
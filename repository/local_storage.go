@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"task-manager/entity"
+)
+
+// LocalStorage keeps blobs as files under a directory, spread over subdirectories by key prefix.
+type LocalStorage struct {
+	dir string
+}
+
+func NewLocalStorage(dir string) (*LocalStorage, error) {
+	err := os.MkdirAll(dir, 0o750)
+	if err != nil {
+		return nil, err
+	}
+
+	return &LocalStorage{dir: dir}, nil
+}
+
+// Put writes the blob to a temporary file first, so a failed upload never leaves a partial blob under key.
+func (s *LocalStorage) Put(_ context.Context, key string, r io.Reader) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(path), 0o750)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	_, err = io.Copy(f, r)
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	err = f.Close()
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), path)
+}
+
+func (s *LocalStorage) Get(_ context.Context, key string) (io.ReadCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, entity.ErrNotFound
+		}
+
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// Delete removes the blob, deleting a missing blob is not an error.
+func (s *LocalStorage) Delete(_ context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+func (s *LocalStorage) path(key string) (string, error) {
+	if len(key) < 3 || !filepath.IsLocal(key) || filepath.Base(key) != key {
+		return "", errors.New("invalid blob key")
+	}
+
+	return filepath.Join(s.dir, key[:2], key), nil
+}
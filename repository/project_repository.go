@@ -4,6 +4,8 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+	"github.com/lib/pq"
 	"task-manager/entity"
 )
 
@@ -18,20 +20,18 @@ func NewProjectRepository(database *sql.DB) *ProjectRepository {
 }
 
 func (r *ProjectRepository) CreateProject(ctx context.Context, project entity.Project) (entity.Project, error) {
-	q := "INSERT INTO projects(name, user_id, created_at) VALUES($1, $2, $3) RETURNING id"
-
 	tx, err := r.db.Begin()
 	if err != nil {
 		return entity.Project{}, err
 	}
 	defer tx.Rollback()
 
-	err = tx.QueryRowContext(ctx, q, project.Name, project.UserID, project.CreatedAt).Scan(&project.ID)
+	project, err = r.insertProject(ctx, tx, project)
 	if err != nil {
 		return entity.Project{}, err
 	}
 
-	err = r.addProjectMember(ctx, tx, project.ID, project.UserID)
+	err = r.saveWorkflow(ctx, tx, entity.DefaultWorkflow(project.ID))
 	if err != nil {
 		return entity.Project{}, err
 	}
@@ -39,27 +39,191 @@ func (r *ProjectRepository) CreateProject(ctx context.Context, project entity.Pr
 	return project, tx.Commit()
 }
 
-func (r *ProjectRepository) UserProjects(ctx context.Context, userID int64) (projects []entity.Project, err error) {
-	q := "SELECT p.id, p.name, p.user_id, p.created_at FROM projects p JOIN projects_users pu ON pu.project_id = p.id WHERE pu.user_id = $1"
+// CreateProjectFromTemplate creates the project with the template workflow and tasks in one transaction.
+// Tasks are returned in template order.
+func (r *ProjectRepository) CreateProjectFromTemplate(ctx context.Context, project entity.Project, tmpl entity.ProjectTemplate) (entity.Project, []entity.Task, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return entity.Project{}, nil, err
+	}
+	defer tx.Rollback()
+
+	project, err = r.insertProject(ctx, tx, project)
+	if err != nil {
+		return entity.Project{}, nil, err
+	}
 
-	rows, err := r.db.QueryContext(ctx, q, userID)
+	err = r.saveWorkflow(ctx, tx, tmpl.Workflow(project.ID))
 	if err != nil {
-		return nil, err
+		return entity.Project{}, nil, err
+	}
+
+	tasks, err := insertTemplateTasks(ctx, tx, project.ID, project.UserID, tmpl.Tasks, project.CreatedAt)
+	if err != nil {
+		return entity.Project{}, nil, err
+	}
+
+	return project, tasks, tx.Commit()
+}
+
+// insertProject inserts the project with its creator as the owner.
+func (r *ProjectRepository) insertProject(ctx context.Context, tx *sql.Tx, project entity.Project) (entity.Project, error) {
+	q := "INSERT INTO projects(name, user_id, created_at) VALUES($1, $2, $3) RETURNING id"
+
+	err := tx.QueryRowContext(ctx, q, project.Name, project.UserID, project.CreatedAt).Scan(&project.ID)
+	if err != nil {
+		return entity.Project{}, err
+	}
+
+	_, err = r.addProjectMember(ctx, tx, project.ID, project.UserID, entity.RoleOwner)
+	if err != nil {
+		return entity.Project{}, err
+	}
+
+	return project, nil
+}
+
+// CloneProject copies the source project into the new project owned by project.UserID in one transaction:
+// workflow, labels, custom fields and tasks with their hierarchy, board order, assignees, labels, dependencies,
+// custom field values and checklists. References to users who aren't members of the copy are dropped.
+// Comments, attachments, time entries, recurrences and history stay with the source.
+func (r *ProjectRepository) CloneProject(ctx context.Context, sourceID int64, project entity.Project, clone entity.ProjectClone) (entity.Project, error) {
+	// repeatable read copies a consistent snapshot of the source
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead})
+	if err != nil {
+		return entity.Project{}, err
+	}
+	defer tx.Rollback()
+
+	project, err = r.insertProject(ctx, tx, project)
+	if err != nil {
+		return entity.Project{}, err
+	}
+
+	var creator *int64
+	if clone.ResetCreators {
+		creator = &project.UserID
+	}
+
+	src, dst := sourceID, project.ID
+
+	// members of the copy, references to other users are dropped
+	members := "SELECT user_id FROM projects_users WHERE project_id = $1"
+
+	queries := []struct {
+		q    string
+		args []any
+	}{
+		{q: `INSERT INTO projects_users(project_id, user_id, role)
+		SELECT $2, user_id, CASE WHEN role = $4 THEN $5 ELSE role END FROM projects_users
+		WHERE project_id = $1 AND $3 ON CONFLICT(project_id, user_id) DO NOTHING`,
+			args: []any{src, dst, clone.IncludeMembers, entity.RoleOwner, entity.RoleAdmin}},
+		{q: "INSERT INTO project_statuses(project_id, name, position, done) SELECT $2, name, position, done FROM project_statuses WHERE project_id = $1",
+			args: []any{src, dst}},
+		{q: "INSERT INTO project_transitions(project_id, from_status, to_status) SELECT $2, from_status, to_status FROM project_transitions WHERE project_id = $1",
+			args: []any{src, dst}},
+		// new ids are taken upfront so references between copied rows can be remapped
+		{q: "CREATE TEMPORARY TABLE clone_ids(kind TEXT NOT NULL, old_id BIGINT NOT NULL, new_id BIGINT NOT NULL, PRIMARY KEY(kind, old_id)) ON COMMIT DROP"},
+		{q: "INSERT INTO clone_ids SELECT 'label', id, nextval(pg_get_serial_sequence('labels', 'id')) FROM labels WHERE project_id = $1",
+			args: []any{src}},
+		{q: "INSERT INTO clone_ids SELECT 'field', id, nextval(pg_get_serial_sequence('custom_fields', 'id')) FROM custom_fields WHERE project_id = $1",
+			args: []any{src}},
+		{q: "INSERT INTO clone_ids SELECT 'task', id, nextval(pg_get_serial_sequence('tasks', 'id')) FROM tasks WHERE project_id = $1",
+			args: []any{src}},
+		{q: `INSERT INTO labels(id, project_id, name, color)
+		SELECT m.new_id, $1, l.name, l.color FROM labels l JOIN clone_ids m ON m.kind = 'label' AND m.old_id = l.id`,
+			args: []any{dst}},
+		{q: `INSERT INTO custom_fields(id, project_id, name, type, options)
+		SELECT m.new_id, $1, f.name, f.type, f.options FROM custom_fields f JOIN clone_ids m ON m.kind = 'field' AND m.old_id = f.id`,
+			args: []any{dst}},
+		{q: `INSERT INTO tasks(id, name, project_id, parent_task_id, description, user_id, status, priority, due_at, rank, created_at)
+		SELECT m.new_id, t.name, $1, p.new_id, t.description, COALESCE($2, t.user_id), t.status, t.priority, t.due_at, t.rank, t.created_at
+		FROM tasks t JOIN clone_ids m ON m.kind = 'task' AND m.old_id = t.id
+		    LEFT JOIN clone_ids p ON p.kind = 'task' AND p.old_id = t.parent_task_id`,
+			args: []any{dst, creator}},
+		{q: `INSERT INTO task_assignees(task_id, user_id)
+		SELECT m.new_id, ta.user_id FROM task_assignees ta JOIN clone_ids m ON m.kind = 'task' AND m.old_id = ta.task_id
+		WHERE ta.user_id IN (` + members + `)`,
+			args: []any{dst}},
+		{q: `INSERT INTO task_labels(task_id, label_id)
+		SELECT m.new_id, l.new_id FROM task_labels tl
+		    JOIN clone_ids m ON m.kind = 'task' AND m.old_id = tl.task_id
+		    JOIN clone_ids l ON l.kind = 'label' AND l.old_id = tl.label_id`},
+		{q: `INSERT INTO task_dependencies(task_id, blocker_id)
+		SELECT m.new_id, b.new_id FROM task_dependencies td
+		    JOIN clone_ids m ON m.kind = 'task' AND m.old_id = td.task_id
+		    JOIN clone_ids b ON b.kind = 'task' AND b.old_id = td.blocker_id`},
+		{q: `INSERT INTO task_field_values(task_id, field_id, value)
+		SELECT m.new_id, fm.new_id, fv.value FROM task_field_values fv
+		    JOIN clone_ids m ON m.kind = 'task' AND m.old_id = fv.task_id
+		    JOIN clone_ids fm ON fm.kind = 'field' AND fm.old_id = fv.field_id
+		    JOIN custom_fields f ON f.id = fv.field_id
+		WHERE CASE WHEN f.type = $2 THEN (fv.value)::bigint IN (` + members + `) ELSE TRUE END`,
+			args: []any{dst, entity.CustomFieldUser}},
+		{q: `INSERT INTO checklist_items(task_id, text, checked, responsible_id, position, created_at)
+		SELECT m.new_id, ci.text, ci.checked, CASE WHEN ci.responsible_id IN (` + members + `) THEN ci.responsible_id END, ci.position, ci.created_at
+		FROM checklist_items ci JOIN clone_ids m ON m.kind = 'task' AND m.old_id = ci.task_id
+		ORDER BY ci.id`,
+			args: []any{dst}},
+	}
+
+	for _, query := range queries {
+		_, err = tx.ExecContext(ctx, query.q, query.args...)
+		if err != nil {
+			return entity.Project{}, err
+		}
+	}
+
+	return project, tx.Commit()
+}
+
+func (r *ProjectRepository) UserProjects(ctx context.Context, userID int64, pr entity.PageRequest) (entity.Page[entity.Project], error) {
+	var b queryBuilder
+	b.and("pu.user_id = " + b.arg(userID))
+
+	if pr.Cursor != "" {
+		c, err := decodeCursor(pr.Cursor, "")
+		if err != nil {
+			return entity.Page[entity.Project]{}, err
+		}
+
+		b.and("p.id > " + b.arg(c.ID))
+	}
+
+	limit := pr.Size()
+
+	q := fmt.Sprintf("SELECT p.id, p.name, p.user_id, p.created_at FROM projects p JOIN projects_users pu ON pu.project_id = p.id%s ORDER BY p.id LIMIT %d",
+		b.whereClause(), limit+1)
+
+	rows, err := r.db.QueryContext(ctx, q, b.args...)
+	if err != nil {
+		return entity.Page[entity.Project]{}, err
 	}
 	defer rows.Close()
 
+	var page entity.Page[entity.Project]
+
 	for rows.Next() {
 		var p entity.Project
 
 		err = rows.Scan(&p.ID, &p.Name, &p.UserID, &p.CreatedAt)
 		if err != nil {
-			return nil, err
+			return entity.Page[entity.Project]{}, err
 		}
 
-		projects = append(projects, p)
+		page.Items = append(page.Items, p)
+	}
+
+	if err = rows.Err(); err != nil {
+		return entity.Page[entity.Project]{}, err
+	}
+
+	if len(page.Items) > limit {
+		page.Items = page.Items[:limit]
+		page.NextCursor = encodeCursor(cursor{ID: page.Items[limit-1].ID})
 	}
 
-	return projects, nil
+	return page, nil
 }
 
 func (r *ProjectRepository) ProjectByID(ctx context.Context, id int64) (p entity.Project, err error) {
@@ -88,22 +252,197 @@ func (r *ProjectRepository) DeleteProject(ctx context.Context, projectID int64)
 	return nil
 }
 
-func (r *ProjectRepository) AddProjectMember(ctx context.Context, code string) error {
+// AddProjectMember adds the user invited with code to the project, added is false when they are a member already.
+func (r *ProjectRepository) AddProjectMember(ctx context.Context, code string) (m entity.Membership, added bool, err error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return entity.Membership{}, false, err
+	}
+	defer tx.Rollback()
+
+	q := "SELECT project_id, user_id, role FROM invitation_codes WHERE code = $1"
+
+	err = tx.QueryRowContext(ctx, q, code).Scan(&m.ProjectID, &m.UserID, &m.Role)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entity.Membership{}, false, entity.ErrNotFound
+		}
+
+		return entity.Membership{}, false, err
+	}
+
+	added, err = r.addProjectMember(ctx, tx, m.ProjectID, m.UserID, m.Role)
+	if err != nil {
+		return entity.Membership{}, false, err
+	}
+
+	return m, added, tx.Commit()
+}
+
+// addProjectMember reports whether the user has been added, existing members are left as they are.
+func (r *ProjectRepository) addProjectMember(ctx context.Context, tx *sql.Tx, projectID int64, userID int64, role entity.Role) (bool, error) {
+	q := "INSERT INTO projects_users(project_id, user_id, role) VALUES ($1, $2, $3) ON CONFLICT(project_id, user_id) DO NOTHING"
+
+	res, err := tx.ExecContext(ctx, q, projectID, userID, role)
+	if err != nil {
+		return false, err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return n == 1, nil
+}
+
+func (r *ProjectRepository) IsProjectMember(ctx context.Context, projectID int64, userID int64) (bool, error) {
+	q := "SELECT EXISTS(SELECT 1 FROM projects_users WHERE project_id = $1 AND user_id = $2)"
+
+	var exists bool
+
+	err := r.db.QueryRowContext(ctx, q, projectID, userID).Scan(&exists)
+	if err != nil {
+		return false, err
+	}
+
+	return exists, nil
+}
+
+// MemberRole returns role of the user in the project, ErrNotFound if the user is not a member.
+func (r *ProjectRepository) MemberRole(ctx context.Context, projectID int64, userID int64) (role entity.Role, err error) {
+	q := "SELECT role FROM projects_users WHERE project_id = $1 AND user_id = $2"
+
+	err = r.db.QueryRowContext(ctx, q, projectID, userID).Scan(&role)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", entity.ErrNotFound
+		}
+
+		return "", err
+	}
+
+	return role, nil
+}
+
+func (r *ProjectRepository) SetMemberRole(ctx context.Context, projectID int64, userID int64, role entity.Role) error {
+	q := "UPDATE projects_users SET role = $1 WHERE project_id = $2 AND user_id = $3"
+
+	res, err := r.db.ExecContext(ctx, q, role, projectID, userID)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return entity.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *ProjectRepository) SaveInvitationCode(ctx context.Context, code string, userID int64, projectID int64, role entity.Role) error {
+	q := "INSERT INTO invitation_codes(code, user_id, project_id, role) VALUES ($1, $2, $3, $4)"
+
+	_, err := r.db.ExecContext(ctx, q, code, userID, projectID, role)
+	return err
+}
+
+func (r *ProjectRepository) ProjectWorkflow(ctx context.Context, projectID int64) (w entity.Workflow, err error) {
+	w.ProjectID = projectID
+
+	q := "SELECT name, done FROM project_statuses WHERE project_id = $1 ORDER BY position"
+
+	rows, err := r.db.QueryContext(ctx, q, projectID)
+	if err != nil {
+		return entity.Workflow{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var s entity.Status
+
+		err = rows.Scan(&s.Name, &s.Done)
+		if err != nil {
+			return entity.Workflow{}, err
+		}
+
+		w.Statuses = append(w.Statuses, s)
+	}
+
+	if err = rows.Err(); err != nil {
+		return entity.Workflow{}, err
+	}
+
+	if len(w.Statuses) == 0 {
+		return entity.Workflow{}, entity.ErrNotFound
+	}
+
+	q = "SELECT from_status, to_status FROM project_transitions WHERE project_id = $1"
+
+	rows, err = r.db.QueryContext(ctx, q, projectID)
+	if err != nil {
+		return entity.Workflow{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var t entity.Transition
+
+		err = rows.Scan(&t.From, &t.To)
+		if err != nil {
+			return entity.Workflow{}, err
+		}
+
+		w.Transitions = append(w.Transitions, t)
+	}
+
+	return w, rows.Err()
+}
+
+// SaveWorkflow replaces statuses and transitions of the project. Statuses tasks still have can't be removed,
+// they are checked under the board lock, so tasks being created or moved meanwhile are taken into account.
+func (r *ProjectRepository) SaveWorkflow(ctx context.Context, w entity.Workflow) error {
 	tx, err := r.db.Begin()
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback()
 
-	q := "SELECT project_id, user_id FROM invitation_codes WHERE code = $1"
+	err = lockBoard(ctx, tx, w.ProjectID)
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, len(w.Statuses))
+	for i, s := range w.Statuses {
+		names[i] = s.Name
+	}
+
+	var status string
+
+	q := "SELECT status FROM tasks WHERE project_id = $1 AND status <> ALL($2) ORDER BY status LIMIT 1"
+
+	err = tx.QueryRowContext(ctx, q, w.ProjectID, pq.Array(names)).Scan(&status)
+	if err == nil {
+		return fmt.Errorf("%w: status %q is used by tasks", entity.ErrBadRequest, status)
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	q = "DELETE FROM project_statuses WHERE project_id = $1"
 
-	var projectID, userID int64
-	err = tx.QueryRowContext(ctx, q, code).Scan(&projectID, &userID)
+	_, err = tx.ExecContext(ctx, q, w.ProjectID)
 	if err != nil {
 		return err
 	}
 
-	err = r.addProjectMember(ctx, tx, projectID, userID)
+	err = r.saveWorkflow(ctx, tx, w)
 	if err != nil {
 		return err
 	}
@@ -111,20 +450,259 @@ func (r *ProjectRepository) AddProjectMember(ctx context.Context, code string) e
 	return tx.Commit()
 }
 
-func (r *ProjectRepository) addProjectMember(ctx context.Context, tx *sql.Tx, projectID int64, userID int64) error {
-	q := "INSERT INTO projects_users(project_id, user_id) VALUES ($1, $2) ON CONFLICT(project_id, user_id) DO NOTHING"
+func (r *ProjectRepository) saveWorkflow(ctx context.Context, tx *sql.Tx, w entity.Workflow) error {
+	q := "INSERT INTO project_statuses(project_id, name, position, done) VALUES ($1, $2, $3, $4)"
+
+	for i, s := range w.Statuses {
+		_, err := tx.ExecContext(ctx, q, w.ProjectID, s.Name, i, s.Done)
+		if err != nil {
+			return err
+		}
+	}
+
+	q = "INSERT INTO project_transitions(project_id, from_status, to_status) VALUES ($1, $2, $3) ON CONFLICT DO NOTHING"
+
+	for _, t := range w.Transitions {
+		_, err := tx.ExecContext(ctx, q, w.ProjectID, t.From, t.To)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *ProjectRepository) CreateLabel(ctx context.Context, l entity.Label) (entity.Label, error) {
+	q := "INSERT INTO labels(project_id, name, color) VALUES ($1, $2, $3) RETURNING id"
+
+	err := r.db.QueryRowContext(ctx, q, l.ProjectID, l.Name, l.Color).Scan(&l.ID)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return entity.Label{}, fmt.Errorf("%w: label %q already exists", entity.ErrBadRequest, l.Name)
+		}
+
+		return entity.Label{}, err
+	}
+
+	return l, nil
+}
+
+func (r *ProjectRepository) LabelByID(ctx context.Context, id int64) (l entity.Label, err error) {
+	q := "SELECT id, project_id, name, color FROM labels WHERE id = $1"
+
+	err = r.db.QueryRowContext(ctx, q, id).Scan(&l.ID, &l.ProjectID, &l.Name, &l.Color)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entity.Label{}, entity.ErrNotFound
+		}
+
+		return entity.Label{}, err
+	}
+
+	return l, nil
+}
+
+// ProjectLabels returns the label catalog of the project ordered by name.
+func (r *ProjectRepository) ProjectLabels(ctx context.Context, projectID int64) (labels []entity.Label, err error) {
+	q := "SELECT id, project_id, name, color FROM labels WHERE project_id = $1 ORDER BY name, id"
+
+	rows, err := r.db.QueryContext(ctx, q, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var l entity.Label
+
+		err = rows.Scan(&l.ID, &l.ProjectID, &l.Name, &l.Color)
+		if err != nil {
+			return nil, err
+		}
+
+		labels = append(labels, l)
+	}
+
+	return labels, rows.Err()
+}
+
+// UpdateLabel sets only non-nil fields of upd.
+func (r *ProjectRepository) UpdateLabel(ctx context.Context, id int64, upd entity.LabelToUpdate) error {
+	q := "UPDATE labels SET name = COALESCE($1, name), color = COALESCE($2, color) WHERE id = $3"
 
-	_, err := tx.ExecContext(ctx, q, projectID, userID)
+	res, err := r.db.ExecContext(ctx, q, upd.Name, upd.Color, id)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return fmt.Errorf("%w: label %q already exists", entity.ErrBadRequest, *upd.Name)
+		}
+
+		return err
+	}
+
+	n, err := res.RowsAffected()
 	if err != nil {
 		return err
 	}
 
+	if n == 0 {
+		return entity.ErrNotFound
+	}
+
 	return nil
 }
 
-func (r *ProjectRepository) SaveInvitationCode(ctx context.Context, code string, userID int64, projectID int64) error {
-	q := "INSERT INTO invitation_codes(code, user_id, project_id) VALUES ($1, $2, $3)"
+// DeleteLabel removes the label from the catalog, it's detached from tasks along the way.
+func (r *ProjectRepository) DeleteLabel(ctx context.Context, id int64) error {
+	q := "DELETE FROM labels WHERE id = $1"
 
-	_, err := r.db.ExecContext(ctx, q, code, userID, projectID)
-	return err
+	res, err := r.db.ExecContext(ctx, q, id)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return entity.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *ProjectRepository) CreateCustomField(ctx context.Context, f entity.CustomField) (entity.CustomField, error) {
+	q := "INSERT INTO custom_fields(project_id, name, type, options) VALUES ($1, $2, $3, $4) RETURNING id"
+
+	err := r.db.QueryRowContext(ctx, q, f.ProjectID, f.Name, f.Type, pq.Array(f.Options)).Scan(&f.ID)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return entity.CustomField{}, fmt.Errorf("%w: field %q already exists", entity.ErrBadRequest, f.Name)
+		}
+
+		return entity.CustomField{}, err
+	}
+
+	return f, nil
+}
+
+func (r *ProjectRepository) CustomFieldByID(ctx context.Context, id int64) (f entity.CustomField, err error) {
+	q := "SELECT id, project_id, name, type, options FROM custom_fields WHERE id = $1"
+
+	f, err = scanCustomField(r.db.QueryRowContext(ctx, q, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entity.CustomField{}, entity.ErrNotFound
+		}
+
+		return entity.CustomField{}, err
+	}
+
+	return f, nil
+}
+
+// ProjectCustomFields returns custom fields of the project ordered by name.
+func (r *ProjectRepository) ProjectCustomFields(ctx context.Context, projectID int64) (fields []entity.CustomField, err error) {
+	q := "SELECT id, project_id, name, type, options FROM custom_fields WHERE project_id = $1 ORDER BY name, id"
+
+	rows, err := r.db.QueryContext(ctx, q, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		f, err := scanCustomField(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		fields = append(fields, f)
+	}
+
+	return fields, rows.Err()
+}
+
+// UpdateCustomField sets only non-nil fields of upd. Task values holding options removed from
+// a select field are dropped in the same transaction.
+func (r *ProjectRepository) UpdateCustomField(ctx context.Context, id int64, upd entity.CustomFieldToUpdate) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var options any
+	if upd.Options != nil {
+		options = pq.Array(*upd.Options)
+	}
+
+	q := "UPDATE custom_fields SET name = COALESCE($1, name), options = COALESCE($2, options) WHERE id = $3 RETURNING type, options"
+
+	var (
+		typ     string
+		current []string
+	)
+
+	err = tx.QueryRowContext(ctx, q, upd.Name, options, id).Scan(&typ, pq.Array(&current))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entity.ErrNotFound
+		}
+
+		if isUniqueViolation(err) {
+			return fmt.Errorf("%w: field %q already exists", entity.ErrBadRequest, *upd.Name)
+		}
+
+		return err
+	}
+
+	var queries []string
+
+	switch typ {
+	case entity.CustomFieldSelect:
+		queries = []string{"DELETE FROM task_field_values WHERE field_id = $1 AND NOT (value #>> '{}') = ANY($2)"}
+	case entity.CustomFieldMultiSelect:
+		queries = []string{
+			"DELETE FROM task_field_values WHERE field_id = $1 AND NOT value ?| $2",
+			`UPDATE task_field_values SET value = (SELECT jsonb_agg(o) FROM jsonb_array_elements_text(value) o WHERE o = ANY($2))
+			WHERE field_id = $1`,
+		}
+	}
+
+	for _, q := range queries {
+		_, err = tx.ExecContext(ctx, q, id, pq.Array(current))
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// DeleteCustomField removes the field from the project, its values are removed from tasks along the way.
+func (r *ProjectRepository) DeleteCustomField(ctx context.Context, id int64) error {
+	q := "DELETE FROM custom_fields WHERE id = $1"
+
+	res, err := r.db.ExecContext(ctx, q, id)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return entity.ErrNotFound
+	}
+
+	return nil
+}
+
+func scanCustomField(s scanner) (f entity.CustomField, err error) {
+	err = s.Scan(&f.ID, &f.ProjectID, &f.Name, &f.Type, pq.Array(&f.Options))
+	return f, err
 }
@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"github.com/lib/pq"
+	"strings"
+)
+
+// queryBuilder collects WHERE conditions along with their positional arguments.
+type queryBuilder struct {
+	where []string
+	args  []any
+}
+
+// arg adds v to the arguments and returns its placeholder.
+func (b *queryBuilder) arg(v any) string {
+	b.args = append(b.args, v)
+	return fmt.Sprintf("$%d", len(b.args))
+}
+
+func (b *queryBuilder) and(cond string) {
+	b.where = append(b.where, cond)
+}
+
+func (b *queryBuilder) whereClause() string {
+	if len(b.where) == 0 {
+		return ""
+	}
+
+	return " WHERE " + strings.Join(b.where, " AND ")
+}
+
+// isUniqueViolation reports whether err is caused by a unique constraint.
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
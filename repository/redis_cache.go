@@ -68,8 +68,12 @@ func (r *RedisCache) UsersToSendVIP(ctx context.Context) (users []entity.User, e
 	return r.user.UsersToSendVIP(ctx)
 }
 
-func (r *RedisCache) ProjectUsers(ctx context.Context, projectID int64) (users []entity.User, err error) {
-	return r.user.ProjectUsers(ctx, projectID)
+func (r *RedisCache) ProjectUsers(ctx context.Context, projectID int64, pr entity.PageRequest) (entity.Page[entity.ProjectMember], error) {
+	return r.user.ProjectUsers(ctx, projectID, pr)
+}
+
+func (r *RedisCache) MembersByNames(ctx context.Context, projectID int64, names []string) (users []entity.User, err error) {
+	return r.user.MembersByNames(ctx, projectID, names)
 }
 
 func (r *RedisCache) MarkNotification(ctx context.Context, email string, notification string) error {
@@ -3,11 +3,16 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
+	"io"
 	"os"
+	"strconv"
+	"strings"
 	"task-manager/bootstrap"
 	"task-manager/entity"
+	"task-manager/service"
 	"testing"
 	"time"
 )
@@ -120,9 +125,9 @@ func TestRepository_CreateProject(t *testing.T) {
 	require.NoError(t, err)
 
 	// User projects
-	projects, err := repo.UserProjects(eCtx, user.ID)
+	projects, err := repo.UserProjects(eCtx, user.ID, entity.PageRequest{})
 	require.NoError(t, err)
-	require.Contains(t, projects, actualProject)
+	require.Contains(t, projects.Items, actualProject)
 
 	// Project by ID
 	expectedProject, err := repo.ProjectByID(eCtx, actualProject.ID)
@@ -150,7 +155,7 @@ func TestRepository_Projects_Error(t *testing.T) {
 
 	db.Close()
 
-	_, err = repo.UserProjects(eCtx, time.Now().UnixNano())
+	_, err = repo.UserProjects(eCtx, time.Now().UnixNano(), entity.PageRequest{})
 	require.Error(t, err)
 
 	err = repo.DeleteProject(eCtx, time.Now().UnixNano())
@@ -233,15 +238,15 @@ func TestRepository_CreateTask(t *testing.T) {
 	actualTask2, err = task.CreateTask(eCtx, actualTask2)
 	require.NoError(t, err)
 
-	actualTasks, err := task.ProjectTasks(eCtx, actualProject.ID)
+	actualTasks, err := task.ProjectTasks(eCtx, actualProject.ID, entity.TaskQuery{})
 	require.NoError(t, err)
-	require.Contains(t, actualTasks, actualTask)
-	require.NotContains(t, actualTasks, actualTask2)
+	require.Contains(t, actualTasks.Items, actualTask)
+	require.NotContains(t, actualTasks.Items, actualTask2)
 
-	actualTasks, err = task.UserTasks(eCtx, user.ID)
+	actualTasks, err = task.UserTasks(eCtx, user.ID, entity.TaskQuery{})
 	require.NoError(t, err)
-	require.Contains(t, actualTasks, actualTask)
-	require.NotContains(t, actualTasks, actualTask2)
+	require.Contains(t, actualTasks.Items, actualTask)
+	require.NotContains(t, actualTasks.Items, actualTask2)
 
 	db.Close()
 
@@ -251,13 +256,2485 @@ func TestRepository_CreateTask(t *testing.T) {
 	_, err = task.TaskByID(eCtx, time.Now().UnixNano())
 	require.Error(t, err)
 
-	_, err = task.ProjectTasks(eCtx, time.Now().UnixNano())
+	_, err = task.ProjectTasks(eCtx, time.Now().UnixNano(), entity.TaskQuery{})
 	require.Error(t, err)
 
-	_, err = task.UserTasks(eCtx, time.Now().UnixNano())
+	_, err = task.UserTasks(eCtx, time.Now().UnixNano(), entity.TaskQuery{})
 	require.Error(t, err)
 }
 
+func TestRepository_Workflow(t *testing.T) {
+	db := GetDB(t)
+
+	userRepo := NewUserRepository(db)
+	repo := NewProjectRepository(db)
+	task := NewTaskRepository(db)
+
+	user := entity.User{
+		Name:      uuid.NewString(),
+		Password:  uuid.NewString(),
+		Email:     uuid.NewString(),
+		CreatedAt: time.Now().UTC().Round(time.Millisecond),
+	}
+
+	user, err := userRepo.CreateUser(eCtx, user)
+	require.NoError(t, err)
+
+	project := entity.Project{
+		Name:      uuid.NewString(),
+		UserID:    user.ID,
+		CreatedAt: time.Now().UTC().Round(time.Millisecond),
+	}
+
+	project, err = repo.CreateProject(eCtx, project)
+	require.NoError(t, err)
+
+	// New project has default workflow
+	workflow, err := repo.ProjectWorkflow(eCtx, project.ID)
+	require.NoError(t, err)
+	require.Equal(t, entity.DefaultWorkflow(project.ID).Statuses, workflow.Statuses)
+	require.ElementsMatch(t, entity.DefaultWorkflow(project.ID).Transitions, workflow.Transitions)
+
+	// Replace workflow
+	expected := entity.Workflow{
+		ProjectID:   project.ID,
+		Statuses:    []entity.Status{{Name: "open"}, {Name: "closed", Done: true}},
+		Transitions: []entity.Transition{{From: "open", To: "closed"}},
+	}
+
+	err = repo.SaveWorkflow(eCtx, expected)
+	require.NoError(t, err)
+
+	workflow, err = repo.ProjectWorkflow(eCtx, project.ID)
+	require.NoError(t, err)
+	require.Equal(t, expected, workflow)
+
+	// Task status
+	actualTask, err := task.CreateTask(eCtx, entity.Task{
+		Name:      uuid.NewString(),
+		UserID:    user.ID,
+		ProjectID: project.ID,
+		Status:    workflow.Initial(),
+		CreatedAt: time.Now().UTC().Round(time.Millisecond),
+	})
+	require.NoError(t, err)
+
+	err = task.UpdateTaskStatus(eCtx, actualTask.ID, "closed")
+	require.NoError(t, err)
+
+	actualTask, err = task.TaskByID(eCtx, actualTask.ID)
+	require.NoError(t, err)
+	require.Equal(t, "closed", actualTask.Status)
+
+	// a task changing its status goes to the end of the new column
+	nextTask, err := task.CreateTask(eCtx, entity.Task{
+		Name:      uuid.NewString(),
+		UserID:    user.ID,
+		ProjectID: project.ID,
+		Status:    workflow.Initial(),
+		CreatedAt: time.Now().UTC().Round(time.Millisecond),
+	})
+	require.NoError(t, err)
+
+	err = task.UpdateTaskStatus(eCtx, nextTask.ID, "closed")
+	require.NoError(t, err)
+
+	nextTask, err = task.TaskByID(eCtx, nextTask.ID)
+	require.NoError(t, err)
+	require.Greater(t, nextTask.Rank, actualTask.Rank)
+
+	statuses, err := task.ProjectStatusesInUse(eCtx, project.ID)
+	require.NoError(t, err)
+	require.Equal(t, []string{"closed"}, statuses)
+
+	// a status tasks have can't be removed
+	err = repo.SaveWorkflow(eCtx, entity.Workflow{ProjectID: project.ID, Statuses: []entity.Status{{Name: "open"}}})
+	require.ErrorIs(t, err, entity.ErrBadRequest)
+
+	workflow, err = repo.ProjectWorkflow(eCtx, project.ID)
+	require.NoError(t, err)
+	require.Equal(t, expected, workflow)
+
+	err = task.UpdateTaskStatus(eCtx, time.Now().UnixNano(), "closed")
+	require.ErrorIs(t, err, entity.ErrNotFound)
+
+	_, err = repo.ProjectWorkflow(eCtx, time.Now().UnixNano())
+	require.ErrorIs(t, err, entity.ErrNotFound)
+}
+
+func TestRepository_TaskAssignees(t *testing.T) {
+	db := GetDB(t)
+
+	userRepo := NewUserRepository(db)
+	repo := NewProjectRepository(db)
+	task := NewTaskRepository(db)
+
+	user := entity.User{
+		Name:      uuid.NewString(),
+		Password:  uuid.NewString(),
+		Email:     uuid.NewString(),
+		CreatedAt: time.Now().UTC().Round(time.Millisecond),
+	}
+
+	user, err := userRepo.CreateUser(eCtx, user)
+	require.NoError(t, err)
+
+	project, err := repo.CreateProject(eCtx, entity.Project{
+		Name:      uuid.NewString(),
+		UserID:    user.ID,
+		CreatedAt: time.Now().UTC().Round(time.Millisecond),
+	})
+	require.NoError(t, err)
+
+	isMember, err := repo.IsProjectMember(eCtx, project.ID, user.ID)
+	require.NoError(t, err)
+	require.True(t, isMember)
+
+	isMember, err = repo.IsProjectMember(eCtx, project.ID, time.Now().UnixNano())
+	require.NoError(t, err)
+	require.False(t, isMember)
+
+	actualTask, err := task.CreateTask(eCtx, entity.Task{
+		Name:      uuid.NewString(),
+		UserID:    user.ID,
+		ProjectID: project.ID,
+		CreatedAt: time.Now().UTC().Round(time.Millisecond),
+	})
+	require.NoError(t, err)
+
+	// Assign twice is a no-op
+	err = task.AssignTask(eCtx, actualTask.ID, user.ID)
+	require.NoError(t, err)
+
+	err = task.AssignTask(eCtx, actualTask.ID, user.ID)
+	require.NoError(t, err)
+
+	actualTask.Assignees = []int64{user.ID}
+
+	expectedTask, err := task.TaskByID(eCtx, actualTask.ID)
+	require.NoError(t, err)
+	require.Equal(t, actualTask, expectedTask)
+
+	tasks, err := task.AssignedTasks(eCtx, user.ID, entity.TaskQuery{})
+	require.NoError(t, err)
+	require.Contains(t, tasks.Items, actualTask)
+
+	// Unassign
+	err = task.UnassignTask(eCtx, actualTask.ID, user.ID)
+	require.NoError(t, err)
+
+	err = task.UnassignTask(eCtx, actualTask.ID, user.ID)
+	require.ErrorIs(t, err, entity.ErrNotFound)
+
+	tasks, err = task.AssignedTasks(eCtx, user.ID, entity.TaskQuery{})
+	require.NoError(t, err)
+	require.Empty(t, tasks.Items)
+}
+
+func TestRepository_UpdateTask(t *testing.T) {
+	db := GetDB(t)
+
+	userRepo := NewUserRepository(db)
+	repo := NewProjectRepository(db)
+	task := NewTaskRepository(db)
+
+	user := entity.User{
+		Name:      uuid.NewString(),
+		Password:  uuid.NewString(),
+		Email:     uuid.NewString(),
+		CreatedAt: time.Now().UTC().Round(time.Millisecond),
+	}
+
+	user, err := userRepo.CreateUser(eCtx, user)
+	require.NoError(t, err)
+
+	project, err := repo.CreateProject(eCtx, entity.Project{
+		Name:      uuid.NewString(),
+		UserID:    user.ID,
+		CreatedAt: time.Now().UTC().Round(time.Millisecond),
+	})
+	require.NoError(t, err)
+
+	actualTask, err := task.CreateTask(eCtx, entity.Task{
+		Name:        uuid.NewString(),
+		UserID:      user.ID,
+		Description: uuid.NewString(),
+		ProjectID:   project.ID,
+		Status:      entity.StatusTodo,
+		CreatedAt:   time.Now().UTC().Round(time.Millisecond),
+	})
+	require.NoError(t, err)
+
+	// Only name changes
+	name := uuid.NewString()
+
+	err = task.UpdateTask(eCtx, actualTask.ID, entity.TaskToUpdate{Name: &name})
+	require.NoError(t, err)
+
+	actualTask.Name = name
+
+	expectedTask, err := task.TaskByID(eCtx, actualTask.ID)
+	require.NoError(t, err)
+	require.Equal(t, actualTask, expectedTask)
+
+	// Description and status change
+	description := uuid.NewString()
+	status := entity.StatusInProgress
+
+	err = task.UpdateTask(eCtx, actualTask.ID, entity.TaskToUpdate{Description: &description, Status: &status})
+	require.NoError(t, err)
+
+	actualTask.Description = description
+	actualTask.Status = status
+
+	// the task goes to the end of the empty in progress column
+	actualTask.Rank, err = entity.RankBetween("", "")
+	require.NoError(t, err)
+
+	expectedTask, err = task.TaskByID(eCtx, actualTask.ID)
+	require.NoError(t, err)
+	require.Equal(t, actualTask, expectedTask)
+
+	// Empty update
+	err = task.UpdateTask(eCtx, actualTask.ID, entity.TaskToUpdate{})
+	require.NoError(t, err)
+
+	err = task.UpdateTask(eCtx, time.Now().UnixNano(), entity.TaskToUpdate{Name: &name})
+	require.ErrorIs(t, err, entity.ErrNotFound)
+
+	// Delete
+	err = task.DeleteTask(eCtx, actualTask.ID, false)
+	require.NoError(t, err)
+
+	_, err = task.TaskByID(eCtx, actualTask.ID)
+	require.ErrorIs(t, err, entity.ErrNotFound)
+
+	err = task.DeleteTask(eCtx, actualTask.ID, false)
+	require.ErrorIs(t, err, entity.ErrNotFound)
+
+	db.Close()
+
+	err = task.UpdateTask(eCtx, actualTask.ID, entity.TaskToUpdate{Name: &name})
+	require.Error(t, err)
+
+	err = task.DeleteTask(eCtx, actualTask.ID, false)
+	require.Error(t, err)
+}
+
+func TestRepository_MemberRole(t *testing.T) {
+	db := GetDB(t)
+
+	userRepo := NewUserRepository(db)
+	repo := NewProjectRepository(db)
+
+	owner, err := userRepo.CreateUser(eCtx, entity.User{
+		Name:      uuid.NewString(),
+		Password:  uuid.NewString(),
+		Email:     uuid.NewString(),
+		CreatedAt: time.Now().UTC().Round(time.Millisecond),
+	})
+	require.NoError(t, err)
+
+	member, err := userRepo.CreateUser(eCtx, entity.User{
+		Name:      uuid.NewString(),
+		Password:  uuid.NewString(),
+		Email:     uuid.NewString(),
+		CreatedAt: time.Now().UTC().Round(time.Millisecond),
+	})
+	require.NoError(t, err)
+
+	project, err := repo.CreateProject(eCtx, entity.Project{
+		Name:      uuid.NewString(),
+		UserID:    owner.ID,
+		CreatedAt: time.Now().UTC().Round(time.Millisecond),
+	})
+	require.NoError(t, err)
+
+	// Creator is the owner
+	role, err := repo.MemberRole(eCtx, project.ID, owner.ID)
+	require.NoError(t, err)
+	require.Equal(t, entity.RoleOwner, role)
+
+	_, err = repo.MemberRole(eCtx, project.ID, member.ID)
+	require.ErrorIs(t, err, entity.ErrNotFound)
+
+	// Invited user gets role from invitation
+	code := uuid.NewString()
+
+	err = repo.SaveInvitationCode(eCtx, code, member.ID, project.ID, entity.RoleViewer)
+	require.NoError(t, err)
+
+	_, _, err = repo.AddProjectMember(eCtx, code)
+	require.NoError(t, err)
+
+	role, err = repo.MemberRole(eCtx, project.ID, member.ID)
+	require.NoError(t, err)
+	require.Equal(t, entity.RoleViewer, role)
+
+	// Change role
+	err = repo.SetMemberRole(eCtx, project.ID, member.ID, entity.RoleAdmin)
+	require.NoError(t, err)
+
+	owner.Password = ""
+	member.Password = ""
+
+	members, err := userRepo.ProjectUsers(eCtx, project.ID, entity.PageRequest{})
+	require.NoError(t, err)
+	require.Contains(t, members.Items, entity.ProjectMember{User: member, Role: entity.RoleAdmin})
+	require.Contains(t, members.Items, entity.ProjectMember{User: owner, Role: entity.RoleOwner})
+
+	err = repo.SetMemberRole(eCtx, project.ID, time.Now().UnixNano(), entity.RoleAdmin)
+	require.ErrorIs(t, err, entity.ErrNotFound)
+}
+
+func TestRepository_TaskDueDates(t *testing.T) {
+	db := GetDB(t)
+
+	userRepo := NewUserRepository(db)
+	repo := NewProjectRepository(db)
+	task := NewTaskRepository(db)
+
+	user, err := userRepo.CreateUser(eCtx, entity.User{
+		Name:      uuid.NewString(),
+		Password:  uuid.NewString(),
+		Email:     uuid.NewString(),
+		CreatedAt: time.Now().UTC().Round(time.Millisecond),
+	})
+	require.NoError(t, err)
+
+	project, err := repo.CreateProject(eCtx, entity.Project{
+		Name:      uuid.NewString(),
+		UserID:    user.ID,
+		CreatedAt: time.Now().UTC().Round(time.Millisecond),
+	})
+	require.NoError(t, err)
+
+	now := time.Now().UTC().Round(time.Millisecond)
+	soon := now.Add(30 * time.Minute)
+	past := now.Add(-time.Hour)
+
+	soonTask, err := task.CreateTask(eCtx, entity.Task{
+		Name:      uuid.NewString(),
+		UserID:    user.ID,
+		ProjectID: project.ID,
+		Status:    entity.StatusTodo,
+		DueAt:     &soon,
+		CreatedAt: now,
+	})
+	require.NoError(t, err)
+
+	pastTask, err := task.CreateTask(eCtx, entity.Task{
+		Name:      uuid.NewString(),
+		UserID:    user.ID,
+		ProjectID: project.ID,
+		Status:    entity.StatusTodo,
+		DueAt:     &past,
+		CreatedAt: now,
+	})
+	require.NoError(t, err)
+
+	pastTask.Overdue = true
+
+	expectedTask, err := task.TaskByID(eCtx, pastTask.ID)
+	require.NoError(t, err)
+	require.Equal(t, pastTask, expectedTask)
+
+	// Due range
+	tasks, err := task.ProjectTasks(eCtx, project.ID, entity.TaskQuery{DueFrom: &now})
+	require.NoError(t, err)
+	require.Equal(t, []entity.Task{soonTask}, tasks.Items)
+
+	tasks, err = task.UserTasks(eCtx, user.ID, entity.TaskQuery{DueTo: &now})
+	require.NoError(t, err)
+	require.Equal(t, []entity.Task{pastTask}, tasks.Items)
+
+	// Reminders
+	reminders, err := task.TasksToRemind(eCtx, entity.ReminderThreshold{Name: "1h", Before: time.Hour}, now)
+	require.NoError(t, err)
+	require.Contains(t, reminders, entity.Reminder{
+		TaskID:    soonTask.ID,
+		TaskName:  soonTask.Name,
+		DueAt:     soon,
+		Threshold: entity.ReminderThreshold{Name: "1h", Before: time.Hour},
+		Receivers: []string{user.Email},
+	})
+
+	overdue := entity.ReminderThreshold{Name: "overdue"}
+
+	reminders, err = task.TasksToRemind(eCtx, overdue, now)
+	require.NoError(t, err)
+
+	rm := entity.Reminder{TaskID: pastTask.ID, TaskName: pastTask.Name, DueAt: past, Threshold: overdue, Receivers: []string{user.Email}}
+	require.Contains(t, reminders, rm)
+
+	marked, err := task.MarkReminder(eCtx, rm, user.Email)
+	require.NoError(t, err)
+	require.True(t, marked)
+
+	marked, err = task.MarkReminder(eCtx, rm, user.Email)
+	require.NoError(t, err)
+	require.False(t, marked)
+
+	reminders, err = task.TasksToRemind(eCtx, overdue, now)
+	require.NoError(t, err)
+	require.NotContains(t, reminders, rm)
+
+	err = task.UnmarkReminder(eCtx, rm, user.Email)
+	require.NoError(t, err)
+
+	reminders, err = task.TasksToRemind(eCtx, overdue, now)
+	require.NoError(t, err)
+	require.Contains(t, reminders, rm)
+}
+
+func TestRepository_TaskSorting(t *testing.T) {
+	db := GetDB(t)
+
+	userRepo := NewUserRepository(db)
+	repo := NewProjectRepository(db)
+	task := NewTaskRepository(db)
+
+	user, err := userRepo.CreateUser(eCtx, entity.User{
+		Name:      uuid.NewString(),
+		Password:  uuid.NewString(),
+		Email:     uuid.NewString(),
+		CreatedAt: time.Now().UTC().Round(time.Millisecond),
+	})
+	require.NoError(t, err)
+
+	project, err := repo.CreateProject(eCtx, entity.Project{
+		Name:      uuid.NewString(),
+		UserID:    user.ID,
+		CreatedAt: time.Now().UTC().Round(time.Millisecond),
+	})
+	require.NoError(t, err)
+
+	now := time.Now().UTC().Round(time.Millisecond)
+	due := now.Add(time.Hour)
+
+	var created []entity.Task
+
+	for i, p := range []entity.Priority{entity.PriorityHigh, entity.PriorityLow, entity.PriorityUrgent} {
+		tsk := entity.Task{
+			Name:      string(rune('c' - i)),
+			UserID:    user.ID,
+			ProjectID: project.ID,
+			Status:    entity.StatusTodo,
+			Priority:  p,
+			CreatedAt: now.Add(time.Duration(i) * time.Second),
+		}
+
+		if i == 1 {
+			tsk.DueAt = &due
+		}
+
+		tsk, err = task.CreateTask(eCtx, tsk)
+		require.NoError(t, err)
+
+		created = append(created, tsk)
+	}
+
+	// Default is creation order
+	tasks, err := task.ProjectTasks(eCtx, project.ID, entity.TaskQuery{})
+	require.NoError(t, err)
+	require.Equal(t, created, tasks.Items)
+
+	tasks, err = task.ProjectTasks(eCtx, project.ID, entity.TaskQuery{Sort: entity.SortByPriority, Desc: true})
+	require.NoError(t, err)
+	require.Equal(t, []entity.Task{created[2], created[0], created[1]}, tasks.Items)
+
+	tasks, err = task.ProjectTasks(eCtx, project.ID, entity.TaskQuery{Sort: entity.SortByName})
+	require.NoError(t, err)
+	require.Equal(t, []entity.Task{created[2], created[1], created[0]}, tasks.Items)
+
+	// Tasks without due date go last
+	tasks, err = task.ProjectTasks(eCtx, project.ID, entity.TaskQuery{Sort: entity.SortByDueAt, Desc: true})
+	require.NoError(t, err)
+	require.Equal(t, created[1], tasks.Items[0])
+
+	_, err = task.ProjectTasks(eCtx, project.ID, entity.TaskQuery{Sort: "user_id; DROP TABLE tasks"})
+	require.ErrorIs(t, err, entity.ErrBadRequest)
+
+	// Pages follow each other in every sort
+	for _, sort := range []string{entity.SortByCreatedAt, entity.SortByPriority, entity.SortByName, entity.SortByDueAt} {
+		var paged []entity.Task
+
+		tq := entity.TaskQuery{Sort: sort, Desc: true, Page: entity.PageRequest{Limit: 2}}
+
+		for {
+			page, err := task.ProjectTasks(eCtx, project.ID, tq)
+			require.NoError(t, err)
+
+			paged = append(paged, page.Items...)
+
+			if page.NextCursor == "" {
+				break
+			}
+
+			tq.Page.Cursor = page.NextCursor
+		}
+
+		all, err := task.ProjectTasks(eCtx, project.ID, entity.TaskQuery{Sort: sort, Desc: true})
+		require.NoError(t, err)
+		require.Equal(t, all.Items, paged)
+	}
+
+	// Cursor is bound to its sort
+	page, err := task.ProjectTasks(eCtx, project.ID, entity.TaskQuery{Page: entity.PageRequest{Limit: 1}})
+	require.NoError(t, err)
+	require.Len(t, page.Items, 1)
+
+	_, err = task.ProjectTasks(eCtx, project.ID, entity.TaskQuery{Sort: entity.SortByName, Page: entity.PageRequest{Cursor: page.NextCursor}})
+	require.ErrorIs(t, err, entity.ErrBadRequest)
+
+	_, err = task.ProjectTasks(eCtx, project.ID, entity.TaskQuery{Page: entity.PageRequest{Cursor: "not a cursor"}})
+	require.ErrorIs(t, err, entity.ErrBadRequest)
+}
+
+func TestRepository_TaskFilters(t *testing.T) {
+	db := GetDB(t)
+
+	userRepo := NewUserRepository(db)
+	repo := NewProjectRepository(db)
+	task := NewTaskRepository(db)
+
+	user, err := userRepo.CreateUser(eCtx, entity.User{
+		Name:      uuid.NewString(),
+		Password:  uuid.NewString(),
+		Email:     uuid.NewString(),
+		CreatedAt: time.Now().UTC().Round(time.Millisecond),
+	})
+	require.NoError(t, err)
+
+	project, err := repo.CreateProject(eCtx, entity.Project{
+		Name:      uuid.NewString(),
+		UserID:    user.ID,
+		CreatedAt: time.Now().UTC().Round(time.Millisecond),
+	})
+	require.NoError(t, err)
+
+	now := time.Now().UTC().Round(time.Millisecond)
+
+	report, err := task.CreateTask(eCtx, entity.Task{
+		Name:      "Weekly Report",
+		UserID:    user.ID,
+		ProjectID: project.ID,
+		Status:    entity.StatusTodo,
+		Priority:  entity.PriorityHigh,
+		CreatedAt: now.Add(-time.Hour),
+	})
+	require.NoError(t, err)
+
+	invoice, err := task.CreateTask(eCtx, entity.Task{
+		Name:      "Invoice 100%",
+		UserID:    user.ID,
+		ProjectID: project.ID,
+		Status:    entity.StatusTodo,
+		Priority:  entity.PriorityLow,
+		CreatedAt: now,
+	})
+	require.NoError(t, err)
+
+	err = task.AssignTask(eCtx, invoice.ID, user.ID)
+	require.NoError(t, err)
+
+	invoice.Assignees = []int64{user.ID}
+
+	tests := []struct {
+		filters  []entity.Filter
+		expected []entity.Task
+	}{
+		{[]entity.Filter{{Field: "name", Op: entity.OpContains, Value: "report"}}, []entity.Task{report}},
+		{[]entity.Filter{{Field: "name", Op: entity.OpContains, Value: "100%"}}, []entity.Task{invoice}},
+		{[]entity.Filter{{Field: "priority", Op: entity.OpIn, Value: "3,4"}}, []entity.Task{report}},
+		{[]entity.Filter{{Field: "created_at", Op: entity.OpGte, Value: now.Format(time.RFC3339Nano)}}, []entity.Task{invoice}},
+		{[]entity.Filter{{Field: "assignee", Op: entity.OpEq, Value: strconv.FormatInt(user.ID, 10)}}, []entity.Task{invoice}},
+		{[]entity.Filter{{Field: "assignee", Op: entity.OpNe, Value: strconv.FormatInt(user.ID, 10)}}, []entity.Task{report}},
+		{[]entity.Filter{
+			{Field: "creator", Op: entity.OpEq, Value: strconv.FormatInt(user.ID, 10)},
+			{Field: "priority", Op: entity.OpLt, Value: "2"},
+		}, []entity.Task{invoice}},
+	}
+
+	for _, tt := range tests {
+		tasks, err := task.ProjectTasks(eCtx, project.ID, entity.TaskQuery{Filters: tt.filters})
+		require.NoError(t, err)
+		require.Equal(t, tt.expected, tasks.Items, tt.filters)
+	}
+
+	for _, f := range []entity.Filter{
+		{Field: "password", Op: entity.OpEq, Value: "1"},
+		{Field: "name", Op: entity.OpGt, Value: "a"},
+		{Field: "priority", Op: entity.OpEq, Value: "high"},
+		{Field: "created_at", Op: entity.OpGt, Value: "yesterday"},
+	} {
+		_, err = task.ProjectTasks(eCtx, project.ID, entity.TaskQuery{Filters: []entity.Filter{f}})
+		require.ErrorIs(t, err, entity.ErrBadRequest, f)
+	}
+}
+
+func TestRepository_Search(t *testing.T) {
+	db := GetDB(t)
+
+	userRepo := NewUserRepository(db)
+	repo := NewProjectRepository(db)
+	task := NewTaskRepository(db)
+	search := NewSearchRepository(db)
+
+	var users []entity.User
+
+	for range 2 {
+		user, err := userRepo.CreateUser(eCtx, entity.User{
+			Name:      uuid.NewString(),
+			Password:  uuid.NewString(),
+			Email:     uuid.NewString(),
+			CreatedAt: time.Now().UTC().Round(time.Millisecond),
+		})
+		require.NoError(t, err)
+
+		users = append(users, user)
+	}
+
+	// unique word keeps results of other tests away
+	word := "zq" + strings.ReplaceAll(uuid.NewString(), "-", "")
+
+	project, err := repo.CreateProject(eCtx, entity.Project{
+		Name:      "Quarterly " + word,
+		UserID:    users[0].ID,
+		CreatedAt: time.Now().UTC().Round(time.Millisecond),
+	})
+	require.NoError(t, err)
+
+	foreign, err := repo.CreateProject(eCtx, entity.Project{
+		Name:      "Foreign " + word,
+		UserID:    users[1].ID,
+		CreatedAt: time.Now().UTC().Round(time.Millisecond),
+	})
+	require.NoError(t, err)
+
+	inName, err := task.CreateTask(eCtx, entity.Task{
+		Name:        "Prepare " + word,
+		Description: "numbers for the board",
+		UserID:      users[0].ID,
+		ProjectID:   project.ID,
+		Status:      entity.StatusTodo,
+		Priority:    entity.PriorityMedium,
+		CreatedAt:   time.Now().UTC().Round(time.Millisecond),
+	})
+	require.NoError(t, err)
+
+	inDescription, err := task.CreateTask(eCtx, entity.Task{
+		Name:        "Send numbers",
+		Description: "attach the <b>" + word + "</b> to the email",
+		UserID:      users[0].ID,
+		ProjectID:   project.ID,
+		Status:      entity.StatusTodo,
+		Priority:    entity.PriorityMedium,
+		CreatedAt:   time.Now().UTC().Round(time.Millisecond),
+	})
+	require.NoError(t, err)
+
+	_, err = task.CreateTask(eCtx, entity.Task{
+		Name:        "Foreign " + word,
+		Description: word,
+		UserID:      users[1].ID,
+		ProjectID:   foreign.ID,
+		Status:      entity.StatusTodo,
+		Priority:    entity.PriorityMedium,
+		CreatedAt:   time.Now().UTC().Round(time.Millisecond),
+	})
+	require.NoError(t, err)
+
+	results, err := search.Search(eCtx, users[0].ID, word, 10)
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+
+	// name matches outrank description matches, project results carry no snippet
+	type key struct {
+		typ string
+		id  int64
+	}
+
+	found := map[key]entity.SearchResult{}
+	for _, res := range results {
+		require.NotEqual(t, foreign.ID, res.ProjectID)
+		found[key{res.Type, res.ID}] = res
+	}
+
+	require.Contains(t, found[key{entity.SearchTypeProject, project.ID}].Highlight, "<mark>"+word+"</mark>")
+	require.Empty(t, found[key{entity.SearchTypeProject, project.ID}].Snippet)
+
+	require.Contains(t, found[key{entity.SearchTypeTask, inName.ID}].Highlight, "<mark>"+word+"</mark>")
+
+	require.Contains(t, found[key{entity.SearchTypeTask, inDescription.ID}].Snippet, "<mark>"+word+"</mark>")
+	require.NotContains(t, found[key{entity.SearchTypeTask, inDescription.ID}].Highlight, "<mark>")
+
+	// user text is escaped, the only markup is the highlighting
+	require.Contains(t, found[key{entity.SearchTypeTask, inDescription.ID}].Snippet, "&lt;b&gt;")
+	require.NotContains(t, found[key{entity.SearchTypeTask, inDescription.ID}].Snippet, "<b>")
+	require.Equal(t, key{entity.SearchTypeTask, inDescription.ID}, key{results[2].Type, results[2].ID})
+
+	results, err = search.Search(eCtx, users[0].ID, word, 1)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+
+	results, err = search.Search(eCtx, users[0].ID, word+" -numbers", 10)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	require.Equal(t, key{entity.SearchTypeProject, project.ID}, key{results[0].Type, results[0].ID})
+}
+
+func TestRepository_Labels(t *testing.T) {
+	db := GetDB(t)
+
+	userRepo := NewUserRepository(db)
+	repo := NewProjectRepository(db)
+	task := NewTaskRepository(db)
+
+	user, err := userRepo.CreateUser(eCtx, entity.User{
+		Name:      uuid.NewString(),
+		Password:  uuid.NewString(),
+		Email:     uuid.NewString(),
+		CreatedAt: time.Now().UTC().Round(time.Millisecond),
+	})
+	require.NoError(t, err)
+
+	project, err := repo.CreateProject(eCtx, entity.Project{
+		Name:      uuid.NewString(),
+		UserID:    user.ID,
+		CreatedAt: time.Now().UTC().Round(time.Millisecond),
+	})
+	require.NoError(t, err)
+
+	bug, err := repo.CreateLabel(eCtx, entity.Label{ProjectID: project.ID, Name: "bug", Color: "#d73a4a"})
+	require.NoError(t, err)
+
+	_, err = repo.CreateLabel(eCtx, entity.Label{ProjectID: project.ID, Name: "bug", Color: "#000000"})
+	require.ErrorIs(t, err, entity.ErrBadRequest)
+
+	feature, err := repo.CreateLabel(eCtx, entity.Label{ProjectID: project.ID, Name: "feature", Color: entity.DefaultLabelColor})
+	require.NoError(t, err)
+
+	name := "defect"
+	err = repo.UpdateLabel(eCtx, bug.ID, entity.LabelToUpdate{Name: &name})
+	require.NoError(t, err)
+
+	bug.Name = name
+
+	labels, err := repo.ProjectLabels(eCtx, project.ID)
+	require.NoError(t, err)
+	require.Equal(t, []entity.Label{bug, feature}, labels)
+
+	labeled, err := task.CreateTask(eCtx, entity.Task{
+		Name:      uuid.NewString(),
+		UserID:    user.ID,
+		ProjectID: project.ID,
+		Status:    entity.StatusTodo,
+		Priority:  entity.PriorityMedium,
+		CreatedAt: time.Now().UTC().Round(time.Millisecond),
+	})
+	require.NoError(t, err)
+
+	plain, err := task.CreateTask(eCtx, entity.Task{
+		Name:      uuid.NewString(),
+		UserID:    user.ID,
+		ProjectID: project.ID,
+		Status:    entity.StatusTodo,
+		Priority:  entity.PriorityMedium,
+		CreatedAt: time.Now().UTC().Round(time.Millisecond),
+	})
+	require.NoError(t, err)
+
+	for _, l := range []entity.Label{feature, bug, bug} {
+		err = task.AttachLabel(eCtx, labeled.ID, l.ID)
+		require.NoError(t, err)
+	}
+
+	labeled.Labels = []entity.Label{bug, feature}
+
+	got, err := task.TaskByID(eCtx, labeled.ID)
+	require.NoError(t, err)
+	require.Equal(t, labeled, got)
+
+	byLabel := entity.TaskQuery{Filters: []entity.Filter{{Field: "label", Op: entity.OpEq, Value: strconv.FormatInt(bug.ID, 10)}}}
+
+	tasks, err := task.ProjectTasks(eCtx, project.ID, byLabel)
+	require.NoError(t, err)
+	require.Equal(t, []entity.Task{labeled}, tasks.Items)
+
+	byLabel.Filters[0].Op = entity.OpNe
+
+	tasks, err = task.ProjectTasks(eCtx, project.ID, byLabel)
+	require.NoError(t, err)
+	require.Equal(t, []entity.Task{plain}, tasks.Items)
+
+	err = task.DetachLabel(eCtx, plain.ID, bug.ID)
+	require.ErrorIs(t, err, entity.ErrNotFound)
+
+	// deleting a label detaches it from tasks
+	err = repo.DeleteLabel(eCtx, bug.ID)
+	require.NoError(t, err)
+
+	labeled.Labels = []entity.Label{feature}
+
+	got, err = task.TaskByID(eCtx, labeled.ID)
+	require.NoError(t, err)
+	require.Equal(t, labeled, got)
+
+	_, err = repo.LabelByID(eCtx, bug.ID)
+	require.ErrorIs(t, err, entity.ErrNotFound)
+
+	err = task.DetachLabel(eCtx, labeled.ID, feature.ID)
+	require.NoError(t, err)
+
+	labeled.Labels = nil
+
+	got, err = task.TaskByID(eCtx, labeled.ID)
+	require.NoError(t, err)
+	require.Equal(t, labeled, got)
+}
+
+func TestRepository_Subtasks(t *testing.T) {
+	db := GetDB(t)
+
+	userRepo := NewUserRepository(db)
+	repo := NewProjectRepository(db)
+	task := NewTaskRepository(db)
+
+	user, err := userRepo.CreateUser(eCtx, entity.User{
+		Name:      uuid.NewString(),
+		Password:  uuid.NewString(),
+		Email:     uuid.NewString(),
+		CreatedAt: time.Now().UTC().Round(time.Millisecond),
+	})
+	require.NoError(t, err)
+
+	project, err := repo.CreateProject(eCtx, entity.Project{
+		Name:      uuid.NewString(),
+		UserID:    user.ID,
+		CreatedAt: time.Now().UTC().Round(time.Millisecond),
+	})
+	require.NoError(t, err)
+
+	create := func(parentID *int64) entity.Task {
+		created, err := task.CreateTask(eCtx, entity.Task{
+			Name:      uuid.NewString(),
+			UserID:    user.ID,
+			ProjectID: project.ID,
+			ParentID:  parentID,
+			Status:    entity.StatusTodo,
+			Priority:  entity.PriorityMedium,
+			CreatedAt: time.Now().UTC().Round(time.Millisecond),
+		})
+		require.NoError(t, err)
+
+		return created
+	}
+
+	root := create(nil)
+	child := create(&root.ID)
+	grandchild := create(&child.ID)
+
+	err = task.UpdateTaskStatus(eCtx, grandchild.ID, entity.StatusDone)
+	require.NoError(t, err)
+
+	grandchild.Status = entity.StatusDone
+	grandchild.Rank, err = entity.RankBetween("", "")
+	require.NoError(t, err)
+
+	// progress rolls up subtasks at any depth
+	got, err := task.TaskByID(eCtx, root.ID)
+	require.NoError(t, err)
+	require.Equal(t, entity.Progress{Done: 1, Total: 2}, got.Progress)
+
+	child.Progress = entity.Progress{Done: 1, Total: 1}
+
+	children, err := task.ChildTasks(eCtx, root.ID, entity.TaskQuery{})
+	require.NoError(t, err)
+	require.Equal(t, []entity.Task{child}, children.Items)
+
+	descendants, err := task.TaskDescendants(eCtx, root.ID)
+	require.NoError(t, err)
+	require.Equal(t, []entity.Task{child, grandchild}, descendants)
+
+	for _, tt := range []struct {
+		ancestor, task int64
+		expected       bool
+	}{
+		{root.ID, grandchild.ID, true},
+		{child.ID, grandchild.ID, true},
+		{grandchild.ID, grandchild.ID, true},
+		{grandchild.ID, root.ID, false},
+		{child.ID, root.ID, false},
+	} {
+		is, err := task.IsAncestor(eCtx, tt.ancestor, tt.task)
+		require.NoError(t, err)
+		require.Equal(t, tt.expected, is)
+	}
+
+	// reparent hands subtasks over to the parent of the deleted task
+	err = task.DeleteTask(eCtx, child.ID, true)
+	require.NoError(t, err)
+
+	got, err = task.TaskByID(eCtx, grandchild.ID)
+	require.NoError(t, err)
+	require.Equal(t, &root.ID, got.ParentID)
+
+	moved := create(nil)
+
+	err = task.UpdateTask(eCtx, moved.ID, entity.TaskToUpdate{ParentID: entity.NewNullable(&grandchild.ID)})
+	require.NoError(t, err)
+
+	got, err = task.TaskByID(eCtx, root.ID)
+	require.NoError(t, err)
+	require.Equal(t, entity.Progress{Done: 1, Total: 2}, got.Progress)
+
+	// cascade deletes subtasks at any depth
+	err = task.DeleteTask(eCtx, root.ID, false)
+	require.NoError(t, err)
+
+	for _, id := range []int64{grandchild.ID, moved.ID} {
+		_, err = task.TaskByID(eCtx, id)
+		require.ErrorIs(t, err, entity.ErrNotFound)
+	}
+}
+
+func TestRepository_TaskDependencies(t *testing.T) {
+	db := GetDB(t)
+
+	userRepo := NewUserRepository(db)
+	repo := NewProjectRepository(db)
+	task := NewTaskRepository(db)
+
+	user, err := userRepo.CreateUser(eCtx, entity.User{
+		Name:      uuid.NewString(),
+		Password:  uuid.NewString(),
+		Email:     uuid.NewString(),
+		CreatedAt: time.Now().UTC().Round(time.Millisecond),
+	})
+	require.NoError(t, err)
+
+	var tasks []entity.Task
+
+	// a blocked by b, b blocked by c, c lives in another project
+	for range 3 {
+		project, err := repo.CreateProject(eCtx, entity.Project{
+			Name:      uuid.NewString(),
+			UserID:    user.ID,
+			CreatedAt: time.Now().UTC().Round(time.Millisecond),
+		})
+		require.NoError(t, err)
+
+		created, err := task.CreateTask(eCtx, entity.Task{
+			Name:      uuid.NewString(),
+			UserID:    user.ID,
+			ProjectID: project.ID,
+			Status:    entity.StatusTodo,
+			Priority:  entity.PriorityMedium,
+			CreatedAt: time.Now().UTC().Round(time.Millisecond),
+		})
+		require.NoError(t, err)
+
+		tasks = append(tasks, created)
+	}
+
+	a, b, c := tasks[0], tasks[1], tasks[2]
+
+	err = task.AddDependency(eCtx, a.ID, b.ID)
+	require.NoError(t, err)
+
+	err = task.AddDependency(eCtx, b.ID, c.ID)
+	require.NoError(t, err)
+
+	b.BlockedBy = []int64{c.ID}
+	b.Blocking = []int64{a.ID}
+
+	got, err := task.TaskByID(eCtx, b.ID)
+	require.NoError(t, err)
+	require.Equal(t, b, got)
+
+	for _, tt := range []struct {
+		task, blocker int64
+		expected      bool
+	}{
+		{a.ID, c.ID, true},
+		{a.ID, b.ID, true},
+		{a.ID, a.ID, true},
+		{c.ID, a.ID, false},
+		{b.ID, a.ID, false},
+	} {
+		depends, err := task.DependsOn(eCtx, tt.task, tt.blocker)
+		require.NoError(t, err)
+		require.Equal(t, tt.expected, depends)
+	}
+
+	blockers, err := task.OpenBlockers(eCtx, b.ID)
+	require.NoError(t, err)
+	require.Equal(t, []int64{c.ID}, blockers)
+
+	err = task.UpdateTaskStatus(eCtx, c.ID, entity.StatusDone)
+	require.NoError(t, err)
+
+	blockers, err = task.OpenBlockers(eCtx, b.ID)
+	require.NoError(t, err)
+	require.Empty(t, blockers)
+
+	err = task.RemoveDependency(eCtx, a.ID, b.ID)
+	require.NoError(t, err)
+
+	err = task.RemoveDependency(eCtx, a.ID, b.ID)
+	require.ErrorIs(t, err, entity.ErrNotFound)
+
+	// deleting a task drops its dependencies
+	err = task.DeleteTask(eCtx, c.ID, false)
+	require.NoError(t, err)
+
+	b.BlockedBy, b.Blocking = nil, nil
+
+	got, err = task.TaskByID(eCtx, b.ID)
+	require.NoError(t, err)
+	require.Equal(t, b, got)
+}
+
+func TestRepository_Comments(t *testing.T) {
+	db := GetDB(t)
+
+	userRepo := NewUserRepository(db)
+	repo := NewProjectRepository(db)
+	task := NewTaskRepository(db)
+	comment := NewCommentRepository(db)
+
+	var users []entity.User
+
+	for range 3 {
+		user, err := userRepo.CreateUser(eCtx, entity.User{
+			Name:      uuid.NewString(),
+			Password:  uuid.NewString(),
+			Email:     uuid.NewString(),
+			CreatedAt: time.Now().UTC().Round(time.Millisecond),
+		})
+		require.NoError(t, err)
+
+		user.Password = ""
+		users = append(users, user)
+	}
+
+	owner, member, outsider := users[0], users[1], users[2]
+
+	project, err := repo.CreateProject(eCtx, entity.Project{
+		Name:      uuid.NewString(),
+		UserID:    owner.ID,
+		CreatedAt: time.Now().UTC().Round(time.Millisecond),
+	})
+	require.NoError(t, err)
+
+	code := uuid.NewString()
+
+	err = repo.SaveInvitationCode(eCtx, code, member.ID, project.ID, entity.RoleMember)
+	require.NoError(t, err)
+
+	_, _, err = repo.AddProjectMember(eCtx, code)
+	require.NoError(t, err)
+
+	// mentions are matched ignoring case among project members only
+	mentioned, err := userRepo.MembersByNames(eCtx, project.ID, []string{strings.ToUpper(member.Name), outsider.Name})
+	require.NoError(t, err)
+	require.Equal(t, []entity.User{member}, mentioned)
+
+	created, err := task.CreateTask(eCtx, entity.Task{
+		Name:      uuid.NewString(),
+		UserID:    owner.ID,
+		ProjectID: project.ID,
+		Status:    entity.StatusTodo,
+		Priority:  entity.PriorityMedium,
+		CreatedAt: time.Now().UTC().Round(time.Millisecond),
+	})
+	require.NoError(t, err)
+
+	var comments []entity.Comment
+
+	for i := range 3 {
+		c, err := comment.CreateComment(eCtx, entity.Comment{
+			TaskID:    created.ID,
+			UserID:    owner.ID,
+			Body:      "comment " + strconv.Itoa(i),
+			CreatedAt: time.Now().UTC().Round(time.Millisecond),
+		})
+		require.NoError(t, err)
+
+		comments = append(comments, c)
+	}
+
+	updatedAt := time.Now().UTC().Round(time.Millisecond)
+
+	err = comment.UpdateComment(eCtx, comments[1].ID, "edited", updatedAt)
+	require.NoError(t, err)
+
+	comments[1].Body = "edited"
+	comments[1].UpdatedAt = &updatedAt
+
+	got, err := comment.CommentByID(eCtx, comments[1].ID)
+	require.NoError(t, err)
+	require.Equal(t, comments[1], got)
+
+	page, err := comment.TaskComments(eCtx, created.ID, entity.PageRequest{Limit: 2})
+	require.NoError(t, err)
+	require.Equal(t, comments[:2], page.Items)
+	require.NotEmpty(t, page.NextCursor)
+
+	page, err = comment.TaskComments(eCtx, created.ID, entity.PageRequest{Limit: 2, Cursor: page.NextCursor})
+	require.NoError(t, err)
+	require.Equal(t, comments[2:], page.Items)
+	require.Empty(t, page.NextCursor)
+
+	err = comment.DeleteComment(eCtx, comments[0].ID)
+	require.NoError(t, err)
+
+	_, err = comment.CommentByID(eCtx, comments[0].ID)
+	require.ErrorIs(t, err, entity.ErrNotFound)
+
+	err = comment.DeleteComment(eCtx, comments[0].ID)
+	require.ErrorIs(t, err, entity.ErrNotFound)
+
+	// comments go away with their task
+	err = task.DeleteTask(eCtx, created.ID, false)
+	require.NoError(t, err)
+
+	_, err = comment.CommentByID(eCtx, comments[1].ID)
+	require.ErrorIs(t, err, entity.ErrNotFound)
+}
+
+func TestRepository_Attachments(t *testing.T) {
+	db := GetDB(t)
+
+	userRepo := NewUserRepository(db)
+	repo := NewProjectRepository(db)
+	task := NewTaskRepository(db)
+	attachment := NewAttachmentRepository(db)
+
+	user, err := userRepo.CreateUser(eCtx, entity.User{
+		Name:      uuid.NewString(),
+		Password:  uuid.NewString(),
+		Email:     uuid.NewString(),
+		CreatedAt: time.Now().UTC().Round(time.Millisecond),
+	})
+	require.NoError(t, err)
+
+	project, err := repo.CreateProject(eCtx, entity.Project{
+		Name:      uuid.NewString(),
+		UserID:    user.ID,
+		CreatedAt: time.Now().UTC().Round(time.Millisecond),
+	})
+	require.NoError(t, err)
+
+	created, err := task.CreateTask(eCtx, entity.Task{
+		Name:      uuid.NewString(),
+		UserID:    user.ID,
+		ProjectID: project.ID,
+		Status:    entity.StatusTodo,
+		Priority:  entity.PriorityMedium,
+		CreatedAt: time.Now().UTC().Round(time.Millisecond),
+	})
+	require.NoError(t, err)
+
+	var attachments []entity.Attachment
+
+	for range 2 {
+		a, err := attachment.CreateAttachment(eCtx, entity.Attachment{
+			TaskID:      created.ID,
+			UserID:      user.ID,
+			Name:        "screenshot.png",
+			ContentType: "image/png",
+			Size:        1024,
+			StorageKey:  uuid.NewString(),
+			CreatedAt:   time.Now().UTC().Round(time.Millisecond),
+		})
+		require.NoError(t, err)
+
+		attachments = append(attachments, a)
+	}
+
+	got, err := attachment.AttachmentByID(eCtx, attachments[0].ID)
+	require.NoError(t, err)
+	require.Equal(t, attachments[0], got)
+
+	list, err := attachment.TaskAttachments(eCtx, created.ID)
+	require.NoError(t, err)
+	require.Equal(t, attachments, list)
+
+	queued := func() []string {
+		keys, err := attachment.BlobsToDelete(eCtx, 1000000)
+		require.NoError(t, err)
+
+		return keys
+	}
+
+	// deleted attachments leave their blobs queued for deletion, directly or with the task
+	err = attachment.DeleteAttachment(eCtx, attachments[0].ID)
+	require.NoError(t, err)
+
+	require.Contains(t, queued(), attachments[0].StorageKey)
+	require.NotContains(t, queued(), attachments[1].StorageKey)
+
+	err = task.DeleteTask(eCtx, created.ID, false)
+	require.NoError(t, err)
+
+	require.Contains(t, queued(), attachments[1].StorageKey)
+
+	for _, a := range attachments {
+		err = attachment.MarkBlobDeleted(eCtx, a.StorageKey)
+		require.NoError(t, err)
+
+		require.NotContains(t, queued(), a.StorageKey)
+	}
+
+	err = attachment.DeleteAttachment(eCtx, attachments[0].ID)
+	require.ErrorIs(t, err, entity.ErrNotFound)
+}
+
+func TestRepository_LocalStorage(t *testing.T) {
+	storage, err := NewLocalStorage(t.TempDir())
+	require.NoError(t, err)
+
+	key := uuid.NewString()
+
+	err = storage.Put(eCtx, key, strings.NewReader("content"))
+	require.NoError(t, err)
+
+	r, err := storage.Get(eCtx, key)
+	require.NoError(t, err)
+
+	b, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.NoError(t, r.Close())
+	require.Equal(t, "content", string(b))
+
+	err = storage.Delete(eCtx, key)
+	require.NoError(t, err)
+
+	_, err = storage.Get(eCtx, key)
+	require.ErrorIs(t, err, entity.ErrNotFound)
+
+	// deleting a missing blob is fine, keys can't escape the directory
+	err = storage.Delete(eCtx, key)
+	require.NoError(t, err)
+
+	err = storage.Put(eCtx, "../"+key, strings.NewReader("content"))
+	require.Error(t, err)
+}
+
+func TestRepository_History(t *testing.T) {
+	db := GetDB(t)
+
+	userRepo := NewUserRepository(db)
+	repo := NewProjectRepository(db)
+	task := NewTaskRepository(db)
+	history := NewHistoryRepository(db)
+
+	user, err := userRepo.CreateUser(eCtx, entity.User{
+		Name:      uuid.NewString(),
+		Password:  uuid.NewString(),
+		Email:     uuid.NewString(),
+		CreatedAt: time.Now().UTC().Round(time.Millisecond),
+	})
+	require.NoError(t, err)
+
+	project, err := repo.CreateProject(eCtx, entity.Project{
+		Name:      uuid.NewString(),
+		UserID:    user.ID,
+		CreatedAt: time.Now().UTC().Round(time.Millisecond),
+	})
+	require.NoError(t, err)
+
+	created, err := task.CreateTask(eCtx, entity.Task{
+		Name:      uuid.NewString(),
+		UserID:    user.ID,
+		ProjectID: project.ID,
+		Status:    entity.StatusTodo,
+		Priority:  entity.PriorityMedium,
+		CreatedAt: time.Now().UTC().Round(time.Millisecond),
+	})
+	require.NoError(t, err)
+
+	record := func(taskID *int64, changes ...entity.Change) {
+		for i := range changes {
+			changes[i].ProjectID = project.ID
+			changes[i].TaskID = taskID
+			changes[i].ActorID = &user.ID
+			changes[i].CreatedAt = time.Now().UTC().Round(time.Millisecond)
+		}
+
+		err := history.AddChanges(eCtx, changes)
+		require.NoError(t, err)
+	}
+
+	record(nil, entity.NewChange("workflow", nil, entity.DefaultWorkflow(project.ID)))
+	record(&created.ID,
+		entity.NewChange("name", "old", "new"),
+		entity.NewChange("priority", entity.PriorityMedium, entity.PriorityHigh),
+	)
+	record(&created.ID, entity.NewChange("due_at", nil, nil))
+
+	// Task history, the latest first
+	page, err := history.TaskHistory(eCtx, created.ID, entity.PageRequest{Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, page.Items, 2)
+	require.NotEmpty(t, page.NextCursor)
+	require.Equal(t, "due_at", page.Items[0].Field)
+	require.JSONEq(t, "null", string(page.Items[0].OldValue))
+	require.Equal(t, "priority", page.Items[1].Field)
+	require.JSONEq(t, "2", string(page.Items[1].OldValue))
+	require.JSONEq(t, "3", string(page.Items[1].NewValue))
+	require.Equal(t, user.ID, *page.Items[1].ActorID)
+
+	page, err = history.TaskHistory(eCtx, created.ID, entity.PageRequest{Limit: 2, Cursor: page.NextCursor})
+	require.NoError(t, err)
+	require.Len(t, page.Items, 1)
+	require.Empty(t, page.NextCursor)
+	require.Equal(t, "name", page.Items[0].Field)
+	require.JSONEq(t, `"old"`, string(page.Items[0].OldValue))
+
+	// Project history keeps changes of deleted tasks
+	err = task.DeleteTask(eCtx, created.ID, false)
+	require.NoError(t, err)
+
+	page, err = history.ProjectHistory(eCtx, project.ID, entity.PageRequest{})
+	require.NoError(t, err)
+	require.Len(t, page.Items, 4)
+	require.Equal(t, "workflow", page.Items[3].Field)
+	require.Nil(t, page.Items[3].TaskID)
+	require.Equal(t, created.ID, *page.Items[0].TaskID)
+
+	// Accepting an invitation tells whether the member has been added
+	member, err := userRepo.CreateUser(eCtx, entity.User{
+		Name:      uuid.NewString(),
+		Password:  uuid.NewString(),
+		Email:     uuid.NewString(),
+		CreatedAt: time.Now().UTC().Round(time.Millisecond),
+	})
+	require.NoError(t, err)
+
+	code := uuid.NewString()
+
+	err = repo.SaveInvitationCode(eCtx, code, member.ID, project.ID, entity.RoleMember)
+	require.NoError(t, err)
+
+	m, added, err := repo.AddProjectMember(eCtx, code)
+	require.NoError(t, err)
+	require.True(t, added)
+	require.Equal(t, entity.Membership{ProjectID: project.ID, UserID: member.ID, Role: entity.RoleMember}, m)
+}
+
+func TestRepository_Activity(t *testing.T) {
+	db := GetDB(t)
+
+	userRepo := NewUserRepository(db)
+	repo := NewProjectRepository(db)
+	activity := NewActivityRepository(db)
+
+	user, err := userRepo.CreateUser(eCtx, entity.User{
+		Name:      uuid.NewString(),
+		Password:  uuid.NewString(),
+		Email:     uuid.NewString(),
+		CreatedAt: time.Now().UTC().Round(time.Millisecond),
+	})
+	require.NoError(t, err)
+
+	var projects []entity.Project
+
+	for range 2 {
+		project, err := repo.CreateProject(eCtx, entity.Project{
+			Name:      uuid.NewString(),
+			UserID:    user.ID,
+			CreatedAt: time.Now().UTC().Round(time.Millisecond),
+		})
+		require.NoError(t, err)
+
+		err = activity.AddActivity(eCtx, entity.Activity{
+			ProjectID: project.ID,
+			ActorID:   &user.ID,
+			Type:      entity.ActivityProjectCreated,
+			CreatedAt: time.Now().UTC().Round(time.Millisecond),
+		})
+		require.NoError(t, err)
+
+		projects = append(projects, project)
+	}
+
+	taskID := int64(42)
+
+	err = activity.AddActivity(eCtx, entity.Activity{
+		ProjectID: projects[0].ID,
+		ActorID:   &user.ID,
+		Type:      entity.ActivityTaskCreated,
+		TaskID:    &taskID,
+		Subject:   "write docs",
+		CreatedAt: time.Now().UTC().Round(time.Millisecond),
+	})
+	require.NoError(t, err)
+
+	// Names are copied into the feed
+	page, err := activity.ProjectActivity(eCtx, projects[0].ID, entity.PageRequest{})
+	require.NoError(t, err)
+	require.Len(t, page.Items, 2)
+	require.Equal(t, entity.ActivityTaskCreated, page.Items[0].Type)
+	require.Equal(t, "write docs", page.Items[0].Subject)
+	require.Equal(t, taskID, *page.Items[0].TaskID)
+	require.Equal(t, projects[0].Name, page.Items[0].ProjectName)
+	require.Equal(t, user.Name, page.Items[0].ActorName)
+	require.Equal(t, entity.ActivityProjectCreated, page.Items[1].Type)
+	require.Nil(t, page.Items[1].TaskID)
+
+	// User feed covers all projects of the user
+	page, err = activity.UserActivity(eCtx, user.ID, entity.PageRequest{Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, page.Items, 2)
+	require.NotEmpty(t, page.NextCursor)
+
+	page, err = activity.UserActivity(eCtx, user.ID, entity.PageRequest{Limit: 2, Cursor: page.NextCursor})
+	require.NoError(t, err)
+	require.Len(t, page.Items, 1)
+	require.Empty(t, page.NextCursor)
+	require.Equal(t, projects[0].ID, page.Items[0].ProjectID)
+
+	// Activity of an unknown project
+	err = activity.AddActivity(eCtx, entity.Activity{
+		ProjectID: -1,
+		ActorID:   &user.ID,
+		Type:      entity.ActivityProjectCreated,
+		CreatedAt: time.Now().UTC().Round(time.Millisecond),
+	})
+	require.ErrorIs(t, err, entity.ErrNotFound)
+}
+
+func TestRepository_TaskRanks(t *testing.T) {
+	db := GetDB(t)
+
+	userRepo := NewUserRepository(db)
+	repo := NewProjectRepository(db)
+	task := NewTaskRepository(db)
+
+	user, err := userRepo.CreateUser(eCtx, entity.User{
+		Name:      uuid.NewString(),
+		Password:  uuid.NewString(),
+		Email:     uuid.NewString(),
+		CreatedAt: time.Now().UTC().Round(time.Millisecond),
+	})
+	require.NoError(t, err)
+
+	project, err := repo.CreateProject(eCtx, entity.Project{
+		Name:      uuid.NewString(),
+		UserID:    user.ID,
+		CreatedAt: time.Now().UTC().Round(time.Millisecond),
+	})
+	require.NoError(t, err)
+
+	var created []entity.Task
+
+	for range 3 {
+		tsk, err := task.CreateTask(eCtx, entity.Task{
+			Name:      uuid.NewString(),
+			UserID:    user.ID,
+			ProjectID: project.ID,
+			Status:    entity.StatusTodo,
+			Priority:  entity.PriorityMedium,
+			CreatedAt: time.Now().UTC().Round(time.Millisecond),
+		})
+		require.NoError(t, err)
+
+		created = append(created, tsk)
+	}
+
+	board := func(status string) []int64 {
+		page, err := task.ProjectTasks(eCtx, project.ID, entity.TaskQuery{
+			Filters: []entity.Filter{{Field: "status", Op: entity.OpEq, Value: status}},
+			Sort:    entity.SortByRank,
+		})
+		require.NoError(t, err)
+
+		var ids []int64
+		for _, v := range page.Items {
+			ids = append(ids, v.ID)
+		}
+
+		return ids
+	}
+
+	// New tasks go to the end of the column
+	require.Equal(t, []int64{created[0].ID, created[1].ID, created[2].ID}, board(entity.StatusTodo))
+
+	// Move within the column
+	_, err = task.MoveTask(eCtx, created[2], entity.TaskMove{Status: entity.StatusTodo, AfterID: &created[0].ID})
+	require.NoError(t, err)
+	require.Equal(t, []int64{created[0].ID, created[2].ID, created[1].ID}, board(entity.StatusTodo))
+
+	_, err = task.MoveTask(eCtx, created[1], entity.TaskMove{Status: entity.StatusTodo, BeforeID: &created[0].ID})
+	require.NoError(t, err)
+	require.Equal(t, []int64{created[1].ID, created[0].ID, created[2].ID}, board(entity.StatusTodo))
+
+	// Move to another column changes status
+	rank, err := task.MoveTask(eCtx, created[0], entity.TaskMove{Status: entity.StatusInProgress})
+	require.NoError(t, err)
+
+	moved, err := task.TaskByID(eCtx, created[0].ID)
+	require.NoError(t, err)
+	require.Equal(t, entity.StatusInProgress, moved.Status)
+	require.Equal(t, rank, moved.Rank)
+	require.Equal(t, []int64{created[1].ID, created[2].ID}, board(entity.StatusTodo))
+
+	// Neighbours must be in the target column
+	_, err = task.MoveTask(eCtx, created[1], entity.TaskMove{Status: entity.StatusInProgress, AfterID: &created[2].ID})
+	require.ErrorIs(t, err, entity.ErrBadRequest)
+
+	_, err = task.MoveTask(eCtx, created[2], entity.TaskMove{Status: entity.StatusTodo, AfterID: &created[1].ID, BeforeID: &created[1].ID})
+	require.ErrorIs(t, err, entity.ErrBadRequest)
+}
+
+func TestRepository_Recurrences(t *testing.T) {
+	db := GetDB(t)
+
+	userRepo := NewUserRepository(db)
+	repo := NewProjectRepository(db)
+	task := NewTaskRepository(db)
+
+	user, err := userRepo.CreateUser(eCtx, entity.User{
+		Name:      uuid.NewString(),
+		Password:  uuid.NewString(),
+		Email:     uuid.NewString(),
+		CreatedAt: time.Now().UTC().Round(time.Millisecond),
+	})
+	require.NoError(t, err)
+
+	project, err := repo.CreateProject(eCtx, entity.Project{
+		Name:      uuid.NewString(),
+		UserID:    user.ID,
+		CreatedAt: time.Now().UTC().Round(time.Millisecond),
+	})
+	require.NoError(t, err)
+
+	label, err := repo.CreateLabel(eCtx, entity.Label{ProjectID: project.ID, Name: "report", Color: entity.DefaultLabelColor})
+	require.NoError(t, err)
+
+	now := time.Now().UTC().Round(time.Millisecond)
+
+	template, err := task.CreateTask(eCtx, entity.Task{
+		Name:      uuid.NewString(),
+		UserID:    user.ID,
+		ProjectID: project.ID,
+		Status:    entity.StatusTodo,
+		Priority:  entity.PriorityMedium,
+		CreatedAt: now,
+	})
+	require.NoError(t, err)
+
+	err = task.AssignTask(eCtx, template.ID, user.ID)
+	require.NoError(t, err)
+
+	err = task.AttachLabel(eCtx, template.ID, label.ID)
+	require.NoError(t, err)
+
+	template, err = task.TaskByID(eCtx, template.ID)
+	require.NoError(t, err)
+
+	_, err = task.Recurrence(eCtx, template.ID)
+	require.ErrorIs(t, err, entity.ErrNotFound)
+
+	// Next occurrence in the future, the template is not done
+	nextAt := now.Add(time.Hour)
+	rec := entity.Recurrence{TaskID: template.ID, Rule: "FREQ=DAILY", StartsAt: now, NextAt: &nextAt, Occurrences: 1, LastTaskID: &template.ID}
+
+	err = task.SetRecurrence(eCtx, rec)
+	require.NoError(t, err)
+
+	due := func() []entity.Recurrence {
+		recs, err := task.DueRecurrences(eCtx, now)
+		require.NoError(t, err)
+
+		var own []entity.Recurrence
+		for _, v := range recs {
+			if v.TaskID == template.ID {
+				own = append(own, v)
+			}
+		}
+
+		return own
+	}
+
+	require.Empty(t, due())
+
+	// Completing the latest occurrence makes the next one due
+	err = task.UpdateTaskStatus(eCtx, template.ID, entity.StatusDone)
+	require.NoError(t, err)
+
+	recs := due()
+	require.Len(t, recs, 1)
+	require.Equal(t, rec.Rule, recs[0].Rule)
+	require.Equal(t, 1, recs[0].Occurrences)
+
+	rec = recs[0]
+	followingAt := nextAt.AddDate(0, 0, 1)
+
+	occurrence := template
+	occurrence.Status = entity.StatusTodo
+	occurrence.DueAt = rec.NextAt
+
+	created, ok, err := task.CreateOccurrence(eCtx, rec, occurrence, &followingAt, 2)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.NotEqual(t, template.ID, created.ID)
+
+	got, err := task.TaskByID(eCtx, created.ID)
+	require.NoError(t, err)
+	require.Equal(t, template.Name, got.Name)
+	require.Equal(t, []int64{user.ID}, got.Assignees)
+	require.Equal(t, []entity.Label{label}, got.Labels)
+	require.True(t, nextAt.Equal(*got.DueAt))
+
+	rec2, err := task.Recurrence(eCtx, template.ID)
+	require.NoError(t, err)
+	require.Equal(t, 2, rec2.Occurrences)
+	require.Equal(t, created.ID, *rec2.LastTaskID)
+	require.True(t, followingAt.Equal(*rec2.NextAt))
+	require.Empty(t, due())
+
+	// Stale recurrence doesn't create anything
+	_, ok, err = task.CreateOccurrence(eCtx, rec, occurrence, &followingAt, 2)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	err = task.DeleteRecurrence(eCtx, template.ID)
+	require.NoError(t, err)
+
+	err = task.DeleteRecurrence(eCtx, template.ID)
+	require.ErrorIs(t, err, entity.ErrNotFound)
+}
+
+func TestRepository_TimeEntries(t *testing.T) {
+	db := GetDB(t)
+
+	userRepo := NewUserRepository(db)
+	repo := NewProjectRepository(db)
+	task := NewTaskRepository(db)
+	entries := NewTimeRepository(db)
+
+	var users []entity.User
+
+	for range 2 {
+		user, err := userRepo.CreateUser(eCtx, entity.User{
+			Name:      uuid.NewString(),
+			Password:  uuid.NewString(),
+			Email:     uuid.NewString(),
+			CreatedAt: time.Now().UTC().Round(time.Millisecond),
+		})
+		require.NoError(t, err)
+
+		users = append(users, user)
+	}
+
+	project, err := repo.CreateProject(eCtx, entity.Project{
+		Name:      uuid.NewString(),
+		UserID:    users[0].ID,
+		CreatedAt: time.Now().UTC().Round(time.Millisecond),
+	})
+	require.NoError(t, err)
+
+	var tasks []entity.Task
+
+	for range 2 {
+		created, err := task.CreateTask(eCtx, entity.Task{
+			Name:      uuid.NewString(),
+			UserID:    users[0].ID,
+			ProjectID: project.ID,
+			Status:    entity.StatusTodo,
+			Priority:  entity.PriorityMedium,
+			CreatedAt: time.Now().UTC().Round(time.Millisecond),
+		})
+		require.NoError(t, err)
+
+		tasks = append(tasks, created)
+	}
+
+	day := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	manual := func(taskID int64, userID int64, startedAt time.Time, d time.Duration) entity.TimeEntry {
+		endedAt := startedAt.Add(d)
+
+		e, err := entries.CreateTimeEntry(eCtx, entity.TimeEntry{
+			TaskID:    taskID,
+			UserID:    userID,
+			StartedAt: startedAt,
+			EndedAt:   &endedAt,
+			Note:      "work",
+			CreatedAt: time.Now().UTC().Round(time.Millisecond),
+		})
+		require.NoError(t, err)
+
+		return e
+	}
+
+	first := manual(tasks[0].ID, users[0].ID, day, time.Hour)
+	manual(tasks[0].ID, users[1].ID, day.Add(2*time.Hour), 30*time.Minute)
+	manual(tasks[1].ID, users[0].ID, day.AddDate(0, 0, 1), 15*time.Minute)
+
+	got, err := entries.TimeEntryByID(eCtx, first.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(3600), got.Duration)
+	require.Equal(t, "work", got.Note)
+
+	// One running timer per user
+	running, err := entries.StartTimer(eCtx, entity.TimeEntry{
+		TaskID:    tasks[1].ID,
+		UserID:    users[0].ID,
+		StartedAt: time.Now().Add(-time.Minute),
+		CreatedAt: time.Now(),
+	})
+	require.NoError(t, err)
+
+	_, err = entries.StartTimer(eCtx, entity.TimeEntry{
+		TaskID:    tasks[0].ID,
+		UserID:    users[0].ID,
+		StartedAt: time.Now(),
+		CreatedAt: time.Now(),
+	})
+	require.ErrorIs(t, err, entity.ErrBadRequest)
+
+	current, err := entries.RunningTimer(eCtx, users[0].ID)
+	require.NoError(t, err)
+	require.Equal(t, running.ID, current.ID)
+	require.Nil(t, current.EndedAt)
+
+	stopped, err := entries.StopTimer(eCtx, users[0].ID, running.StartedAt.Add(2*time.Minute))
+	require.NoError(t, err)
+	require.Equal(t, running.ID, stopped.ID)
+	require.Equal(t, int64(120), stopped.Duration)
+
+	_, err = entries.StopTimer(eCtx, users[0].ID, time.Now())
+	require.ErrorIs(t, err, entity.ErrNotFound)
+
+	_, err = entries.RunningTimer(eCtx, users[0].ID)
+	require.ErrorIs(t, err, entity.ErrNotFound)
+
+	// Totals by range and user
+	from, to := day, day.AddDate(0, 0, 1)
+
+	total, err := entries.TaskTimeTotal(eCtx, tasks[0].ID, entity.TimeQuery{})
+	require.NoError(t, err)
+	require.Equal(t, entity.TimeTotal{Seconds: 5400, Entries: 2}, total)
+
+	total, err = entries.TaskTimeTotal(eCtx, tasks[0].ID, entity.TimeQuery{UserID: &users[1].ID})
+	require.NoError(t, err)
+	require.Equal(t, entity.TimeTotal{Seconds: 1800, Entries: 1}, total)
+
+	total, err = entries.ProjectTimeTotal(eCtx, project.ID, entity.TimeQuery{From: &from, To: &to})
+	require.NoError(t, err)
+	require.Equal(t, entity.TimeTotal{Seconds: 5400, Entries: 2}, total)
+
+	total, err = entries.ProjectTimeTotal(eCtx, project.ID, entity.TimeQuery{})
+	require.NoError(t, err)
+	require.Equal(t, entity.TimeTotal{Seconds: 5400 + 900 + 120, Entries: 4}, total)
+
+	total, err = entries.UserTimeTotal(eCtx, users[0].ID, entity.TimeQuery{From: &to})
+	require.NoError(t, err)
+	require.Equal(t, entity.TimeTotal{Seconds: 900 + 120, Entries: 2}, total)
+
+	// Listing and deletion
+	page, err := entries.TaskTimeEntries(eCtx, tasks[0].ID, entity.PageRequest{Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, page.Items, 1)
+	require.Equal(t, first.ID, page.Items[0].ID)
+	require.NotEmpty(t, page.NextCursor)
+
+	err = entries.DeleteTimeEntry(eCtx, first.ID)
+	require.NoError(t, err)
+
+	_, err = entries.TimeEntryByID(eCtx, first.ID)
+	require.ErrorIs(t, err, entity.ErrNotFound)
+}
+
+func TestRepository_CustomFields(t *testing.T) {
+	db := GetDB(t)
+
+	userRepo := NewUserRepository(db)
+	repo := NewProjectRepository(db)
+	task := NewTaskRepository(db)
+
+	user, err := userRepo.CreateUser(eCtx, entity.User{
+		Name:      uuid.NewString(),
+		Password:  uuid.NewString(),
+		Email:     uuid.NewString(),
+		CreatedAt: time.Now().UTC().Round(time.Millisecond),
+	})
+	require.NoError(t, err)
+
+	project, err := repo.CreateProject(eCtx, entity.Project{
+		Name:      uuid.NewString(),
+		UserID:    user.ID,
+		CreatedAt: time.Now().UTC().Round(time.Millisecond),
+	})
+	require.NoError(t, err)
+
+	estimate, err := repo.CreateCustomField(eCtx, entity.CustomField{ProjectID: project.ID, Name: "estimate", Type: entity.CustomFieldNumber, Options: []string{}})
+	require.NoError(t, err)
+
+	_, err = repo.CreateCustomField(eCtx, entity.CustomField{ProjectID: project.ID, Name: "estimate", Type: entity.CustomFieldText, Options: []string{}})
+	require.ErrorIs(t, err, entity.ErrBadRequest)
+
+	stage, err := repo.CreateCustomField(eCtx, entity.CustomField{ProjectID: project.ID, Name: "stage", Type: entity.CustomFieldSelect, Options: []string{"a", "b", "c"}})
+	require.NoError(t, err)
+
+	tags, err := repo.CreateCustomField(eCtx, entity.CustomField{ProjectID: project.ID, Name: "tags", Type: entity.CustomFieldMultiSelect, Options: []string{"x", "y", "z"}})
+	require.NoError(t, err)
+
+	fields, err := repo.ProjectCustomFields(eCtx, project.ID)
+	require.NoError(t, err)
+	require.Equal(t, []entity.CustomField{estimate, stage, tags}, fields)
+
+	newTask := func(values map[int64]json.RawMessage) entity.Task {
+		created, err := task.CreateTask(eCtx, entity.Task{
+			Name:         uuid.NewString(),
+			UserID:       user.ID,
+			ProjectID:    project.ID,
+			Status:       entity.StatusTodo,
+			Priority:     entity.PriorityMedium,
+			CustomFields: values,
+			CreatedAt:    time.Now().UTC().Round(time.Millisecond),
+		})
+		require.NoError(t, err)
+
+		return created
+	}
+
+	big := newTask(map[int64]json.RawMessage{estimate.ID: json.RawMessage(`8`), stage.ID: json.RawMessage(`"b"`), tags.ID: json.RawMessage(`["x","y"]`)})
+	small := newTask(map[int64]json.RawMessage{estimate.ID: json.RawMessage(`2.5`), stage.ID: json.RawMessage(`"a"`)})
+	none := newTask(nil)
+
+	got, err := task.TaskByID(eCtx, big.ID)
+	require.NoError(t, err)
+	require.Equal(t, big, got)
+
+	field := func(f entity.CustomField) string {
+		return "custom." + strconv.FormatInt(f.ID, 10)
+	}
+
+	tests := []struct {
+		name  string
+		query entity.TaskQuery
+		want  []entity.Task
+	}{
+		{
+			name:  "number",
+			query: entity.TaskQuery{Filters: []entity.Filter{{Field: field(estimate), Op: entity.OpGt, Value: "5"}}},
+			want:  []entity.Task{big},
+		},
+		{
+			name:  "select in",
+			query: entity.TaskQuery{Filters: []entity.Filter{{Field: field(stage), Op: entity.OpIn, Value: "a,c"}}},
+			want:  []entity.Task{small},
+		},
+		{
+			name:  "multi select",
+			query: entity.TaskQuery{Filters: []entity.Filter{{Field: field(tags), Op: entity.OpEq, Value: "y"}}},
+			want:  []entity.Task{big},
+		},
+		{
+			name:  "multi select ne",
+			query: entity.TaskQuery{Filters: []entity.Filter{{Field: field(tags), Op: entity.OpNe, Value: "y"}}},
+			want:  []entity.Task{small, none},
+		},
+		{
+			name:  "sort without values last",
+			query: entity.TaskQuery{Sort: field(estimate)},
+			want:  []entity.Task{small, big, none},
+		},
+		{
+			name:  "sort desc without values last",
+			query: entity.TaskQuery{Sort: field(estimate), Desc: true},
+			want:  []entity.Task{big, small, none},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tasks, err := task.ProjectTasks(eCtx, project.ID, tt.query)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, tasks.Items)
+		})
+	}
+
+	_, err = task.ProjectTasks(eCtx, project.ID, entity.TaskQuery{Filters: []entity.Filter{{Field: field(estimate), Op: entity.OpContains, Value: "5"}}})
+	require.ErrorIs(t, err, entity.ErrBadRequest)
+
+	_, err = task.ProjectTasks(eCtx, project.ID, entity.TaskQuery{Sort: field(tags)})
+	require.ErrorIs(t, err, entity.ErrBadRequest)
+
+	// null removes a value
+	err = task.UpdateTask(eCtx, small.ID, entity.TaskToUpdate{CustomFields: map[int64]json.RawMessage{estimate.ID: nil, stage.ID: json.RawMessage(`"c"`)}})
+	require.NoError(t, err)
+
+	small.CustomFields = map[int64]json.RawMessage{stage.ID: json.RawMessage(`"c"`)}
+
+	got, err = task.TaskByID(eCtx, small.ID)
+	require.NoError(t, err)
+	require.Equal(t, small, got)
+
+	err = task.UpdateTask(eCtx, -1, entity.TaskToUpdate{CustomFields: map[int64]json.RawMessage{stage.ID: json.RawMessage(`"a"`)}})
+	require.ErrorIs(t, err, entity.ErrNotFound)
+
+	// values of removed options are dropped
+	options := []string{"a", "b"}
+	err = repo.UpdateCustomField(eCtx, stage.ID, entity.CustomFieldToUpdate{Options: &options})
+	require.NoError(t, err)
+
+	options = []string{"y", "z"}
+	err = repo.UpdateCustomField(eCtx, tags.ID, entity.CustomFieldToUpdate{Options: &options})
+	require.NoError(t, err)
+
+	got, err = task.TaskByID(eCtx, small.ID)
+	require.NoError(t, err)
+	require.Empty(t, got.CustomFields)
+
+	got, err = task.TaskByID(eCtx, big.ID)
+	require.NoError(t, err)
+	require.Equal(t, json.RawMessage(`["y"]`), got.CustomFields[tags.ID])
+
+	err = repo.DeleteCustomField(eCtx, estimate.ID)
+	require.NoError(t, err)
+
+	got, err = task.TaskByID(eCtx, big.ID)
+	require.NoError(t, err)
+	require.NotContains(t, got.CustomFields, estimate.ID)
+
+	err = repo.DeleteCustomField(eCtx, estimate.ID)
+	require.ErrorIs(t, err, entity.ErrNotFound)
+}
+
+func TestRepository_Checklists(t *testing.T) {
+	db := GetDB(t)
+
+	userRepo := NewUserRepository(db)
+	repo := NewProjectRepository(db)
+	task := NewTaskRepository(db)
+
+	user, err := userRepo.CreateUser(eCtx, entity.User{
+		Name:      uuid.NewString(),
+		Password:  uuid.NewString(),
+		Email:     uuid.NewString(),
+		CreatedAt: time.Now().UTC().Round(time.Millisecond),
+	})
+	require.NoError(t, err)
+
+	project, err := repo.CreateProject(eCtx, entity.Project{
+		Name:      uuid.NewString(),
+		UserID:    user.ID,
+		CreatedAt: time.Now().UTC().Round(time.Millisecond),
+	})
+	require.NoError(t, err)
+
+	created, err := task.CreateTask(eCtx, entity.Task{
+		Name:      uuid.NewString(),
+		UserID:    user.ID,
+		ProjectID: project.ID,
+		Status:    entity.StatusTodo,
+		Priority:  entity.PriorityMedium,
+		CreatedAt: time.Now().UTC().Round(time.Millisecond),
+	})
+	require.NoError(t, err)
+
+	var items []entity.ChecklistItem
+
+	for i, responsible := range []*int64{&user.ID, nil, nil} {
+		item, err := task.AddChecklistItem(eCtx, entity.ChecklistItem{
+			TaskID:        created.ID,
+			Text:          uuid.NewString(),
+			ResponsibleID: responsible,
+			CreatedAt:     time.Now().UTC().Round(time.Millisecond),
+		})
+		require.NoError(t, err)
+		require.Equal(t, i, item.Position)
+
+		items = append(items, item)
+	}
+
+	got, err := task.ChecklistItems(eCtx, created.ID)
+	require.NoError(t, err)
+	require.Equal(t, items, got)
+
+	toggled, err := task.ToggleChecklistItem(eCtx, created.ID, items[1].ID)
+	require.NoError(t, err)
+	require.True(t, toggled.Checked)
+
+	_, err = task.ToggleChecklistItem(eCtx, created.ID+1, items[1].ID)
+	require.ErrorIs(t, err, entity.ErrNotFound)
+
+	loaded, err := task.TaskByID(eCtx, created.ID)
+	require.NoError(t, err)
+	require.Equal(t, entity.ChecklistProgress{Checked: 1, Total: 3, Percent: 33}, loaded.Checklist)
+
+	err = task.ReorderChecklist(eCtx, created.ID, []int64{items[2].ID, items[0].ID, items[1].ID})
+	require.NoError(t, err)
+
+	got, err = task.ChecklistItems(eCtx, created.ID)
+	require.NoError(t, err)
+	require.Equal(t, []int64{items[2].ID, items[0].ID, items[1].ID}, []int64{got[0].ID, got[1].ID, got[2].ID})
+	require.Equal(t, []int{0, 1, 2}, []int{got[0].Position, got[1].Position, got[2].Position})
+
+	err = task.DeleteChecklistItem(eCtx, created.ID, items[0].ID)
+	require.NoError(t, err)
+
+	err = task.DeleteChecklistItem(eCtx, created.ID, items[0].ID)
+	require.ErrorIs(t, err, entity.ErrNotFound)
+
+	loaded, err = task.TaskByID(eCtx, created.ID)
+	require.NoError(t, err)
+	require.Equal(t, entity.ChecklistProgress{Checked: 1, Total: 2, Percent: 50}, loaded.Checklist)
+}
+
+func TestRepository_Templates(t *testing.T) {
+	db := GetDB(t)
+
+	userRepo := NewUserRepository(db)
+	repo := NewProjectRepository(db)
+	task := NewTaskRepository(db)
+	templates := NewTemplateRepository(db)
+
+	user, err := userRepo.CreateUser(eCtx, entity.User{
+		Name:      uuid.NewString(),
+		Password:  uuid.NewString(),
+		Email:     uuid.NewString(),
+		CreatedAt: time.Now().UTC().Round(time.Millisecond),
+	})
+	require.NoError(t, err)
+
+	root := 0
+	tmpl := entity.ProjectTemplate{
+		UserID: user.ID,
+		Name:   uuid.NewString(),
+		Statuses: []entity.Status{
+			{Name: "backlog"},
+			{Name: "shipped", Done: true},
+		},
+		Transitions: []entity.Transition{{From: "backlog", To: "shipped"}},
+		Tasks: []entity.TemplateTask{
+			{Name: "setup", Description: "accounts", Priority: entity.PriorityHigh, Status: "backlog"},
+			{Name: "kick-off", Priority: entity.PriorityMedium, Status: "shipped"},
+			{Name: "invite team", Priority: entity.PriorityLow, Status: "backlog", Parent: &root},
+		},
+		CreatedAt: time.Now().UTC().Round(time.Millisecond),
+	}
+
+	require.NoError(t, tmpl.Validate())
+
+	tmpl, err = templates.CreateTemplate(eCtx, tmpl)
+	require.NoError(t, err)
+
+	got, err := templates.TemplateByID(eCtx, tmpl.ID)
+	require.NoError(t, err)
+	require.Equal(t, tmpl, got)
+
+	page, err := templates.UserTemplates(eCtx, user.ID, entity.PageRequest{})
+	require.NoError(t, err)
+	require.Equal(t, []entity.ProjectTemplate{tmpl}, page.Items)
+
+	project, tasks, err := repo.CreateProjectFromTemplate(eCtx, entity.Project{
+		Name:      uuid.NewString(),
+		UserID:    user.ID,
+		CreatedAt: time.Now().UTC().Round(time.Millisecond),
+	}, tmpl)
+	require.NoError(t, err)
+	require.Len(t, tasks, 3)
+
+	role, err := repo.MemberRole(eCtx, project.ID, user.ID)
+	require.NoError(t, err)
+	require.Equal(t, entity.RoleOwner, role)
+
+	workflow, err := repo.ProjectWorkflow(eCtx, project.ID)
+	require.NoError(t, err)
+	require.Equal(t, tmpl.Workflow(project.ID), workflow)
+
+	board, err := task.BoardTasks(eCtx, project.ID)
+	require.NoError(t, err)
+	require.Equal(t, []int64{tasks[0].ID, tasks[2].ID, tasks[1].ID}, []int64{board[0].ID, board[1].ID, board[2].ID})
+	require.Equal(t, &tasks[0].ID, board[1].ParentID)
+	require.Equal(t, "accounts", board[0].Description)
+
+	// a template saved from the project captures the same structure
+	saved := entity.NewProjectTemplate(tmpl.Name, workflow, board)
+
+	one := 0
+	require.Equal(t, []entity.TemplateTask{
+		{Name: "setup", Description: "accounts", Priority: entity.PriorityHigh, Status: "backlog"},
+		{Name: "invite team", Priority: entity.PriorityLow, Status: "backlog", Parent: &one},
+		{Name: "kick-off", Priority: entity.PriorityMedium, Status: "shipped"},
+	}, saved.Tasks)
+
+	// template tasks can be added to an existing project as well
+	added, err := templates.CreateTemplateTasks(eCtx, project.ID, user.ID, tmpl.Tasks, time.Now().UTC().Round(time.Millisecond))
+	require.NoError(t, err)
+	require.Equal(t, &added[0].ID, added[2].ParentID)
+
+	board, err = task.BoardTasks(eCtx, project.ID)
+	require.NoError(t, err)
+	require.Len(t, board, 6)
+
+	err = templates.DeleteTemplate(eCtx, tmpl.ID)
+	require.NoError(t, err)
+
+	_, err = templates.TemplateByID(eCtx, tmpl.ID)
+	require.ErrorIs(t, err, entity.ErrNotFound)
+}
+
+func TestRepository_CloneProject(t *testing.T) {
+	db := GetDB(t)
+
+	userRepo := NewUserRepository(db)
+	repo := NewProjectRepository(db)
+	task := NewTaskRepository(db)
+
+	var users []entity.User
+	for range 2 {
+		user, err := userRepo.CreateUser(eCtx, entity.User{
+			Name:      uuid.NewString(),
+			Password:  uuid.NewString(),
+			Email:     uuid.NewString(),
+			CreatedAt: time.Now().UTC().Round(time.Millisecond),
+		})
+		require.NoError(t, err)
+
+		users = append(users, user)
+	}
+
+	owner, other := users[0], users[1]
+
+	source, err := repo.CreateProject(eCtx, entity.Project{
+		Name:      uuid.NewString(),
+		UserID:    owner.ID,
+		CreatedAt: time.Now().UTC().Round(time.Millisecond),
+	})
+	require.NoError(t, err)
+
+	label, err := repo.CreateLabel(eCtx, entity.Label{ProjectID: source.ID, Name: "bug", Color: entity.DefaultLabelColor})
+	require.NoError(t, err)
+
+	reviewer, err := repo.CreateCustomField(eCtx, entity.CustomField{ProjectID: source.ID, Name: "reviewer", Type: entity.CustomFieldUser})
+	require.NoError(t, err)
+
+	parent, err := task.CreateTask(eCtx, entity.Task{
+		Name:         uuid.NewString(),
+		Description:  "parent description",
+		UserID:       owner.ID,
+		ProjectID:    source.ID,
+		Status:       entity.StatusTodo,
+		Priority:     entity.PriorityHigh,
+		CreatedAt:    time.Now().UTC().Round(time.Millisecond),
+		CustomFields: map[int64]json.RawMessage{reviewer.ID: json.RawMessage(strconv.FormatInt(owner.ID, 10))},
+	})
+	require.NoError(t, err)
+
+	child, err := task.CreateTask(eCtx, entity.Task{
+		Name:      uuid.NewString(),
+		UserID:    owner.ID,
+		ProjectID: source.ID,
+		ParentID:  &parent.ID,
+		Status:    entity.StatusTodo,
+		Priority:  entity.PriorityLow,
+		CreatedAt: time.Now().UTC().Round(time.Millisecond),
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, task.AssignTask(eCtx, child.ID, owner.ID))
+	require.NoError(t, task.AttachLabel(eCtx, child.ID, label.ID))
+	require.NoError(t, task.AddDependency(eCtx, child.ID, parent.ID))
+
+	_, err = task.AddChecklistItem(eCtx, entity.ChecklistItem{
+		TaskID:        child.ID,
+		Text:          uuid.NewString(),
+		ResponsibleID: &owner.ID,
+		CreatedAt:     time.Now().UTC().Round(time.Millisecond),
+	})
+	require.NoError(t, err)
+
+	clone := func(c entity.ProjectClone) (entity.Project, []entity.Task) {
+		t.Helper()
+
+		project, err := repo.CloneProject(eCtx, source.ID, entity.Project{
+			Name:      uuid.NewString(),
+			UserID:    other.ID,
+			CreatedAt: time.Now().UTC().Round(time.Millisecond),
+		}, c)
+		require.NoError(t, err)
+
+		board, err := task.BoardTasks(eCtx, project.ID)
+		require.NoError(t, err)
+		require.Len(t, board, 2)
+
+		role, err := repo.MemberRole(eCtx, project.ID, other.ID)
+		require.NoError(t, err)
+		require.Equal(t, entity.RoleOwner, role)
+
+		workflow, err := repo.ProjectWorkflow(eCtx, project.ID)
+		require.NoError(t, err)
+		require.Equal(t, entity.DefaultWorkflow(project.ID), workflow)
+
+		return project, board
+	}
+
+	// with members, references to the source owner survive and they become an admin
+	project, board := clone(entity.ProjectClone{IncludeMembers: true})
+
+	role, err := repo.MemberRole(eCtx, project.ID, owner.ID)
+	require.NoError(t, err)
+	require.Equal(t, entity.RoleAdmin, role)
+
+	copied, copiedChild := board[0], board[1]
+	require.NotEqual(t, parent.ID, copied.ID)
+	require.Equal(t, parent.Name, copied.Name)
+	require.Equal(t, "parent description", copied.Description)
+	require.Equal(t, entity.PriorityHigh, copied.Priority)
+	require.Equal(t, owner.ID, copied.UserID)
+	require.Equal(t, parent.Rank, copied.Rank)
+	require.Equal(t, &copied.ID, copiedChild.ParentID)
+	require.Equal(t, []int64{copied.ID}, copiedChild.BlockedBy)
+	require.Equal(t, []int64{owner.ID}, copiedChild.Assignees)
+	require.Equal(t, entity.NewChecklistProgress(0, 1), copiedChild.Checklist)
+
+	labels, err := repo.ProjectLabels(eCtx, project.ID)
+	require.NoError(t, err)
+	require.Len(t, labels, 1)
+	require.Equal(t, labels, copiedChild.Labels)
+
+	fields, err := repo.ProjectCustomFields(eCtx, project.ID)
+	require.NoError(t, err)
+	require.Len(t, fields, 1)
+	require.Equal(t, map[int64]json.RawMessage{fields[0].ID: json.RawMessage(strconv.FormatInt(owner.ID, 10))}, copied.CustomFields)
+
+	items, err := task.ChecklistItems(eCtx, copiedChild.ID)
+	require.NoError(t, err)
+	require.Equal(t, &owner.ID, items[0].ResponsibleID)
+
+	// without members, references to non-members are dropped
+	_, board = clone(entity.ProjectClone{ResetCreators: true})
+
+	copied, copiedChild = board[0], board[1]
+	require.Equal(t, other.ID, copied.UserID)
+	require.Empty(t, copied.CustomFields)
+	require.Empty(t, copiedChild.Assignees)
+
+	items, err = task.ChecklistItems(eCtx, copiedChild.ID)
+	require.NoError(t, err)
+	require.Nil(t, items[0].ResponsibleID)
+
+	// the source stays untouched
+	board, err = task.BoardTasks(eCtx, source.ID)
+	require.NoError(t, err)
+	require.Equal(t, []int64{parent.ID, child.ID}, []int64{board[0].ID, board[1].ID})
+}
+
+func TestRepository_TransferTasks(t *testing.T) {
+	db := GetDB(t)
+
+	userRepo := NewUserRepository(db)
+	repo := NewProjectRepository(db)
+	task := NewTaskRepository(db)
+
+	var users []entity.User
+	for range 2 {
+		user, err := userRepo.CreateUser(eCtx, entity.User{
+			Name:      uuid.NewString(),
+			Password:  uuid.NewString(),
+			Email:     uuid.NewString(),
+			CreatedAt: time.Now().UTC().Round(time.Millisecond),
+		})
+		require.NoError(t, err)
+
+		users = append(users, user)
+	}
+
+	user, outsider := users[0], users[1]
+
+	var projects []entity.Project
+	for range 2 {
+		project, err := repo.CreateProject(eCtx, entity.Project{
+			Name:      uuid.NewString(),
+			UserID:    user.ID,
+			CreatedAt: time.Now().UTC().Round(time.Millisecond),
+		})
+		require.NoError(t, err)
+
+		projects = append(projects, project)
+	}
+
+	source, dest := projects[0], projects[1]
+
+	label, err := repo.CreateLabel(eCtx, entity.Label{ProjectID: source.ID, Name: "bug", Color: "#d73a4a"})
+	require.NoError(t, err)
+
+	notes, err := repo.CreateCustomField(eCtx, entity.CustomField{ProjectID: source.ID, Name: "notes", Type: entity.CustomFieldText})
+	require.NoError(t, err)
+
+	stage, err := repo.CreateCustomField(eCtx, entity.CustomField{ProjectID: source.ID, Name: "stage", Type: entity.CustomFieldSelect, Options: []string{"alpha", "beta"}})
+	require.NoError(t, err)
+
+	destStage, err := repo.CreateCustomField(eCtx, entity.CustomField{ProjectID: dest.ID, Name: "stage", Type: entity.CustomFieldSelect, Options: []string{"alpha"}})
+	require.NoError(t, err)
+
+	var tasks []entity.Task
+
+	for _, name := range []string{"parent", "child", "grandchild"} {
+		created := entity.Task{
+			Name:      name,
+			UserID:    user.ID,
+			ProjectID: source.ID,
+			Status:    entity.StatusTodo,
+			Priority:  entity.PriorityMedium,
+			CreatedAt: time.Now().UTC().Round(time.Millisecond),
+		}
+
+		if len(tasks) > 0 {
+			created.ParentID = &tasks[len(tasks)-1].ID
+		} else {
+			created.CustomFields = map[int64]json.RawMessage{notes.ID: json.RawMessage(`"kept"`), stage.ID: json.RawMessage(`"beta"`)}
+		}
+
+		created, err = task.CreateTask(eCtx, created)
+		require.NoError(t, err)
+
+		tasks = append(tasks, created)
+	}
+
+	parent, child, grandchild := tasks[0], tasks[1], tasks[2]
+
+	require.NoError(t, task.AssignTask(eCtx, child.ID, user.ID))
+	require.NoError(t, task.AssignTask(eCtx, child.ID, outsider.ID))
+	require.NoError(t, task.AttachLabel(eCtx, child.ID, label.ID))
+
+	_, err = task.AddChecklistItem(eCtx, entity.ChecklistItem{
+		TaskID:        child.ID,
+		Text:          uuid.NewString(),
+		ResponsibleID: &outsider.ID,
+		CreatedAt:     time.Now().UTC().Round(time.Millisecond),
+	})
+	require.NoError(t, err)
+
+	// the grandchild stays behind
+	parent.ProjectID, child.ProjectID = dest.ID, dest.ID
+	child.Status = entity.StatusDone
+
+	err = task.TransferTasks(eCtx, dest.ID, []entity.Task{parent, child}, true)
+	require.NoError(t, err)
+
+	board, err := task.BoardTasks(eCtx, dest.ID)
+	require.NoError(t, err)
+	require.Equal(t, []int64{parent.ID, child.ID}, []int64{board[0].ID, board[1].ID})
+
+	movedParent, movedChild := board[0], board[1]
+	require.Equal(t, entity.StatusDone, movedChild.Status)
+	require.Equal(t, &parent.ID, movedChild.ParentID)
+	require.Equal(t, []int64{user.ID}, movedChild.Assignees)
+	require.Len(t, movedChild.Labels, 1)
+	require.Equal(t, dest.ID, movedChild.Labels[0].ProjectID)
+	require.Equal(t, "bug", movedChild.Labels[0].Name)
+
+	fields, err := repo.ProjectCustomFields(eCtx, dest.ID)
+	require.NoError(t, err)
+	require.Len(t, fields, 2)
+
+	// the value the destination field doesn't accept is dropped
+	for _, f := range fields {
+		if f.ID != destStage.ID {
+			require.Equal(t, map[int64]json.RawMessage{f.ID: json.RawMessage(`"kept"`)}, movedParent.CustomFields)
+		}
+	}
+
+	items, err := task.ChecklistItems(eCtx, child.ID)
+	require.NoError(t, err)
+	require.Nil(t, items[0].ResponsibleID)
+
+	left, err := task.TaskByID(eCtx, grandchild.ID)
+	require.NoError(t, err)
+	require.Equal(t, source.ID, left.ProjectID)
+	require.Nil(t, left.ParentID)
+
+	err = task.TransferTasks(eCtx, dest.ID, []entity.Task{{ID: -1, Status: entity.StatusTodo}}, false)
+	require.ErrorIs(t, err, entity.ErrNotFound)
+}
+
+func TestProjectService_DeleteTaskSubtasks(t *testing.T) {
+	db := GetDB(t)
+
+	userRepo := NewUserRepository(db)
+	repo := NewProjectRepository(db)
+	task := NewTaskRepository(db)
+	projects := service.NewProjectRepository(NewAuthRepository(db), repo, task, userRepo, NewHistoryRepository(db), NewActivityRepository(db), nil)
+
+	var users []entity.User
+	for range 2 {
+		user, err := userRepo.CreateUser(eCtx, entity.User{
+			Name:      uuid.NewString(),
+			Password:  uuid.NewString(),
+			Email:     uuid.NewString(),
+			CreatedAt: time.Now().UTC().Round(time.Millisecond),
+		})
+		require.NoError(t, err)
+
+		users = append(users, user)
+	}
+
+	owner, member := users[0], users[1]
+
+	project, err := repo.CreateProject(eCtx, entity.Project{
+		Name:      uuid.NewString(),
+		UserID:    owner.ID,
+		CreatedAt: time.Now().UTC().Round(time.Millisecond),
+	})
+	require.NoError(t, err)
+
+	code := uuid.NewString()
+
+	err = repo.SaveInvitationCode(eCtx, code, member.ID, project.ID, entity.RoleMember)
+	require.NoError(t, err)
+
+	_, _, err = repo.AddProjectMember(eCtx, code)
+	require.NoError(t, err)
+
+	parent, err := task.CreateTask(eCtx, entity.Task{
+		Name:      uuid.NewString(),
+		UserID:    member.ID,
+		ProjectID: project.ID,
+		Status:    entity.StatusTodo,
+		Priority:  entity.PriorityMedium,
+		CreatedAt: time.Now().UTC().Round(time.Millisecond),
+	})
+	require.NoError(t, err)
+
+	child, err := task.CreateTask(eCtx, entity.Task{
+		Name:      uuid.NewString(),
+		UserID:    owner.ID,
+		ProjectID: project.ID,
+		ParentID:  &parent.ID,
+		Status:    entity.StatusTodo,
+		Priority:  entity.PriorityMedium,
+		CreatedAt: time.Now().UTC().Round(time.Millisecond),
+	})
+	require.NoError(t, err)
+
+	ctx := context.WithValue(eCtx, "user", member)
+
+	// the member created the parent but not the subtask cascading would delete
+	err = projects.DeleteTask(ctx, parent.ID, entity.SubtasksCascade)
+	require.ErrorIs(t, err, entity.ErrForbidden)
+
+	_, err = task.TaskByID(eCtx, child.ID)
+	require.NoError(t, err)
+
+	// keeping subtasks only deletes the member's own task
+	err = projects.DeleteTask(ctx, parent.ID, entity.SubtasksReparent)
+	require.NoError(t, err)
+
+	got, err := task.TaskByID(eCtx, child.ID)
+	require.NoError(t, err)
+	require.Nil(t, got.ParentID)
+}
+
 func GetDB(t *testing.T) *sql.DB {
 	t.Helper()
 
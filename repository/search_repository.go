@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"task-manager/entity"
+)
+
+type SearchRepository struct {
+	db *sql.DB
+}
+
+func NewSearchRepository(database *sql.DB) *SearchRepository {
+	return &SearchRepository{db: database}
+}
+
+// headlineOptions wraps matched words into <mark></mark>, snippets are cut to a couple of short fragments.
+const (
+	nameHeadlineOptions    = "HighlightAll=true, StartSel=<mark>, StopSel=</mark>"
+	snippetHeadlineOptions = "MaxFragments=2, MaxWords=20, MinWords=5, StartSel=<mark>, StopSel=</mark>"
+)
+
+// escapeHTML returns the SQL expression escaping HTML special characters of the text column, so headlines
+// carry no markup but <mark>. The parser reads escaped characters as entities and still matches the words around them.
+func escapeHTML(column string) string {
+	return fmt.Sprintf("replace(replace(replace(%s, '&', '&amp;'), '<', '&lt;'), '>', '&gt;')", column)
+}
+
+// Search returns up to limit tasks and projects of projects userID is a member of
+// matching text, the most relevant first. Headlines are built for the returned rows only.
+func (r *SearchRepository) Search(ctx context.Context, userID int64, text string, limit int) ([]entity.SearchResult, error) {
+	q := fmt.Sprintf(`WITH q AS (SELECT websearch_to_tsquery('english', $2) AS query),
+hits AS (
+	SELECT '%s' AS type, t.id, t.project_id, t.name, t.description, ts_rank(t.search, q.query) AS rank
+	FROM tasks t JOIN projects_users pu ON pu.project_id = t.project_id, q
+	WHERE pu.user_id = $1 AND t.search @@ q.query
+	UNION ALL
+	SELECT '%s', p.id, p.id, p.name, '', ts_rank(p.search, q.query)
+	FROM projects p JOIN projects_users pu ON pu.project_id = p.id, q
+	WHERE pu.user_id = $1 AND p.search @@ q.query
+	ORDER BY rank DESC, type, id
+	LIMIT $3
+)
+SELECT h.type, h.id, h.project_id, h.name,
+	ts_headline('english', %s, q.query, $4),
+	CASE WHEN h.description = '' THEN '' ELSE ts_headline('english', %s, q.query, $5) END,
+	h.rank
+FROM hits h, q
+ORDER BY h.rank DESC, h.type, h.id`, entity.SearchTypeTask, entity.SearchTypeProject, escapeHTML("h.name"), escapeHTML("h.description"))
+
+	rows, err := r.db.QueryContext(ctx, q, userID, text, limit, nameHeadlineOptions, snippetHeadlineOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []entity.SearchResult
+
+	for rows.Next() {
+		var res entity.SearchResult
+
+		err = rows.Scan(&res.Type, &res.ID, &res.ProjectID, &res.Name, &res.Highlight, &res.Snippet, &res.Rank)
+		if err != nil {
+			return nil, err
+		}
+
+		results = append(results, res)
+	}
+
+	return results, rows.Err()
+}
@@ -1,12 +1,26 @@
 package repository
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"github.com/lib/pq"
+	"maps"
+	"strconv"
+	"strings"
 	"task-manager/entity"
+	"time"
 )
 
+// taskDone is true when task t is in one of the done statuses of its project workflow.
+const taskDone = "EXISTS(SELECT 1 FROM project_statuses ps WHERE ps.project_id = t.project_id AND ps.name = t.status AND ps.done)"
+
+const taskColumns = "t.id, t.name, t.project_id, t.parent_task_id, t.description, t.user_id, t.status, t.priority, t.due_at, " +
+	"(t.due_at IS NOT NULL AND t.due_at < NOW() AND NOT " + taskDone + "), t.rank, t.created_at"
+
 type TaskRepository struct {
 	db *sql.DB
 }
@@ -15,10 +29,47 @@ func NewTaskRepository(db *sql.DB) *TaskRepository {
 	return &TaskRepository{db: db}
 }
 
+// CreateTask puts the task at the end of its status column.
 func (r *TaskRepository) CreateTask(ctx context.Context, t entity.Task) (entity.Task, error) {
-	q := "INSERT INTO tasks (name, project_id, description, user_id, created_at) VALUES ($1, $2, $3, $4, $5) RETURNING id"
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return entity.Task{}, err
+	}
+	defer tx.Rollback()
+
+	t, err = insertTask(ctx, tx, t)
+	if err != nil {
+		return entity.Task{}, err
+	}
+
+	return t, tx.Commit()
+}
+
+// insertTask inserts the task ranked at the end of its status column.
+func insertTask(ctx context.Context, tx *sql.Tx, t entity.Task) (entity.Task, error) {
+	err := lockBoard(ctx, tx, t.ProjectID)
+	if err != nil {
+		return entity.Task{}, err
+	}
+
+	last, err := lastRank(ctx, tx, t.ProjectID, t.Status, 0)
+	if err != nil {
+		return entity.Task{}, err
+	}
+
+	t.Rank, err = entity.RankBetween(last, "")
+	if err != nil {
+		return entity.Task{}, err
+	}
+
+	q := "INSERT INTO tasks (name, project_id, parent_task_id, description, user_id, status, priority, due_at, rank, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id"
 
-	err := r.db.QueryRowContext(ctx, q, t.Name, t.ProjectID, t.Description, t.UserID, t.CreatedAt).Scan(&t.ID)
+	err = tx.QueryRowContext(ctx, q, t.Name, t.ProjectID, t.ParentID, t.Description, t.UserID, t.Status, t.Priority, t.DueAt, t.Rank, t.CreatedAt).Scan(&t.ID)
+	if err != nil {
+		return entity.Task{}, err
+	}
+
+	err = setFieldValues(ctx, tx, t.ID, t.CustomFields)
 	if err != nil {
 		return entity.Task{}, err
 	}
@@ -26,10 +77,31 @@ func (r *TaskRepository) CreateTask(ctx context.Context, t entity.Task) (entity.
 	return t, nil
 }
 
+// setFieldValues stores custom field values of the task, nil values are removed.
+func setFieldValues(ctx context.Context, tx *sql.Tx, taskID int64, values map[int64]json.RawMessage) error {
+	for fieldID, value := range values {
+		q := "DELETE FROM task_field_values WHERE task_id = $1 AND field_id = $2"
+		args := []any{taskID, fieldID}
+
+		if value != nil {
+			q = `INSERT INTO task_field_values(task_id, field_id, value) VALUES ($1, $2, $3)
+			ON CONFLICT(task_id, field_id) DO UPDATE SET value = $3`
+			args = append(args, []byte(value))
+		}
+
+		_, err := tx.ExecContext(ctx, q, args...)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (r *TaskRepository) TaskByID(ctx context.Context, id int64) (t entity.Task, err error) {
-	q := "SELECT id, name, project_id, description, user_id, created_at FROM tasks WHERE id = $1"
+	q := "SELECT " + taskColumns + " FROM tasks t WHERE t.id = $1"
 
-	err = r.db.QueryRowContext(ctx, q, id).Scan(&t.ID, &t.Name, &t.ProjectID, &t.Description, &t.UserID, &t.CreatedAt)
+	t, err = scanTask(r.db.QueryRowContext(ctx, q, id))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return entity.Task{}, entity.ErrNotFound
@@ -38,11 +110,28 @@ func (r *TaskRepository) TaskByID(ctx context.Context, id int64) (t entity.Task,
 		return t, err
 	}
 
-	return t, nil
+	tasks := []entity.Task{t}
+
+	err = r.loadDetails(ctx, tasks)
+	if err != nil {
+		return entity.Task{}, err
+	}
+
+	return tasks[0], nil
+}
+
+func (r *TaskRepository) ProjectTasks(ctx context.Context, projectID int64, tq entity.TaskQuery) (entity.Page[entity.Task], error) {
+	var b queryBuilder
+	b.and("t.project_id = " + b.arg(projectID))
+
+	return r.tasks(ctx, &b, tq)
 }
 
-func (r *TaskRepository) ProjectTasks(ctx context.Context, projectID int64) (tasks []entity.Task, err error) {
-	q := "SELECT id, name, project_id, description, user_id, created_at FROM tasks WHERE project_id = $1"
+// BoardTasks returns all tasks of the project in board order: by status column, then by rank.
+func (r *TaskRepository) BoardTasks(ctx context.Context, projectID int64) (tasks []entity.Task, err error) {
+	q := `SELECT ` + taskColumns + ` FROM tasks t
+	    LEFT JOIN project_statuses ps ON ps.project_id = t.project_id AND ps.name = t.status
+	WHERE t.project_id = $1 ORDER BY ps.position, t.rank, t.id`
 
 	rows, err := r.db.QueryContext(ctx, q, projectID)
 	if err != nil {
@@ -51,9 +140,7 @@ func (r *TaskRepository) ProjectTasks(ctx context.Context, projectID int64) (tas
 	defer rows.Close()
 
 	for rows.Next() {
-		var task entity.Task
-
-		err = rows.Scan(&task.ID, &task.Name, &task.ProjectID, &task.Description, &task.UserID, &task.CreatedAt)
+		task, err := scanTask(rows)
 		if err != nil {
 			return nil, err
 		}
@@ -61,22 +148,243 @@ func (r *TaskRepository) ProjectTasks(ctx context.Context, projectID int64) (tas
 		tasks = append(tasks, task)
 	}
 
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	err = r.loadDetails(ctx, tasks)
+	if err != nil {
+		return nil, err
+	}
+
 	return tasks, nil
 }
 
-func (r *TaskRepository) UserTasks(ctx context.Context, userID int64) (tasks []entity.Task, err error) {
-	q := "	SELECT id, name, project_id, description, user_id, created_at FROM tasks WHERE user_id = $1"
+func (r *TaskRepository) UserTasks(ctx context.Context, userID int64, tq entity.TaskQuery) (entity.Page[entity.Task], error) {
+	var b queryBuilder
+	b.and("t.user_id = " + b.arg(userID))
+
+	return r.tasks(ctx, &b, tq)
+}
+
+// UpdateTask sets only non-nil fields of upd and custom field values it holds in one transaction.
+func (r *TaskRepository) UpdateTask(ctx context.Context, id int64, upd entity.TaskToUpdate) error {
+	var (
+		set  []string
+		args []any
+	)
+
+	if upd.Name != nil {
+		args = append(args, *upd.Name)
+		set = append(set, fmt.Sprintf("name = $%d", len(args)))
+	}
+
+	if upd.Description != nil {
+		args = append(args, *upd.Description)
+		set = append(set, fmt.Sprintf("description = $%d", len(args)))
+	}
+
+	if upd.Status != nil {
+		args = append(args, *upd.Status)
+		set = append(set, fmt.Sprintf("status = $%d", len(args)))
+	}
+
+	if upd.Priority != nil {
+		args = append(args, *upd.Priority)
+		set = append(set, fmt.Sprintf("priority = $%d", len(args)))
+	}
+
+	if upd.DueAt.Set {
+		args = append(args, upd.DueAt.Value)
+		set = append(set, fmt.Sprintf("due_at = $%d", len(args)))
+	}
+
+	if upd.ParentID.Set {
+		args = append(args, upd.ParentID.Value)
+		set = append(set, fmt.Sprintf("parent_task_id = $%d", len(args)))
+	}
+
+	if upd.IsEmpty() {
+		return nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// a task changing its status goes to the end of the new column
+	if upd.Status != nil {
+		rank, err := statusRank(ctx, tx, id, *upd.Status)
+		if err != nil {
+			return err
+		}
+
+		args = append(args, rank)
+		set = append(set, fmt.Sprintf("rank = $%d", len(args)))
+	}
+
+	// an update of custom fields only still locks the task row and finds out whether it exists
+	if len(set) == 0 {
+		set = append(set, "id = id")
+	}
+
+	args = append(args, id)
+	q := fmt.Sprintf("UPDATE tasks SET %s WHERE id = $%d", strings.Join(set, ", "), len(args))
+
+	res, err := tx.ExecContext(ctx, q, args...)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return entity.ErrNotFound
+	}
+
+	err = setFieldValues(ctx, tx, id, upd.CustomFields)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// DeleteTask deletes the task with all its subtasks, unless reparent is set:
+// then subtasks are moved under the parent of the deleted task first.
+func (r *TaskRepository) DeleteTask(ctx context.Context, id int64, reparent bool) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if reparent {
+		q := "UPDATE tasks SET parent_task_id = (SELECT parent_task_id FROM tasks WHERE id = $1) WHERE parent_task_id = $1"
+
+		_, err = tx.ExecContext(ctx, q, id)
+		if err != nil {
+			return err
+		}
+	}
+
+	q := "DELETE FROM tasks WHERE id = $1"
+
+	res, err := tx.ExecContext(ctx, q, id)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return entity.ErrNotFound
+	}
+
+	return tx.Commit()
+}
+
+// AddDependency records that the task is blocked by blockerID.
+func (r *TaskRepository) AddDependency(ctx context.Context, taskID int64, blockerID int64) error {
+	q := "INSERT INTO task_dependencies(task_id, blocker_id) VALUES ($1, $2) ON CONFLICT(task_id, blocker_id) DO NOTHING"
+
+	_, err := r.db.ExecContext(ctx, q, taskID, blockerID)
+	return err
+}
+
+func (r *TaskRepository) RemoveDependency(ctx context.Context, taskID int64, blockerID int64) error {
+	q := "DELETE FROM task_dependencies WHERE task_id = $1 AND blocker_id = $2"
+
+	res, err := r.db.ExecContext(ctx, q, taskID, blockerID)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return entity.ErrNotFound
+	}
+
+	return nil
+}
+
+// DependsOn reports whether the task is blockerID itself or is blocked by it directly or through other tasks.
+func (r *TaskRepository) DependsOn(ctx context.Context, taskID int64, blockerID int64) (bool, error) {
+	q := `WITH RECURSIVE blockers(id) AS (
+		SELECT $1::bigint
+		UNION
+		SELECT td.blocker_id FROM task_dependencies td JOIN blockers b ON td.task_id = b.id
+	)
+	SELECT EXISTS(SELECT 1 FROM blockers WHERE id = $2)`
+
+	var depends bool
+
+	err := r.db.QueryRowContext(ctx, q, taskID, blockerID).Scan(&depends)
+	return depends, err
+}
+
+// OpenBlockers returns ids of tasks blocking the task that aren't done yet.
+func (r *TaskRepository) OpenBlockers(ctx context.Context, taskID int64) (ids []int64, err error) {
+	q := `SELECT t.id FROM task_dependencies td JOIN tasks t ON t.id = td.blocker_id
+	WHERE td.task_id = $1 AND NOT ` + taskDone + ` ORDER BY t.id`
 
-	rows, err := r.db.QueryContext(ctx, q, userID)
+	rows, err := r.db.QueryContext(ctx, q, taskID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
 	for rows.Next() {
-		var task entity.Task
+		var id int64
+
+		err = rows.Scan(&id)
+		if err != nil {
+			return nil, err
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// ChildTasks returns direct subtasks of the task.
+func (r *TaskRepository) ChildTasks(ctx context.Context, parentID int64, tq entity.TaskQuery) (entity.Page[entity.Task], error) {
+	var b queryBuilder
+	b.and("t.parent_task_id = " + b.arg(parentID))
+
+	return r.tasks(ctx, &b, tq)
+}
 
-		err = rows.Scan(&task.ID, &task.Name, &task.ProjectID, &task.Description, &task.UserID, &task.CreatedAt)
+// TaskDescendants returns subtasks of the task at any depth ordered by id.
+func (r *TaskRepository) TaskDescendants(ctx context.Context, id int64) (tasks []entity.Task, err error) {
+	q := `WITH RECURSIVE tree(id) AS (
+		SELECT id FROM tasks WHERE parent_task_id = $1
+		UNION ALL
+		SELECT t.id FROM tasks t JOIN tree ON t.parent_task_id = tree.id
+	)
+	SELECT ` + taskColumns + ` FROM tasks t WHERE t.id IN (SELECT id FROM tree) ORDER BY t.id`
+
+	rows, err := r.db.QueryContext(ctx, q, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		task, err := scanTask(rows)
 		if err != nil {
 			return nil, err
 		}
@@ -84,5 +392,1140 @@ func (r *TaskRepository) UserTasks(ctx context.Context, userID int64) (tasks []e
 		tasks = append(tasks, task)
 	}
 
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	err = r.loadDetails(ctx, tasks)
+	if err != nil {
+		return nil, err
+	}
+
 	return tasks, nil
 }
+
+// TransferTasks moves the tasks to the end of their status columns in the project in the given order, tasks must
+// already have statuses of the project workflow and parents moved along or none. Their data comes along:
+// labels are matched by name and created when missing, custom field values go to fields with the same name
+// and type, created when missing if createFields is set. Values the fields no longer accept, assignees,
+// checklist responsibles and user field values referring to non-members of the project are dropped.
+// Subtasks left behind are detached.
+func (r *TaskRepository) TransferTasks(ctx context.Context, projectID int64, tasks []entity.Task, createFields bool) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = lockBoard(ctx, tx, projectID)
+	if err != nil {
+		return err
+	}
+
+	ids := make([]int64, len(tasks))
+	last := make(map[string]string)
+
+	for i, t := range tasks {
+		ids[i] = t.ID
+
+		rank, ok := last[t.Status]
+		if !ok {
+			rank, err = lastRank(ctx, tx, projectID, t.Status, 0)
+			if err != nil {
+				return err
+			}
+		}
+
+		rank, err = entity.RankBetween(rank, "")
+		if err != nil {
+			return err
+		}
+
+		last[t.Status] = rank
+
+		q := "UPDATE tasks SET project_id = $1, status = $2, parent_task_id = $3, rank = $4 WHERE id = $5"
+
+		res, err := tx.ExecContext(ctx, q, projectID, t.Status, t.ParentID, rank, t.ID)
+		if err != nil {
+			return err
+		}
+
+		n, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		if n == 0 {
+			return entity.ErrNotFound
+		}
+	}
+
+	members := "SELECT user_id FROM projects_users WHERE project_id = $1"
+
+	queries := []struct {
+		q    string
+		args []any
+	}{
+		{q: "UPDATE tasks SET parent_task_id = NULL WHERE parent_task_id = ANY($2) AND project_id <> $1"},
+		{q: `INSERT INTO labels(project_id, name, color)
+		SELECT DISTINCT ON (l.name) $1, l.name, l.color FROM task_labels tl JOIN labels l ON l.id = tl.label_id
+		WHERE tl.task_id = ANY($2) AND l.project_id <> $1
+		ORDER BY l.name, l.id
+		ON CONFLICT(project_id, name) DO NOTHING`},
+		{q: `UPDATE task_labels tl SET label_id = d.id FROM labels l, labels d
+		WHERE l.id = tl.label_id AND tl.task_id = ANY($2) AND l.project_id <> $1 AND d.project_id = $1 AND d.name = l.name`},
+		{q: `INSERT INTO custom_fields(project_id, name, type, options)
+		SELECT DISTINCT ON (f.name) $1, f.name, f.type, f.options FROM task_field_values v JOIN custom_fields f ON f.id = v.field_id
+		WHERE v.task_id = ANY($2) AND f.project_id <> $1 AND $3
+		ORDER BY f.name, f.id
+		ON CONFLICT(project_id, name) DO NOTHING`, args: []any{createFields}},
+		{q: `UPDATE task_field_values v SET field_id = d.id FROM custom_fields f, custom_fields d
+		WHERE f.id = v.field_id AND v.task_id = ANY($2) AND f.project_id <> $1
+		    AND d.project_id = $1 AND d.name = f.name AND d.type = f.type`},
+		{q: `UPDATE task_field_values v SET value = (SELECT jsonb_agg(o) FROM jsonb_array_elements_text(v.value) o WHERE o = ANY(f.options))
+		FROM custom_fields f WHERE f.id = v.field_id AND v.task_id = ANY($2) AND f.project_id = $1 AND f.type = $3 AND v.value ?| f.options`,
+			args: []any{entity.CustomFieldMultiSelect}},
+		{q: `DELETE FROM task_field_values v USING custom_fields f
+		WHERE f.id = v.field_id AND v.task_id = ANY($2) AND (f.project_id <> $1 OR CASE f.type
+		    WHEN $3 THEN NOT (v.value #>> '{}') = ANY(f.options)
+		    WHEN $4 THEN NOT v.value ?| f.options
+		    WHEN $5 THEN (v.value)::bigint NOT IN (` + members + `)
+		    ELSE FALSE END)`,
+			args: []any{entity.CustomFieldSelect, entity.CustomFieldMultiSelect, entity.CustomFieldUser}},
+		{q: "DELETE FROM task_assignees WHERE task_id = ANY($2) AND user_id NOT IN (" + members + ")"},
+		{q: "UPDATE checklist_items SET responsible_id = NULL WHERE task_id = ANY($2) AND responsible_id NOT IN (" + members + ")"},
+	}
+
+	for _, query := range queries {
+		_, err = tx.ExecContext(ctx, query.q, append([]any{projectID, pq.Array(ids)}, query.args...)...)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// IsAncestor reports whether ancestorID is the task itself or any task above it in the hierarchy.
+func (r *TaskRepository) IsAncestor(ctx context.Context, ancestorID int64, taskID int64) (bool, error) {
+	q := `WITH RECURSIVE up(id) AS (
+		SELECT $2::bigint
+		UNION ALL
+		SELECT t.parent_task_id FROM tasks t JOIN up ON t.id = up.id WHERE t.parent_task_id IS NOT NULL
+	)
+	SELECT EXISTS(SELECT 1 FROM up WHERE id = $1)`
+
+	var is bool
+
+	err := r.db.QueryRowContext(ctx, q, ancestorID, taskID).Scan(&is)
+	return is, err
+}
+
+// AssignedTasks returns tasks the user is assigned to.
+func (r *TaskRepository) AssignedTasks(ctx context.Context, userID int64, tq entity.TaskQuery) (entity.Page[entity.Task], error) {
+	var b queryBuilder
+	b.and("t.id IN (SELECT task_id FROM task_assignees WHERE user_id = " + b.arg(userID) + ")")
+
+	return r.tasks(ctx, &b, tq)
+}
+
+func (r *TaskRepository) AssignTask(ctx context.Context, taskID int64, userID int64) error {
+	q := "INSERT INTO task_assignees(task_id, user_id) VALUES ($1, $2) ON CONFLICT(task_id, user_id) DO NOTHING"
+
+	_, err := r.db.ExecContext(ctx, q, taskID, userID)
+	return err
+}
+
+func (r *TaskRepository) UnassignTask(ctx context.Context, taskID int64, userID int64) error {
+	q := "DELETE FROM task_assignees WHERE task_id = $1 AND user_id = $2"
+
+	res, err := r.db.ExecContext(ctx, q, taskID, userID)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return entity.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *TaskRepository) AttachLabel(ctx context.Context, taskID int64, labelID int64) error {
+	q := "INSERT INTO task_labels(task_id, label_id) VALUES ($1, $2) ON CONFLICT(task_id, label_id) DO NOTHING"
+
+	_, err := r.db.ExecContext(ctx, q, taskID, labelID)
+	return err
+}
+
+func (r *TaskRepository) DetachLabel(ctx context.Context, taskID int64, labelID int64) error {
+	q := "DELETE FROM task_labels WHERE task_id = $1 AND label_id = $2"
+
+	res, err := r.db.ExecContext(ctx, q, taskID, labelID)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return entity.ErrNotFound
+	}
+
+	return nil
+}
+
+// UpdateTaskStatus sets status of the task ranking it at the end of the new column.
+func (r *TaskRepository) UpdateTaskStatus(ctx context.Context, id int64, status string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	rank, err := statusRank(ctx, tx, id, status)
+	if err != nil {
+		return err
+	}
+
+	q := "UPDATE tasks SET status = $1, rank = $2 WHERE id = $3"
+
+	_, err = tx.ExecContext(ctx, q, status, rank, id)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// statusRank locks the board of the task and returns its rank in the status column: the current one
+// if the task stays in it, otherwise a rank at the end of the column.
+func statusRank(ctx context.Context, tx *sql.Tx, id int64, status string) (string, error) {
+	var (
+		projectID     int64
+		current, rank string
+	)
+
+	q := "SELECT project_id, status, rank FROM tasks WHERE id = $1"
+
+	err := tx.QueryRowContext(ctx, q, id).Scan(&projectID, &current, &rank)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", entity.ErrNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if current == status {
+		return rank, nil
+	}
+
+	err = lockBoard(ctx, tx, projectID)
+	if err != nil {
+		return "", err
+	}
+
+	last, err := lastRank(ctx, tx, projectID, status, id)
+	if err != nil {
+		return "", err
+	}
+
+	return entity.RankBetween(last, "")
+}
+
+// MoveTask sets status of the task and ranks it between the neighbours from move in one transaction.
+// Neighbours must be in the move.Status column of the same project.
+func (r *TaskRepository) MoveTask(ctx context.Context, task entity.Task, move entity.TaskMove) (rank string, err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	err = lockBoard(ctx, tx, task.ProjectID)
+	if err != nil {
+		return "", err
+	}
+
+	var prev, next string
+
+	if move.AfterID != nil {
+		prev, err = neighbourRank(ctx, tx, task, move.Status, *move.AfterID)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if move.BeforeID != nil {
+		next, err = neighbourRank(ctx, tx, task, move.Status, *move.BeforeID)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	switch {
+	case move.AfterID != nil && move.BeforeID == nil:
+		next, err = adjacentRank(ctx, tx, task, move.Status, prev, true)
+	case move.AfterID == nil && move.BeforeID != nil:
+		prev, err = adjacentRank(ctx, tx, task, move.Status, next, false)
+	case move.AfterID == nil && move.BeforeID == nil:
+		prev, err = lastRank(ctx, tx, task.ProjectID, move.Status, task.ID)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	rank, err = entity.RankBetween(prev, next)
+	if err != nil {
+		return "", err
+	}
+
+	q := "UPDATE tasks SET status = $1, rank = $2 WHERE id = $3"
+
+	_, err = tx.ExecContext(ctx, q, move.Status, rank, task.ID)
+	if err != nil {
+		return "", err
+	}
+
+	return rank, tx.Commit()
+}
+
+// lockBoard serializes ranking tasks of the project until the transaction ends, so ranks don't collide.
+func lockBoard(ctx context.Context, tx *sql.Tx, projectID int64) error {
+	_, err := tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock($1)", projectID)
+	return err
+}
+
+// lastRank returns the highest rank in the status column ignoring the task with exceptID, empty for an empty column.
+func lastRank(ctx context.Context, tx *sql.Tx, projectID int64, status string, exceptID int64) (rank string, err error) {
+	q := "SELECT COALESCE(MAX(rank), '') FROM tasks WHERE project_id = $1 AND status = $2 AND id <> $3"
+
+	err = tx.QueryRowContext(ctx, q, projectID, status, exceptID).Scan(&rank)
+	return rank, err
+}
+
+// adjacentRank returns the rank right after (or before) the given one in the status column ignoring the task,
+// empty if there is none.
+func adjacentRank(ctx context.Context, tx *sql.Tx, task entity.Task, status string, rank string, after bool) (string, error) {
+	q := "SELECT COALESCE(MAX(rank), '') FROM tasks WHERE project_id = $1 AND status = $2 AND id <> $3 AND rank < $4"
+	if after {
+		q = "SELECT COALESCE(MIN(rank), '') FROM tasks WHERE project_id = $1 AND status = $2 AND id <> $3 AND rank > $4"
+	}
+
+	err := tx.QueryRowContext(ctx, q, task.ProjectID, status, task.ID, rank).Scan(&rank)
+	return rank, err
+}
+
+func neighbourRank(ctx context.Context, tx *sql.Tx, task entity.Task, status string, id int64) (rank string, err error) {
+	if id == task.ID {
+		return "", fmt.Errorf("%w: task can't be its own neighbour", entity.ErrBadRequest)
+	}
+
+	q := "SELECT rank FROM tasks WHERE id = $1 AND project_id = $2 AND status = $3"
+
+	err = tx.QueryRowContext(ctx, q, id, task.ProjectID, status).Scan(&rank)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", fmt.Errorf("%w: task %d is not in the %q column", entity.ErrBadRequest, id, status)
+		}
+
+		return "", err
+	}
+
+	return rank, nil
+}
+
+// ProjectStatusesInUse returns distinct statuses tasks of the project currently have.
+func (r *TaskRepository) ProjectStatusesInUse(ctx context.Context, projectID int64) (statuses []string, err error) {
+	q := "SELECT DISTINCT status FROM tasks WHERE project_id = $1"
+
+	rows, err := r.db.QueryContext(ctx, q, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var status string
+
+		err = rows.Scan(&status)
+		if err != nil {
+			return nil, err
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	return statuses, rows.Err()
+}
+
+// TasksToRemind returns not done tasks due within the threshold that weren't reminded about yet.
+// Assignees receive reminders, the creator does if nobody is assigned.
+func (r *TaskRepository) TasksToRemind(ctx context.Context, threshold entity.ReminderThreshold, now time.Time) (reminders []entity.Reminder, err error) {
+	q := `SELECT t.id, t.name, t.due_at, u.email
+	FROM tasks t
+	    JOIN users u ON u.id = ANY(COALESCE(NULLIF(ARRAY(SELECT ta.user_id FROM task_assignees ta WHERE ta.task_id = t.id), '{}'), ARRAY[t.user_id]))
+	WHERE t.due_at <= $1 AND ($2 OR t.due_at > $3) AND NOT ` + taskDone + `
+	    AND NOT EXISTS(SELECT 1 FROM task_reminders tr WHERE tr.task_id = t.id AND tr.threshold = $4 AND tr.due_at = t.due_at
+	        AND tr.receiver IN ('', u.email))
+	ORDER BY t.id`
+
+	rows, err := r.db.QueryContext(ctx, q, now.Add(threshold.Before), threshold.Before == 0, now, threshold.Name)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			rm    entity.Reminder
+			email string
+		)
+
+		err = rows.Scan(&rm.TaskID, &rm.TaskName, &rm.DueAt, &email)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(reminders) == 0 || reminders[len(reminders)-1].TaskID != rm.TaskID {
+			rm.Threshold = threshold
+			reminders = append(reminders, rm)
+		}
+
+		last := &reminders[len(reminders)-1]
+		last.Receivers = append(last.Receivers, email)
+	}
+
+	return reminders, rows.Err()
+}
+
+// MarkReminder records the reminder as sent to the receiver, false means it has been sent already.
+func (r *TaskRepository) MarkReminder(ctx context.Context, rm entity.Reminder, receiver string) (bool, error) {
+	q := `INSERT INTO task_reminders(task_id, threshold, due_at, receiver, sent_at) VALUES ($1, $2, $3, $4, $5)
+	ON CONFLICT DO NOTHING`
+
+	res, err := r.db.ExecContext(ctx, q, rm.TaskID, rm.Threshold.Name, rm.DueAt, receiver, time.Now())
+	if err != nil {
+		return false, err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return n == 1, nil
+}
+
+// UnmarkReminder lets the reminder be sent to the receiver again, used when sending fails.
+func (r *TaskRepository) UnmarkReminder(ctx context.Context, rm entity.Reminder, receiver string) error {
+	q := "DELETE FROM task_reminders WHERE task_id = $1 AND threshold = $2 AND due_at = $3 AND receiver = $4"
+
+	_, err := r.db.ExecContext(ctx, q, rm.TaskID, rm.Threshold.Name, rm.DueAt, receiver)
+	return err
+}
+
+// tasks loads a page of tasks matching b and tq. Pages are keyset based: the cursor holds sort value
+// and id of the last task, so the next page starts right after it no matter what was inserted since.
+func (r *TaskRepository) tasks(ctx context.Context, b *queryBuilder, tq entity.TaskQuery) (entity.Page[entity.Task], error) {
+	if tq.DueFrom != nil {
+		b.and("t.due_at >= " + b.arg(*tq.DueFrom))
+	}
+
+	if tq.DueTo != nil {
+		b.and("t.due_at <= " + b.arg(*tq.DueTo))
+	}
+
+	fields, err := r.queryFields(ctx, tq)
+	if err != nil {
+		return entity.Page[entity.Task]{}, err
+	}
+
+	err = applyFilters(b, fields, tq.Filters)
+	if err != nil {
+		return entity.Page[entity.Task]{}, err
+	}
+
+	sort, err := taskSortFor(tq, fields)
+	if err != nil {
+		return entity.Page[entity.Task]{}, err
+	}
+
+	dir, cmp := "ASC", ">"
+	if tq.Desc {
+		dir, cmp = "DESC", "<"
+	}
+
+	sortName := sort.name + ":" + dir
+
+	if tq.Page.Cursor != "" {
+		c, err := decodeCursor(tq.Page.Cursor, sortName)
+		if err != nil {
+			return entity.Page[entity.Task]{}, err
+		}
+
+		b.and(fmt.Sprintf("(%s, t.id) %s (%s::%s, %s)", sort.expr, cmp, b.arg(c.Value), sort.typ, b.arg(c.ID)))
+	}
+
+	limit := tq.Page.Size()
+
+	q := fmt.Sprintf("SELECT %s, (%s)::text FROM tasks t%s ORDER BY %s %s, t.id %s LIMIT %d",
+		taskColumns, sort.expr, b.whereClause(), sort.expr, dir, dir, limit+1)
+
+	rows, err := r.db.QueryContext(ctx, q, b.args...)
+	if err != nil {
+		return entity.Page[entity.Task]{}, err
+	}
+	defer rows.Close()
+
+	var (
+		page   entity.Page[entity.Task]
+		values []string
+	)
+
+	for rows.Next() {
+		var value string
+
+		task, err := scanTask(rows, &value)
+		if err != nil {
+			return entity.Page[entity.Task]{}, err
+		}
+
+		page.Items = append(page.Items, task)
+		values = append(values, value)
+	}
+
+	if err = rows.Err(); err != nil {
+		return entity.Page[entity.Task]{}, err
+	}
+
+	if len(page.Items) > limit {
+		page.Items = page.Items[:limit]
+		page.NextCursor = encodeCursor(cursor{Sort: sortName, Value: values[limit-1], ID: page.Items[limit-1].ID})
+	}
+
+	err = r.loadDetails(ctx, page.Items)
+	if err != nil {
+		return entity.Page[entity.Task]{}, err
+	}
+
+	return page, nil
+}
+
+// loadDetails fills fields of the given tasks kept outside the tasks table.
+func (r *TaskRepository) loadDetails(ctx context.Context, tasks []entity.Task) error {
+	err := r.loadAssignees(ctx, tasks)
+	if err != nil {
+		return err
+	}
+
+	err = r.loadLabels(ctx, tasks)
+	if err != nil {
+		return err
+	}
+
+	err = r.loadDependencies(ctx, tasks)
+	if err != nil {
+		return err
+	}
+
+	err = r.loadCustomFields(ctx, tasks)
+	if err != nil {
+		return err
+	}
+
+	err = r.loadChecklistProgress(ctx, tasks)
+	if err != nil {
+		return err
+	}
+
+	return r.loadProgress(ctx, tasks)
+}
+
+// loadAssignees fills Assignees of the given tasks with one query.
+func (r *TaskRepository) loadAssignees(ctx context.Context, tasks []entity.Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, len(tasks))
+	index := make(map[int64]int, len(tasks))
+
+	for i, t := range tasks {
+		ids[i] = t.ID
+		index[t.ID] = i
+	}
+
+	q := "SELECT task_id, user_id FROM task_assignees WHERE task_id = ANY($1) ORDER BY user_id"
+
+	rows, err := r.db.QueryContext(ctx, q, pq.Array(ids))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var taskID, userID int64
+
+		err = rows.Scan(&taskID, &userID)
+		if err != nil {
+			return err
+		}
+
+		i := index[taskID]
+		tasks[i].Assignees = append(tasks[i].Assignees, userID)
+	}
+
+	return rows.Err()
+}
+
+// loadLabels fills Labels of the given tasks with one query.
+func (r *TaskRepository) loadLabels(ctx context.Context, tasks []entity.Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, len(tasks))
+	index := make(map[int64]int, len(tasks))
+
+	for i, t := range tasks {
+		ids[i] = t.ID
+		index[t.ID] = i
+	}
+
+	q := `SELECT tl.task_id, l.id, l.project_id, l.name, l.color
+	FROM task_labels tl JOIN labels l ON l.id = tl.label_id
+	WHERE tl.task_id = ANY($1) ORDER BY l.name, l.id`
+
+	rows, err := r.db.QueryContext(ctx, q, pq.Array(ids))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			taskID int64
+			l      entity.Label
+		)
+
+		err = rows.Scan(&taskID, &l.ID, &l.ProjectID, &l.Name, &l.Color)
+		if err != nil {
+			return err
+		}
+
+		i := index[taskID]
+		tasks[i].Labels = append(tasks[i].Labels, l)
+	}
+
+	return rows.Err()
+}
+
+// loadDependencies fills BlockedBy and Blocking of the given tasks with one query.
+func (r *TaskRepository) loadDependencies(ctx context.Context, tasks []entity.Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, len(tasks))
+	index := make(map[int64]int, len(tasks))
+
+	for i, t := range tasks {
+		ids[i] = t.ID
+		index[t.ID] = i
+	}
+
+	q := "SELECT task_id, blocker_id FROM task_dependencies WHERE task_id = ANY($1) OR blocker_id = ANY($1) ORDER BY task_id, blocker_id"
+
+	rows, err := r.db.QueryContext(ctx, q, pq.Array(ids))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var taskID, blockerID int64
+
+		err = rows.Scan(&taskID, &blockerID)
+		if err != nil {
+			return err
+		}
+
+		if i, ok := index[taskID]; ok {
+			tasks[i].BlockedBy = append(tasks[i].BlockedBy, blockerID)
+		}
+
+		if i, ok := index[blockerID]; ok {
+			tasks[i].Blocking = append(tasks[i].Blocking, taskID)
+		}
+	}
+
+	return rows.Err()
+}
+
+// loadCustomFields fills CustomFields of the given tasks with one query.
+func (r *TaskRepository) loadCustomFields(ctx context.Context, tasks []entity.Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, len(tasks))
+	index := make(map[int64]int, len(tasks))
+
+	for i, t := range tasks {
+		ids[i] = t.ID
+		index[t.ID] = i
+	}
+
+	q := "SELECT task_id, field_id, value FROM task_field_values WHERE task_id = ANY($1)"
+
+	rows, err := r.db.QueryContext(ctx, q, pq.Array(ids))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			taskID, fieldID int64
+			value           []byte
+		)
+
+		err = rows.Scan(&taskID, &fieldID, &value)
+		if err != nil {
+			return err
+		}
+
+		// jsonb is rendered with spaces, compact values compare equal to the ones being stored
+		var compact bytes.Buffer
+
+		err = json.Compact(&compact, value)
+		if err != nil {
+			return err
+		}
+
+		i := index[taskID]
+		if tasks[i].CustomFields == nil {
+			tasks[i].CustomFields = make(map[int64]json.RawMessage)
+		}
+
+		tasks[i].CustomFields[fieldID] = compact.Bytes()
+	}
+
+	return rows.Err()
+}
+
+// loadChecklistProgress fills Checklist of the given tasks with one query.
+func (r *TaskRepository) loadChecklistProgress(ctx context.Context, tasks []entity.Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, len(tasks))
+	index := make(map[int64]int, len(tasks))
+
+	for i, t := range tasks {
+		ids[i] = t.ID
+		index[t.ID] = i
+	}
+
+	q := "SELECT task_id, COUNT(*) FILTER (WHERE checked), COUNT(*) FROM checklist_items WHERE task_id = ANY($1) GROUP BY task_id"
+
+	rows, err := r.db.QueryContext(ctx, q, pq.Array(ids))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			taskID         int64
+			checked, total int
+		)
+
+		err = rows.Scan(&taskID, &checked, &total)
+		if err != nil {
+			return err
+		}
+
+		tasks[index[taskID]].Checklist = entity.NewChecklistProgress(checked, total)
+	}
+
+	return rows.Err()
+}
+
+// loadProgress fills Progress of the given tasks counting their subtasks at any depth with one query.
+func (r *TaskRepository) loadProgress(ctx context.Context, tasks []entity.Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, len(tasks))
+	index := make(map[int64]int, len(tasks))
+
+	for i, t := range tasks {
+		ids[i] = t.ID
+		index[t.ID] = i
+	}
+
+	q := `WITH RECURSIVE tree(root, id) AS (
+		SELECT parent_task_id, id FROM tasks WHERE parent_task_id = ANY($1)
+		UNION ALL
+		SELECT tree.root, t.id FROM tasks t JOIN tree ON t.parent_task_id = tree.id
+	)
+	SELECT tree.root, COUNT(*) FILTER (WHERE ` + taskDone + `), COUNT(*)
+	FROM tree JOIN tasks t ON t.id = tree.id
+	GROUP BY tree.root`
+
+	rows, err := r.db.QueryContext(ctx, q, pq.Array(ids))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			taskID   int64
+			progress entity.Progress
+		)
+
+		err = rows.Scan(&taskID, &progress.Done, &progress.Total)
+		if err != nil {
+			return err
+		}
+
+		tasks[index[taskID]].Progress = progress
+	}
+
+	return rows.Err()
+}
+
+// taskSort is an expression tasks are ordered by and its SQL type to cast cursor values to.
+type taskSort struct {
+	name string
+	expr string
+	typ  string
+}
+
+func taskSortFor(tq entity.TaskQuery, fields map[string]filterField) (taskSort, error) {
+	switch tq.Sort {
+	case "", entity.SortByCreatedAt:
+		return taskSort{name: entity.SortByCreatedAt, expr: "t.created_at", typ: "timestamptz"}, nil
+	case entity.SortByPriority:
+		return taskSort{name: entity.SortByPriority, expr: "t.priority", typ: "smallint"}, nil
+	case entity.SortByName:
+		return taskSort{name: entity.SortByName, expr: "t.name", typ: "text"}, nil
+	case entity.SortByRank:
+		return taskSort{name: entity.SortByRank, expr: "t.rank", typ: "text"}, nil
+	case entity.SortByDueAt:
+		// keep tasks without due date last in both directions
+		if tq.Desc {
+			return taskSort{name: entity.SortByDueAt, expr: "COALESCE(t.due_at, '-infinity')", typ: "timestamptz"}, nil
+		}
+
+		return taskSort{name: entity.SortByDueAt, expr: "COALESCE(t.due_at, 'infinity')", typ: "timestamptz"}, nil
+	}
+
+	field, ok := fields[tq.Sort]
+	if _, custom := customFieldID(tq.Sort); !ok || !custom {
+		return taskSort{}, fmt.Errorf("%w: unknown sort %q", entity.ErrBadRequest, tq.Sort)
+	}
+
+	// fields with many values per task, like multi select options, have no single value to sort by
+	if field.exists != "" {
+		return taskSort{}, fmt.Errorf("%w: tasks can't be sorted by %q", entity.ErrBadRequest, tq.Sort)
+	}
+
+	// like with due dates, tasks without a value go last, text values have no upper bound so they go first
+	switch field.typ {
+	case filterNumber:
+		if tq.Desc {
+			return taskSort{name: tq.Sort, expr: "COALESCE(" + field.expr + ", '-Infinity')", typ: "numeric"}, nil
+		}
+
+		return taskSort{name: tq.Sort, expr: "COALESCE(" + field.expr + ", 'Infinity')", typ: "numeric"}, nil
+	case filterDate:
+		if tq.Desc {
+			return taskSort{name: tq.Sort, expr: "COALESCE(" + field.expr + ", '-infinity')", typ: "date"}, nil
+		}
+
+		return taskSort{name: tq.Sort, expr: "COALESCE(" + field.expr + ", 'infinity')", typ: "date"}, nil
+	case filterText:
+		return taskSort{name: tq.Sort, expr: "COALESCE(" + field.expr + ", '')", typ: "text"}, nil
+	default:
+		return taskSort{}, fmt.Errorf("%w: tasks can't be sorted by %q", entity.ErrBadRequest, tq.Sort)
+	}
+}
+
+// queryFields returns taskFilterFields extended with custom fields the query filters or sorts by.
+func (r *TaskRepository) queryFields(ctx context.Context, tq entity.TaskQuery) (map[string]filterField, error) {
+	var ids []int64
+
+	for _, name := range append([]string{tq.Sort}, filterNames(tq.Filters)...) {
+		id, ok := customFieldID(name)
+		if ok {
+			ids = append(ids, id)
+		}
+	}
+
+	if len(ids) == 0 {
+		return taskFilterFields, nil
+	}
+
+	fields := maps.Clone(taskFilterFields)
+
+	q := "SELECT id, type FROM custom_fields WHERE id = ANY($1)"
+
+	rows, err := r.db.QueryContext(ctx, q, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			id  int64
+			typ string
+		)
+
+		err = rows.Scan(&id, &typ)
+		if err != nil {
+			return nil, err
+		}
+
+		fields[customFieldPrefix+strconv.FormatInt(id, 10)] = customFilterField(id, typ)
+	}
+
+	return fields, rows.Err()
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// scanTask reads a row selected with taskColumns, extra receives columns selected after them.
+func scanTask(s scanner, extra ...any) (t entity.Task, err error) {
+	dest := []any{&t.ID, &t.Name, &t.ProjectID, &t.ParentID, &t.Description, &t.UserID, &t.Status, &t.Priority, &t.DueAt, &t.Overdue, &t.Rank, &t.CreatedAt}
+
+	err = s.Scan(append(dest, extra...)...)
+	return t, err
+}
+
+// SetRecurrence makes the task a template repeated by rec, replacing its previous recurrence.
+func (r *TaskRepository) SetRecurrence(ctx context.Context, rec entity.Recurrence) error {
+	q := `INSERT INTO task_recurrences(task_id, rule, starts_at, next_at, occurrences, last_task_id) VALUES ($1, $2, $3, $4, $5, $6)
+	ON CONFLICT(task_id) DO UPDATE SET rule = $2, starts_at = $3, next_at = $4, occurrences = $5, last_task_id = $6`
+
+	_, err := r.db.ExecContext(ctx, q, rec.TaskID, rec.Rule, rec.StartsAt, rec.NextAt, rec.Occurrences, rec.LastTaskID)
+	return err
+}
+
+func (r *TaskRepository) Recurrence(ctx context.Context, taskID int64) (rec entity.Recurrence, err error) {
+	q := "SELECT task_id, rule, starts_at, next_at, occurrences, last_task_id FROM task_recurrences WHERE task_id = $1"
+
+	err = r.db.QueryRowContext(ctx, q, taskID).Scan(&rec.TaskID, &rec.Rule, &rec.StartsAt, &rec.NextAt, &rec.Occurrences, &rec.LastTaskID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entity.Recurrence{}, entity.ErrNotFound
+		}
+
+		return entity.Recurrence{}, err
+	}
+
+	return rec, nil
+}
+
+func (r *TaskRepository) DeleteRecurrence(ctx context.Context, taskID int64) error {
+	q := "DELETE FROM task_recurrences WHERE task_id = $1"
+
+	res, err := r.db.ExecContext(ctx, q, taskID)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return entity.ErrNotFound
+	}
+
+	return nil
+}
+
+// DueRecurrences returns recurrences whose next occurrence date has arrived or whose latest occurrence
+// is done or deleted.
+func (r *TaskRepository) DueRecurrences(ctx context.Context, now time.Time) (recs []entity.Recurrence, err error) {
+	q := `SELECT rc.task_id, rc.rule, rc.starts_at, rc.next_at, rc.occurrences, rc.last_task_id
+	FROM task_recurrences rc
+	    LEFT JOIN tasks t ON t.id = rc.last_task_id
+	WHERE rc.next_at IS NOT NULL AND (rc.next_at <= $1 OR t.id IS NULL OR ` + taskDone + `)
+	ORDER BY rc.task_id`
+
+	rows, err := r.db.QueryContext(ctx, q, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var rec entity.Recurrence
+
+		err = rows.Scan(&rec.TaskID, &rec.Rule, &rec.StartsAt, &rec.NextAt, &rec.Occurrences, &rec.LastTaskID)
+		if err != nil {
+			return nil, err
+		}
+
+		recs = append(recs, rec)
+	}
+
+	return recs, rows.Err()
+}
+
+// CreateOccurrence creates the next occurrence t of rec with its assignees and labels and advances rec
+// to nextAt with occurrences counted so far. It returns false without creating anything if rec has been
+// advanced concurrently.
+func (r *TaskRepository) CreateOccurrence(ctx context.Context, rec entity.Recurrence, t entity.Task, nextAt *time.Time, occurrences int) (entity.Task, bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return entity.Task{}, false, err
+	}
+	defer tx.Rollback()
+
+	t, err = insertTask(ctx, tx, t)
+	if err != nil {
+		return entity.Task{}, false, err
+	}
+
+	q := `UPDATE task_recurrences SET next_at = $1, occurrences = $2, last_task_id = $3
+	WHERE task_id = $4 AND next_at = $5 AND occurrences = $6`
+
+	res, err := tx.ExecContext(ctx, q, nextAt, occurrences, t.ID, rec.TaskID, rec.NextAt, rec.Occurrences)
+	if err != nil {
+		return entity.Task{}, false, err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return entity.Task{}, false, err
+	}
+
+	if n == 0 {
+		return entity.Task{}, false, nil
+	}
+
+	for _, userID := range t.Assignees {
+		_, err = tx.ExecContext(ctx, "INSERT INTO task_assignees(task_id, user_id) VALUES ($1, $2)", t.ID, userID)
+		if err != nil {
+			return entity.Task{}, false, err
+		}
+	}
+
+	for _, label := range t.Labels {
+		_, err = tx.ExecContext(ctx, "INSERT INTO task_labels(task_id, label_id) VALUES ($1, $2)", t.ID, label.ID)
+		if err != nil {
+			return entity.Task{}, false, err
+		}
+	}
+
+	return t, true, tx.Commit()
+}
+
+const checklistItemColumns = "id, task_id, text, checked, responsible_id, position, created_at"
+
+// AddChecklistItem appends the item to the end of the task checklist.
+func (r *TaskRepository) AddChecklistItem(ctx context.Context, item entity.ChecklistItem) (entity.ChecklistItem, error) {
+	q := `INSERT INTO checklist_items(task_id, text, checked, responsible_id, position, created_at)
+	SELECT $1, $2, $3, $4, COALESCE(MAX(position) + 1, 0), $5 FROM checklist_items WHERE task_id = $1
+	RETURNING id, position`
+
+	err := r.db.QueryRowContext(ctx, q, item.TaskID, item.Text, item.Checked, item.ResponsibleID, item.CreatedAt).Scan(&item.ID, &item.Position)
+	if err != nil {
+		return entity.ChecklistItem{}, err
+	}
+
+	return item, nil
+}
+
+// ChecklistItems returns the task checklist in order.
+func (r *TaskRepository) ChecklistItems(ctx context.Context, taskID int64) (items []entity.ChecklistItem, err error) {
+	q := "SELECT " + checklistItemColumns + " FROM checklist_items WHERE task_id = $1 ORDER BY position, id"
+
+	rows, err := r.db.QueryContext(ctx, q, taskID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		item, err := scanChecklistItem(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
+
+// ToggleChecklistItem flips checked state of the task item and returns the item as it is now.
+func (r *TaskRepository) ToggleChecklistItem(ctx context.Context, taskID int64, itemID int64) (entity.ChecklistItem, error) {
+	q := "UPDATE checklist_items SET checked = NOT checked WHERE id = $1 AND task_id = $2 RETURNING " + checklistItemColumns
+
+	item, err := scanChecklistItem(r.db.QueryRowContext(ctx, q, itemID, taskID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entity.ChecklistItem{}, entity.ErrNotFound
+		}
+
+		return entity.ChecklistItem{}, err
+	}
+
+	return item, nil
+}
+
+// ReorderChecklist puts items of the task in the order of ids.
+func (r *TaskRepository) ReorderChecklist(ctx context.Context, taskID int64, ids []int64) error {
+	q := `UPDATE checklist_items ci SET position = o.position - 1
+	FROM unnest($2::bigint[]) WITH ORDINALITY o(id, position)
+	WHERE ci.id = o.id AND ci.task_id = $1`
+
+	_, err := r.db.ExecContext(ctx, q, taskID, pq.Array(ids))
+	return err
+}
+
+func (r *TaskRepository) DeleteChecklistItem(ctx context.Context, taskID int64, itemID int64) error {
+	q := "DELETE FROM checklist_items WHERE id = $1 AND task_id = $2"
+
+	res, err := r.db.ExecContext(ctx, q, itemID, taskID)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return entity.ErrNotFound
+	}
+
+	return nil
+}
+
+func scanChecklistItem(s scanner) (item entity.ChecklistItem, err error) {
+	err = s.Scan(&item.ID, &item.TaskID, &item.Text, &item.Checked, &item.ResponsibleID, &item.Position, &item.CreatedAt)
+	return item, err
+}
@@ -0,0 +1,215 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"task-manager/entity"
+	"time"
+)
+
+const templateColumns = "id, user_id, name, statuses, transitions, tasks, created_at"
+
+type TemplateRepository struct {
+	db *sql.DB
+}
+
+func NewTemplateRepository(database *sql.DB) *TemplateRepository {
+	return &TemplateRepository{db: database}
+}
+
+func (r *TemplateRepository) CreateTemplate(ctx context.Context, tmpl entity.ProjectTemplate) (entity.ProjectTemplate, error) {
+	statuses, transitions, tasks, err := templateDocuments(tmpl)
+	if err != nil {
+		return entity.ProjectTemplate{}, err
+	}
+
+	q := `INSERT INTO project_templates(user_id, name, statuses, transitions, tasks, created_at)
+	VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`
+
+	err = r.db.QueryRowContext(ctx, q, tmpl.UserID, tmpl.Name, statuses, transitions, tasks, tmpl.CreatedAt).Scan(&tmpl.ID)
+	if err != nil {
+		return entity.ProjectTemplate{}, err
+	}
+
+	return tmpl, nil
+}
+
+func (r *TemplateRepository) TemplateByID(ctx context.Context, id int64) (entity.ProjectTemplate, error) {
+	q := "SELECT " + templateColumns + " FROM project_templates WHERE id = $1"
+
+	tmpl, err := scanTemplate(r.db.QueryRowContext(ctx, q, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entity.ProjectTemplate{}, entity.ErrNotFound
+		}
+
+		return entity.ProjectTemplate{}, err
+	}
+
+	return tmpl, nil
+}
+
+func (r *TemplateRepository) UserTemplates(ctx context.Context, userID int64, pr entity.PageRequest) (entity.Page[entity.ProjectTemplate], error) {
+	var b queryBuilder
+	b.and("user_id = " + b.arg(userID))
+
+	if pr.Cursor != "" {
+		c, err := decodeCursor(pr.Cursor, "")
+		if err != nil {
+			return entity.Page[entity.ProjectTemplate]{}, err
+		}
+
+		b.and("id > " + b.arg(c.ID))
+	}
+
+	limit := pr.Size()
+
+	q := fmt.Sprintf("SELECT %s FROM project_templates%s ORDER BY id LIMIT %d", templateColumns, b.whereClause(), limit+1)
+
+	rows, err := r.db.QueryContext(ctx, q, b.args...)
+	if err != nil {
+		return entity.Page[entity.ProjectTemplate]{}, err
+	}
+	defer rows.Close()
+
+	var page entity.Page[entity.ProjectTemplate]
+
+	for rows.Next() {
+		tmpl, err := scanTemplate(rows)
+		if err != nil {
+			return entity.Page[entity.ProjectTemplate]{}, err
+		}
+
+		page.Items = append(page.Items, tmpl)
+	}
+
+	if err = rows.Err(); err != nil {
+		return entity.Page[entity.ProjectTemplate]{}, err
+	}
+
+	if len(page.Items) > limit {
+		page.Items = page.Items[:limit]
+		page.NextCursor = encodeCursor(cursor{ID: page.Items[limit-1].ID})
+	}
+
+	return page, nil
+}
+
+func (r *TemplateRepository) DeleteTemplate(ctx context.Context, id int64) error {
+	q := "DELETE FROM project_templates WHERE id = $1"
+
+	res, err := r.db.ExecContext(ctx, q, id)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return entity.ErrNotFound
+	}
+
+	return nil
+}
+
+// CreateTemplateTasks adds tasks of the template to the project in one transaction, statuses of tasks
+// must exist in the project workflow. Tasks are returned in template order.
+func (r *TemplateRepository) CreateTemplateTasks(ctx context.Context, projectID int64, userID int64, tasks []entity.TemplateTask, createdAt time.Time) ([]entity.Task, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	created, err := insertTemplateTasks(ctx, tx, projectID, userID, tasks, createdAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return created, tx.Commit()
+}
+
+// insertTemplateTasks inserts template tasks in their order, so they are ranked in it, and then links subtasks
+// to their parents.
+func insertTemplateTasks(ctx context.Context, tx *sql.Tx, projectID int64, userID int64, tasks []entity.TemplateTask, createdAt time.Time) ([]entity.Task, error) {
+	created := make([]entity.Task, len(tasks))
+
+	for i, tt := range tasks {
+		t, err := insertTask(ctx, tx, entity.Task{
+			Name:        tt.Name,
+			Description: tt.Description,
+			UserID:      userID,
+			ProjectID:   projectID,
+			Status:      tt.Status,
+			Priority:    tt.Priority,
+			CreatedAt:   createdAt,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		created[i] = t
+	}
+
+	q := "UPDATE tasks SET parent_task_id = $1 WHERE id = $2"
+
+	for i, tt := range tasks {
+		if tt.Parent == nil {
+			continue
+		}
+
+		parentID := created[*tt.Parent].ID
+
+		_, err := tx.ExecContext(ctx, q, parentID, created[i].ID)
+		if err != nil {
+			return nil, err
+		}
+
+		created[i].ParentID = &parentID
+	}
+
+	return created, nil
+}
+
+func templateDocuments(tmpl entity.ProjectTemplate) (statuses []byte, transitions []byte, tasks []byte, err error) {
+	statuses, err = json.Marshal(tmpl.Statuses)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	transitions, err = json.Marshal(tmpl.Transitions)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	tasks, err = json.Marshal(tmpl.Tasks)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return statuses, transitions, tasks, nil
+}
+
+func scanTemplate(s scanner) (tmpl entity.ProjectTemplate, err error) {
+	var statuses, transitions, tasks []byte
+
+	err = s.Scan(&tmpl.ID, &tmpl.UserID, &tmpl.Name, &statuses, &transitions, &tasks, &tmpl.CreatedAt)
+	if err != nil {
+		return entity.ProjectTemplate{}, err
+	}
+
+	for dest, doc := range map[any][]byte{&tmpl.Statuses: statuses, &tmpl.Transitions: transitions, &tmpl.Tasks: tasks} {
+		err = json.Unmarshal(doc, dest)
+		if err != nil {
+			return entity.ProjectTemplate{}, err
+		}
+	}
+
+	return tmpl, nil
+}
@@ -0,0 +1,208 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"task-manager/entity"
+	"time"
+)
+
+const timeEntryColumns = "e.id, e.task_id, e.user_id, e.started_at, e.ended_at, " +
+	"EXTRACT(EPOCH FROM COALESCE(e.ended_at, NOW()) - e.started_at)::bigint, e.note, e.created_at"
+
+type TimeRepository struct {
+	db *sql.DB
+}
+
+func NewTimeRepository(database *sql.DB) *TimeRepository {
+	return &TimeRepository{db: database}
+}
+
+// StartTimer inserts a running entry, ErrBadRequest means the user already has a running timer.
+func (r *TimeRepository) StartTimer(ctx context.Context, e entity.TimeEntry) (entity.TimeEntry, error) {
+	q := "INSERT INTO time_entries(task_id, user_id, started_at, note, created_at) VALUES ($1, $2, $3, $4, $5) RETURNING id"
+
+	err := r.db.QueryRowContext(ctx, q, e.TaskID, e.UserID, e.StartedAt, e.Note, e.CreatedAt).Scan(&e.ID)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return entity.TimeEntry{}, fmt.Errorf("%w: a timer is already running", entity.ErrBadRequest)
+		}
+
+		return entity.TimeEntry{}, err
+	}
+
+	return e, nil
+}
+
+// StopTimer ends the running timer of the user at the given time, ErrNotFound if there is none.
+func (r *TimeRepository) StopTimer(ctx context.Context, userID int64, at time.Time) (entity.TimeEntry, error) {
+	q := "UPDATE time_entries e SET ended_at = GREATEST($1, e.started_at) WHERE e.user_id = $2 AND e.ended_at IS NULL RETURNING " + timeEntryColumns
+
+	e, err := scanTimeEntry(r.db.QueryRowContext(ctx, q, at, userID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entity.TimeEntry{}, entity.ErrNotFound
+		}
+
+		return entity.TimeEntry{}, err
+	}
+
+	return e, nil
+}
+
+func (r *TimeRepository) RunningTimer(ctx context.Context, userID int64) (entity.TimeEntry, error) {
+	q := "SELECT " + timeEntryColumns + " FROM time_entries e WHERE e.user_id = $1 AND e.ended_at IS NULL"
+
+	e, err := scanTimeEntry(r.db.QueryRowContext(ctx, q, userID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entity.TimeEntry{}, entity.ErrNotFound
+		}
+
+		return entity.TimeEntry{}, err
+	}
+
+	return e, nil
+}
+
+func (r *TimeRepository) CreateTimeEntry(ctx context.Context, e entity.TimeEntry) (entity.TimeEntry, error) {
+	q := "INSERT INTO time_entries(task_id, user_id, started_at, ended_at, note, created_at) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id"
+
+	err := r.db.QueryRowContext(ctx, q, e.TaskID, e.UserID, e.StartedAt, e.EndedAt, e.Note, e.CreatedAt).Scan(&e.ID)
+	if err != nil {
+		return entity.TimeEntry{}, err
+	}
+
+	return e, nil
+}
+
+func (r *TimeRepository) TimeEntryByID(ctx context.Context, id int64) (entity.TimeEntry, error) {
+	q := "SELECT " + timeEntryColumns + " FROM time_entries e WHERE e.id = $1"
+
+	e, err := scanTimeEntry(r.db.QueryRowContext(ctx, q, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entity.TimeEntry{}, entity.ErrNotFound
+		}
+
+		return entity.TimeEntry{}, err
+	}
+
+	return e, nil
+}
+
+// TaskTimeEntries returns time entries of the task, the oldest first.
+func (r *TimeRepository) TaskTimeEntries(ctx context.Context, taskID int64, pr entity.PageRequest) (entity.Page[entity.TimeEntry], error) {
+	var b queryBuilder
+	b.and("e.task_id = " + b.arg(taskID))
+
+	if pr.Cursor != "" {
+		c, err := decodeCursor(pr.Cursor, "")
+		if err != nil {
+			return entity.Page[entity.TimeEntry]{}, err
+		}
+
+		b.and("e.id > " + b.arg(c.ID))
+	}
+
+	limit := pr.Size()
+
+	q := fmt.Sprintf("SELECT %s FROM time_entries e%s ORDER BY e.id LIMIT %d", timeEntryColumns, b.whereClause(), limit+1)
+
+	rows, err := r.db.QueryContext(ctx, q, b.args...)
+	if err != nil {
+		return entity.Page[entity.TimeEntry]{}, err
+	}
+	defer rows.Close()
+
+	var page entity.Page[entity.TimeEntry]
+
+	for rows.Next() {
+		e, err := scanTimeEntry(rows)
+		if err != nil {
+			return entity.Page[entity.TimeEntry]{}, err
+		}
+
+		page.Items = append(page.Items, e)
+	}
+
+	if err = rows.Err(); err != nil {
+		return entity.Page[entity.TimeEntry]{}, err
+	}
+
+	if len(page.Items) > limit {
+		page.Items = page.Items[:limit]
+		page.NextCursor = encodeCursor(cursor{ID: page.Items[limit-1].ID})
+	}
+
+	return page, nil
+}
+
+func (r *TimeRepository) DeleteTimeEntry(ctx context.Context, id int64) error {
+	q := "DELETE FROM time_entries WHERE id = $1"
+
+	res, err := r.db.ExecContext(ctx, q, id)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return entity.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *TimeRepository) TaskTimeTotal(ctx context.Context, taskID int64, tq entity.TimeQuery) (entity.TimeTotal, error) {
+	var b queryBuilder
+	b.and("e.task_id = " + b.arg(taskID))
+
+	return r.total(ctx, "", &b, tq)
+}
+
+func (r *TimeRepository) ProjectTimeTotal(ctx context.Context, projectID int64, tq entity.TimeQuery) (entity.TimeTotal, error) {
+	var b queryBuilder
+	b.and("t.project_id = " + b.arg(projectID))
+
+	return r.total(ctx, " JOIN tasks t ON t.id = e.task_id", &b, tq)
+}
+
+// UserTimeTotal sums up time the user spent on tasks of all projects.
+func (r *TimeRepository) UserTimeTotal(ctx context.Context, userID int64, tq entity.TimeQuery) (entity.TimeTotal, error) {
+	tq.UserID = &userID
+
+	return r.total(ctx, "", &queryBuilder{}, tq)
+}
+
+// total sums up entries started within the query range, running timers count up to now.
+func (r *TimeRepository) total(ctx context.Context, join string, b *queryBuilder, tq entity.TimeQuery) (total entity.TimeTotal, err error) {
+	if tq.From != nil {
+		b.and("e.started_at >= " + b.arg(*tq.From))
+	}
+
+	if tq.To != nil {
+		b.and("e.started_at < " + b.arg(*tq.To))
+	}
+
+	if tq.UserID != nil {
+		b.and("e.user_id = " + b.arg(*tq.UserID))
+	}
+
+	q := fmt.Sprintf(`SELECT COALESCE(SUM(EXTRACT(EPOCH FROM COALESCE(e.ended_at, NOW()) - e.started_at)), 0)::bigint, COUNT(*)
+	FROM time_entries e%s%s`, join, b.whereClause())
+
+	err = r.db.QueryRowContext(ctx, q, b.args...).Scan(&total.Seconds, &total.Entries)
+	return total, err
+}
+
+func scanTimeEntry(s scanner) (e entity.TimeEntry, err error) {
+	err = s.Scan(&e.ID, &e.TaskID, &e.UserID, &e.StartedAt, &e.EndedAt, &e.Duration, &e.Note, &e.CreatedAt)
+	return e, err
+}
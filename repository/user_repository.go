@@ -4,6 +4,9 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+	"github.com/lib/pq"
+	"strings"
 	"task-manager/entity"
 	"time"
 )
@@ -78,30 +81,56 @@ func (r *UserRepository) UsersToSendVIP(ctx context.Context) (users []entity.Use
 	return users, nil
 }
 
-func (r *UserRepository) ProjectUsers(ctx context.Context, projectID int64) (users []entity.User, err error) {
-	q := `SELECT u.id, u.name, u.email, u.created_at, u.is_verified, u.vip_status
+func (r *UserRepository) ProjectUsers(ctx context.Context, projectID int64, pr entity.PageRequest) (entity.Page[entity.ProjectMember], error) {
+	var b queryBuilder
+	b.and("pu.project_id = " + b.arg(projectID))
+
+	if pr.Cursor != "" {
+		c, err := decodeCursor(pr.Cursor, "")
+		if err != nil {
+			return entity.Page[entity.ProjectMember]{}, err
+		}
+
+		b.and("u.id > " + b.arg(c.ID))
+	}
+
+	limit := pr.Size()
+
+	q := fmt.Sprintf(`SELECT u.id, u.name, u.email, u.created_at, u.is_verified, u.vip_status, pu.role
 	FROM users u
-	    JOIN projects_users pu ON pu.user_id = u.id
-	WHERE pu.project_id = $1`
+	    JOIN projects_users pu ON pu.user_id = u.id%s
+	ORDER BY u.id
+	LIMIT %d`, b.whereClause(), limit+1)
 
-	rows, err := r.db.QueryContext(ctx, q, projectID)
+	rows, err := r.db.QueryContext(ctx, q, b.args...)
 	if err != nil {
-		return nil, err
+		return entity.Page[entity.ProjectMember]{}, err
 	}
 	defer rows.Close()
 
+	var page entity.Page[entity.ProjectMember]
+
 	for rows.Next() {
-		var user entity.User
+		var m entity.ProjectMember
 
-		err = rows.Scan(&user.ID, &user.Name, &user.Email, &user.CreatedAt, &user.IsVerified, &user.VipStatus)
+		err = rows.Scan(&m.ID, &m.Name, &m.Email, &m.CreatedAt, &m.IsVerified, &m.VipStatus, &m.Role)
 		if err != nil {
-			return nil, err
+			return entity.Page[entity.ProjectMember]{}, err
 		}
 
-		users = append(users, user)
+		page.Items = append(page.Items, m)
 	}
 
-	return users, nil
+	if err = rows.Err(); err != nil {
+		return entity.Page[entity.ProjectMember]{}, err
+	}
+
+	if len(page.Items) > limit {
+		page.Items = page.Items[:limit]
+		page.NextCursor = encodeCursor(cursor{ID: page.Items[limit-1].ID})
+	}
+
+	return page, nil
 }
 
 func (r *UserRepository) MarkNotification(ctx context.Context, email string, subject string) error {
@@ -131,3 +160,40 @@ func (r *UserRepository) MarkNotification(ctx context.Context, email string, sub
 
 	return nil
 }
+
+// MembersByNames returns members of the project whose names match any of names ignoring case.
+func (r *UserRepository) MembersByNames(ctx context.Context, projectID int64, names []string) (users []entity.User, err error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	lower := make([]string, len(names))
+	for i, name := range names {
+		lower[i] = strings.ToLower(name)
+	}
+
+	q := `SELECT u.id, u.name, u.email, u.created_at, u.is_verified, u.vip_status
+	FROM users u
+	    JOIN projects_users pu ON pu.user_id = u.id
+	WHERE pu.project_id = $1 AND lower(u.name) = ANY($2)
+	ORDER BY u.id`
+
+	rows, err := r.db.QueryContext(ctx, q, projectID, pq.Array(lower))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var u entity.User
+
+		err = rows.Scan(&u.ID, &u.Name, &u.Email, &u.CreatedAt, &u.IsVerified, &u.VipStatus)
+		if err != nil {
+			return nil, err
+		}
+
+		users = append(users, u)
+	}
+
+	return users, rows.Err()
+}
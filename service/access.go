@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"task-manager/entity"
+)
+
+// projectAccess decides what the authenticated user may do inside a project
+// by looking up their membership role in the entity permission matrix.
+type projectAccess struct {
+	project ProjectRepository
+	task    TaskRepository
+}
+
+// authorize returns the project if the authenticated user's role grants perm.
+func (pa projectAccess) authorize(ctx context.Context, projectID int64, perm entity.Permission) (entity.Project, error) {
+	project, role, err := pa.role(ctx, projectID)
+	if err != nil {
+		return entity.Project{}, err
+	}
+
+	if !role.Can(perm) {
+		return entity.Project{}, fmt.Errorf("%w: %s role has no %s permission", entity.ErrForbidden, role, perm)
+	}
+
+	return project, nil
+}
+
+// authorizeTask returns the task if the authenticated user's role in its project grants perm.
+func (pa projectAccess) authorizeTask(ctx context.Context, id int64, perm entity.Permission) (entity.Task, error) {
+	task, err := pa.task.TaskByID(ctx, id)
+	if err != nil {
+		return entity.Task{}, err
+	}
+
+	_, err = pa.authorize(ctx, task.ProjectID, perm)
+	if err != nil {
+		return entity.Task{}, err
+	}
+
+	return task, nil
+}
+
+// role returns the project together with the authenticated user's role in it.
+func (pa projectAccess) role(ctx context.Context, projectID int64) (entity.Project, entity.Role, error) {
+	user := entity.AuthUser(ctx)
+
+	project, err := pa.project.ProjectByID(ctx, projectID)
+	if err != nil {
+		return entity.Project{}, "", err
+	}
+
+	role, err := pa.project.MemberRole(ctx, projectID, user.ID)
+	if err != nil {
+		if errors.Is(err, entity.ErrNotFound) {
+			return entity.Project{}, "", fmt.Errorf("%w: not a member of the project", entity.ErrForbidden)
+		}
+
+		return entity.Project{}, "", err
+	}
+
+	return project, role, nil
+}
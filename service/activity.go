@@ -0,0 +1,25 @@
+package service
+
+import (
+	"context"
+	"task-manager/entity"
+	"time"
+)
+
+type ActivityRepository interface {
+	AddActivity(ctx context.Context, a entity.Activity) error
+	ProjectActivity(ctx context.Context, projectID int64, pr entity.PageRequest) (entity.Page[entity.Activity], error)
+	UserActivity(ctx context.Context, userID int64, pr entity.PageRequest) (entity.Page[entity.Activity], error)
+}
+
+// recordActivity stamps the activity with the actor and time and adds it to the feed. The activity has already
+// happened by then, so a failure is logged rather than failing the request.
+func recordActivity(ctx context.Context, activity ActivityRepository, actorID int64, a entity.Activity) {
+	a.ActorID = &actorID
+	a.CreatedAt = time.Now()
+
+	err := activity.AddActivity(ctx, a)
+	if err != nil {
+		entity.CtxLogger(ctx).Error("Activity record error", "project_id", a.ProjectID, "type", a.Type, "error", err)
+	}
+}
@@ -0,0 +1,211 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"task-manager/entity"
+	"time"
+)
+
+type AttachmentRepository interface {
+	CreateAttachment(ctx context.Context, a entity.Attachment) (entity.Attachment, error)
+	AttachmentByID(ctx context.Context, id int64) (a entity.Attachment, err error)
+	TaskAttachments(ctx context.Context, taskID int64) (attachments []entity.Attachment, err error)
+	DeleteAttachment(ctx context.Context, id int64) error
+
+	BlobsToDelete(ctx context.Context, limit int) (keys []string, err error)
+	MarkBlobDeleted(ctx context.Context, key string) error
+}
+
+// BlobStorage keeps attachment content, metadata lives in AttachmentRepository.
+type BlobStorage interface {
+	Put(ctx context.Context, key string, r io.Reader) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+type AttachmentService struct {
+	attachment AttachmentRepository
+	storage    BlobStorage
+	access     projectAccess
+}
+
+func NewAttachmentService(attachment AttachmentRepository, storage BlobStorage, project ProjectRepository, task TaskRepository) *AttachmentService {
+	return &AttachmentService{
+		attachment: attachment,
+		storage:    storage,
+		access:     projectAccess{project: project, task: task},
+	}
+}
+
+func (as *AttachmentService) TaskAttachments(ctx context.Context, taskID int64) ([]entity.Attachment, error) {
+	_, err := as.access.authorizeTask(ctx, taskID, entity.PermViewProject)
+	if err != nil {
+		return nil, err
+	}
+
+	return as.attachment.TaskAttachments(ctx, taskID)
+}
+
+// UploadAttachment streams r to the blob storage and records it as an attachment of the task.
+// The content type is detected from the content, files of other types or over the size limit are rejected.
+func (as *AttachmentService) UploadAttachment(ctx context.Context, taskID int64, name string, r io.Reader) (entity.Attachment, error) {
+	name = filepath.Base(name)
+	if name == "." || name == string(filepath.Separator) {
+		return entity.Attachment{}, fmt.Errorf("%w: empty file name", entity.ErrBadRequest)
+	}
+
+	_, err := as.access.authorizeTask(ctx, taskID, entity.PermEditTasks)
+	if err != nil {
+		return entity.Attachment{}, err
+	}
+
+	head := make([]byte, 512)
+
+	n, err := io.ReadFull(r, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return entity.Attachment{}, err
+	}
+
+	head = head[:n]
+
+	contentType, _, err := mime.ParseMediaType(http.DetectContentType(head))
+	if err != nil {
+		return entity.Attachment{}, err
+	}
+
+	err = entity.ValidateAttachmentType(contentType)
+	if err != nil {
+		return entity.Attachment{}, err
+	}
+
+	key := uuid.NewString()
+	content := &countingReader{r: io.LimitReader(io.MultiReader(bytes.NewReader(head), r), entity.MaxAttachmentSize+1)}
+
+	err = as.storage.Put(ctx, key, content)
+	if err != nil {
+		return entity.Attachment{}, err
+	}
+
+	if content.n > entity.MaxAttachmentSize {
+		err = fmt.Errorf("%w: file is larger than %d bytes", entity.ErrBadRequest, entity.MaxAttachmentSize)
+		return entity.Attachment{}, errors.Join(err, as.storage.Delete(ctx, key))
+	}
+
+	user := entity.AuthUser(ctx)
+
+	attachment, err := as.attachment.CreateAttachment(ctx, entity.Attachment{
+		TaskID:      taskID,
+		UserID:      user.ID,
+		Name:        name,
+		ContentType: contentType,
+		Size:        content.n,
+		StorageKey:  key,
+		CreatedAt:   time.Now(),
+	})
+	if err != nil {
+		return entity.Attachment{}, errors.Join(err, as.storage.Delete(ctx, key))
+	}
+
+	return attachment, nil
+}
+
+// DownloadAttachment returns the attachment with its content, the caller closes the content.
+func (as *AttachmentService) DownloadAttachment(ctx context.Context, taskID int64, attachmentID int64) (entity.Attachment, io.ReadCloser, error) {
+	attachment, err := as.taskAttachment(ctx, taskID, attachmentID)
+	if err != nil {
+		return entity.Attachment{}, nil, err
+	}
+
+	content, err := as.storage.Get(ctx, attachment.StorageKey)
+	if err != nil {
+		return entity.Attachment{}, nil, err
+	}
+
+	return attachment, content, nil
+}
+
+// DeleteAttachment deletes the attachment, uploaders delete their own files, otherwise PermDeleteTasks is required.
+// The blob is removed by DeleteBlobs later.
+func (as *AttachmentService) DeleteAttachment(ctx context.Context, taskID int64, attachmentID int64) error {
+	attachment, err := as.taskAttachment(ctx, taskID, attachmentID)
+	if err != nil {
+		return err
+	}
+
+	user := entity.AuthUser(ctx)
+
+	if attachment.UserID != user.ID {
+		_, err = as.access.authorizeTask(ctx, taskID, entity.PermDeleteTasks)
+		if err != nil {
+			return err
+		}
+	}
+
+	return as.attachment.DeleteAttachment(ctx, attachmentID)
+}
+
+// DeleteBlobs removes blobs of deleted attachments from the storage, whether the attachment was deleted
+// on its own or along with its task, project or uploader.
+func (as *AttachmentService) DeleteBlobs(ctx context.Context, l slog.Logger) error {
+	keys, err := as.attachment.BlobsToDelete(ctx, 100)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		err = as.storage.Delete(ctx, key)
+		if err != nil {
+			return err
+		}
+
+		err = as.attachment.MarkBlobDeleted(ctx, key)
+		if err != nil {
+			return err
+		}
+
+		l.Info("Attachment blob deleted", "key", key)
+	}
+
+	return nil
+}
+
+// taskAttachment returns the attachment if it belongs to the task and the user is a member of the task project.
+func (as *AttachmentService) taskAttachment(ctx context.Context, taskID int64, attachmentID int64) (entity.Attachment, error) {
+	_, err := as.access.authorizeTask(ctx, taskID, entity.PermViewProject)
+	if err != nil {
+		return entity.Attachment{}, err
+	}
+
+	attachment, err := as.attachment.AttachmentByID(ctx, attachmentID)
+	if err != nil {
+		return entity.Attachment{}, err
+	}
+
+	if attachment.TaskID != taskID {
+		return entity.Attachment{}, entity.ErrNotFound
+	}
+
+	return attachment, nil
+}
+
+// countingReader counts bytes read through it.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+
+	return n, err
+}
@@ -0,0 +1,200 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/segmentio/kafka-go"
+	"strings"
+	"task-manager/entity"
+	"time"
+)
+
+type CommentRepository interface {
+	CreateComment(ctx context.Context, c entity.Comment) (entity.Comment, error)
+	CommentByID(ctx context.Context, id int64) (c entity.Comment, err error)
+	TaskComments(ctx context.Context, taskID int64, pr entity.PageRequest) (entity.Page[entity.Comment], error)
+	UpdateComment(ctx context.Context, id int64, body string, updatedAt time.Time) error
+	DeleteComment(ctx context.Context, id int64) error
+}
+
+type CommentService struct {
+	comment CommentRepository
+	user    UserRepository
+	kafka   *kafka.Conn
+	access  projectAccess
+}
+
+func NewCommentService(comment CommentRepository, project ProjectRepository, task TaskRepository, user UserRepository, kafkaConn *kafka.Conn) *CommentService {
+	return &CommentService{
+		comment: comment,
+		user:    user,
+		kafka:   kafkaConn,
+		access:  projectAccess{project: project, task: task},
+	}
+}
+
+func (cs *CommentService) TaskComments(ctx context.Context, taskID int64, pr entity.PageRequest) (entity.Page[entity.Comment], error) {
+	_, err := cs.access.authorizeTask(ctx, taskID, entity.PermViewProject)
+	if err != nil {
+		return entity.Page[entity.Comment]{}, err
+	}
+
+	return cs.comment.TaskComments(ctx, taskID, pr)
+}
+
+// CreateComment adds a comment to the task, any project member may comment.
+// Project members mentioned as @name are notified.
+func (cs *CommentService) CreateComment(ctx context.Context, taskID int64, body string) (entity.Comment, error) {
+	err := entity.ValidateCommentBody(body)
+	if err != nil {
+		return entity.Comment{}, err
+	}
+
+	task, err := cs.access.authorizeTask(ctx, taskID, entity.PermViewProject)
+	if err != nil {
+		return entity.Comment{}, err
+	}
+
+	user := entity.AuthUser(ctx)
+
+	comment, err := cs.comment.CreateComment(ctx, entity.Comment{
+		TaskID:    taskID,
+		UserID:    user.ID,
+		Body:      body,
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		return entity.Comment{}, err
+	}
+
+	// the comment is saved already, a failed notification doesn't undo it
+	err = cs.notifyMentioned(ctx, task, comment, "")
+	if err != nil {
+		entity.CtxLogger(ctx).Error("Notification error", "task_id", task.ID, "comment_id", comment.ID, "error", err)
+	}
+
+	return comment, nil
+}
+
+// UpdateComment changes the text of the comment, only its author may do it.
+// Members mentioned for the first time are notified.
+func (cs *CommentService) UpdateComment(ctx context.Context, taskID int64, commentID int64, body string) (entity.Comment, error) {
+	err := entity.ValidateCommentBody(body)
+	if err != nil {
+		return entity.Comment{}, err
+	}
+
+	task, comment, err := cs.taskComment(ctx, taskID, commentID)
+	if err != nil {
+		return entity.Comment{}, err
+	}
+
+	user := entity.AuthUser(ctx)
+
+	if comment.UserID != user.ID {
+		return entity.Comment{}, fmt.Errorf("%w: only the author may edit the comment", entity.ErrForbidden)
+	}
+
+	if comment.Body == body {
+		return comment, nil
+	}
+
+	previous := comment.Body
+	now := time.Now()
+
+	err = cs.comment.UpdateComment(ctx, commentID, body, now)
+	if err != nil {
+		return entity.Comment{}, err
+	}
+
+	comment.Body = body
+	comment.UpdatedAt = &now
+
+	err = cs.notifyMentioned(ctx, task, comment, previous)
+	if err != nil {
+		entity.CtxLogger(ctx).Error("Notification error", "task_id", task.ID, "comment_id", comment.ID, "error", err)
+	}
+
+	return comment, nil
+}
+
+// DeleteComment removes the comment, authors delete their own comments, otherwise PermDeleteTasks is required.
+func (cs *CommentService) DeleteComment(ctx context.Context, taskID int64, commentID int64) error {
+	task, comment, err := cs.taskComment(ctx, taskID, commentID)
+	if err != nil {
+		return err
+	}
+
+	user := entity.AuthUser(ctx)
+
+	if comment.UserID != user.ID {
+		_, err = cs.access.authorize(ctx, task.ProjectID, entity.PermDeleteTasks)
+		if err != nil {
+			return err
+		}
+	}
+
+	return cs.comment.DeleteComment(ctx, commentID)
+}
+
+// taskComment returns the comment if it belongs to the task and the user is a member of the task project.
+func (cs *CommentService) taskComment(ctx context.Context, taskID int64, commentID int64) (entity.Task, entity.Comment, error) {
+	task, err := cs.access.authorizeTask(ctx, taskID, entity.PermViewProject)
+	if err != nil {
+		return entity.Task{}, entity.Comment{}, err
+	}
+
+	comment, err := cs.comment.CommentByID(ctx, commentID)
+	if err != nil {
+		return entity.Task{}, entity.Comment{}, err
+	}
+
+	if comment.TaskID != taskID {
+		return entity.Task{}, entity.Comment{}, entity.ErrNotFound
+	}
+
+	return task, comment, nil
+}
+
+// notifyMentioned notifies project members mentioned in the comment but not in its previous text.
+// The author isn't notified about mentioning themselves.
+func (cs *CommentService) notifyMentioned(ctx context.Context, task entity.Task, comment entity.Comment, previous string) error {
+	already := make(map[string]bool)
+	for _, name := range entity.Mentions(previous) {
+		already[strings.ToLower(name)] = true
+	}
+
+	var names []string
+
+	for _, name := range entity.Mentions(comment.Body) {
+		if !already[strings.ToLower(name)] {
+			names = append(names, name)
+		}
+	}
+
+	members, err := cs.user.MembersByNames(ctx, task.ProjectID, names)
+	if err != nil {
+		return err
+	}
+
+	author := entity.AuthUser(ctx)
+
+	for _, member := range members {
+		if member.ID == comment.UserID {
+			continue
+		}
+
+		ntf := entity.Notification{
+			Subject:  "comment mention",
+			Receiver: member.Email,
+			Message:  fmt.Sprintf("%s mentioned you in a comment on task %q: %s", author.Name, task.Name, comment.Body),
+		}
+
+		err = sendNotification(ctx, cs.kafka, ntf)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
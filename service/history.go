@@ -0,0 +1,36 @@
+package service
+
+import (
+	"context"
+	"task-manager/entity"
+	"time"
+)
+
+type HistoryRepository interface {
+	AddChanges(ctx context.Context, changes []entity.Change) error
+	TaskHistory(ctx context.Context, taskID int64, pr entity.PageRequest) (entity.Page[entity.Change], error)
+	ProjectHistory(ctx context.Context, projectID int64, pr entity.PageRequest) (entity.Page[entity.Change], error)
+}
+
+// recordChanges stamps changes with the actor, project, task and time and appends them to the history.
+// taskID is nil for changes of the project itself. The changes are already applied by then, so a failure
+// is logged rather than failing the request.
+func recordChanges(ctx context.Context, history HistoryRepository, actorID int64, projectID int64, taskID *int64, changes ...entity.Change) {
+	if len(changes) == 0 {
+		return
+	}
+
+	now := time.Now()
+
+	for i := range changes {
+		changes[i].ProjectID = projectID
+		changes[i].TaskID = taskID
+		changes[i].ActorID = &actorID
+		changes[i].CreatedAt = now
+	}
+
+	err := history.AddChanges(ctx, changes)
+	if err != nil {
+		entity.CtxLogger(ctx).Error("History record error", "project_id", projectID, "task_id", taskID, "error", err)
+	}
+}
@@ -0,0 +1,35 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/segmentio/kafka-go"
+	"task-manager/entity"
+)
+
+// sendNotification writes notification to Kafka, where it's picked up by the mail sender.
+func sendNotification(_ context.Context, conn *kafka.Conn, ntf entity.Notification) error {
+	message := map[string]string{
+		"subject":  fmt.Sprintf("New notification: %s", ntf.Subject),
+		"receiver": ntf.Receiver,
+		"message":  ntf.Message,
+	}
+
+	b, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	msg := kafka.Message{
+		Key:   []byte("notification"),
+		Value: b,
+	}
+
+	_, err = conn.WriteMessages(msg)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -1,11 +1,17 @@
 package service
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/google/uuid"
 	"github.com/segmentio/kafka-go"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
 	"task-manager/entity"
 	"time"
 )
@@ -13,35 +19,92 @@ import (
 type TaskRepository interface {
 	CreateTask(ctx context.Context, t entity.Task) (entity.Task, error)
 	TaskByID(ctx context.Context, id int64) (t entity.Task, err error)
-	ProjectTasks(ctx context.Context, projectID int64) (tasks []entity.Task, err error)
-	UserTasks(ctx context.Context, userID int64) (tasks []entity.Task, err error)
+	ProjectTasks(ctx context.Context, projectID int64, tq entity.TaskQuery) (entity.Page[entity.Task], error)
+	BoardTasks(ctx context.Context, projectID int64) (tasks []entity.Task, err error)
+	UserTasks(ctx context.Context, userID int64, tq entity.TaskQuery) (entity.Page[entity.Task], error)
+	AssignedTasks(ctx context.Context, userID int64, tq entity.TaskQuery) (entity.Page[entity.Task], error)
+	AssignTask(ctx context.Context, taskID int64, userID int64) error
+	UnassignTask(ctx context.Context, taskID int64, userID int64) error
+	UpdateTask(ctx context.Context, id int64, upd entity.TaskToUpdate) error
+	UpdateTaskStatus(ctx context.Context, id int64, status string) error
+	MoveTask(ctx context.Context, task entity.Task, move entity.TaskMove) (rank string, err error)
+	TransferTasks(ctx context.Context, projectID int64, tasks []entity.Task, createFields bool) error
+	SetRecurrence(ctx context.Context, rec entity.Recurrence) error
+	Recurrence(ctx context.Context, taskID int64) (entity.Recurrence, error)
+	DeleteRecurrence(ctx context.Context, taskID int64) error
+	DueRecurrences(ctx context.Context, now time.Time) ([]entity.Recurrence, error)
+	CreateOccurrence(ctx context.Context, rec entity.Recurrence, t entity.Task, nextAt *time.Time, occurrences int) (entity.Task, bool, error)
+	AttachLabel(ctx context.Context, taskID int64, labelID int64) error
+	DetachLabel(ctx context.Context, taskID int64, labelID int64) error
+	AddChecklistItem(ctx context.Context, item entity.ChecklistItem) (entity.ChecklistItem, error)
+	ChecklistItems(ctx context.Context, taskID int64) (items []entity.ChecklistItem, err error)
+	ToggleChecklistItem(ctx context.Context, taskID int64, itemID int64) (entity.ChecklistItem, error)
+	ReorderChecklist(ctx context.Context, taskID int64, ids []int64) error
+	DeleteChecklistItem(ctx context.Context, taskID int64, itemID int64) error
+	DeleteTask(ctx context.Context, id int64, reparent bool) error
+	ChildTasks(ctx context.Context, parentID int64, tq entity.TaskQuery) (entity.Page[entity.Task], error)
+	TaskDescendants(ctx context.Context, id int64) (tasks []entity.Task, err error)
+	IsAncestor(ctx context.Context, ancestorID int64, taskID int64) (bool, error)
+	AddDependency(ctx context.Context, taskID int64, blockerID int64) error
+	RemoveDependency(ctx context.Context, taskID int64, blockerID int64) error
+	DependsOn(ctx context.Context, taskID int64, blockerID int64) (bool, error)
+	OpenBlockers(ctx context.Context, taskID int64) (ids []int64, err error)
+
+	TasksToRemind(ctx context.Context, threshold entity.ReminderThreshold, now time.Time) (reminders []entity.Reminder, err error)
+	MarkReminder(ctx context.Context, rm entity.Reminder, receiver string) (bool, error)
+	UnmarkReminder(ctx context.Context, rm entity.Reminder, receiver string) error
 }
 
 type ProjectRepository interface {
 	CreateProject(ctx context.Context, project entity.Project) (entity.Project, error)
-	UserProjects(ctx context.Context, userID int64) (projects []entity.Project, err error)
+	CreateProjectFromTemplate(ctx context.Context, project entity.Project, tmpl entity.ProjectTemplate) (entity.Project, []entity.Task, error)
+	CloneProject(ctx context.Context, sourceID int64, project entity.Project, clone entity.ProjectClone) (entity.Project, error)
+	UserProjects(ctx context.Context, userID int64, pr entity.PageRequest) (entity.Page[entity.Project], error)
 	ProjectByID(ctx context.Context, id int64) (p entity.Project, err error)
 	DeleteProject(ctx context.Context, projectID int64) error
-	AddProjectMember(ctx context.Context, code string) error
+	AddProjectMember(ctx context.Context, code string) (m entity.Membership, added bool, err error)
+	IsProjectMember(ctx context.Context, projectID int64, userID int64) (bool, error)
+	MemberRole(ctx context.Context, projectID int64, userID int64) (role entity.Role, err error)
+	SetMemberRole(ctx context.Context, projectID int64, userID int64, role entity.Role) error
+	ProjectWorkflow(ctx context.Context, projectID int64) (w entity.Workflow, err error)
+	SaveWorkflow(ctx context.Context, w entity.Workflow) error
+
+	CreateLabel(ctx context.Context, l entity.Label) (entity.Label, error)
+	LabelByID(ctx context.Context, id int64) (l entity.Label, err error)
+	ProjectLabels(ctx context.Context, projectID int64) (labels []entity.Label, err error)
+	UpdateLabel(ctx context.Context, id int64, upd entity.LabelToUpdate) error
+	DeleteLabel(ctx context.Context, id int64) error
 
-	SaveInvitationCode(ctx context.Context, code string, userID int64, projectID int64) error
+	CreateCustomField(ctx context.Context, f entity.CustomField) (entity.CustomField, error)
+	CustomFieldByID(ctx context.Context, id int64) (f entity.CustomField, err error)
+	ProjectCustomFields(ctx context.Context, projectID int64) (fields []entity.CustomField, err error)
+	UpdateCustomField(ctx context.Context, id int64, upd entity.CustomFieldToUpdate) error
+	DeleteCustomField(ctx context.Context, id int64) error
+
+	SaveInvitationCode(ctx context.Context, code string, userID int64, projectID int64, role entity.Role) error
 }
 
 type ProjectService struct {
-	auth    AuthRepository
-	project ProjectRepository
-	user    UserRepository
-	task    TaskRepository
-	kafka   *kafka.Conn
+	auth     AuthRepository
+	project  ProjectRepository
+	user     UserRepository
+	task     TaskRepository
+	history  HistoryRepository
+	activity ActivityRepository
+	kafka    *kafka.Conn
+	access   projectAccess
 }
 
-func NewProjectRepository(auth AuthRepository, project ProjectRepository, task TaskRepository, user UserRepository, kafkaConn *kafka.Conn) *ProjectService {
+func NewProjectRepository(auth AuthRepository, project ProjectRepository, task TaskRepository, user UserRepository, history HistoryRepository, activity ActivityRepository, kafkaConn *kafka.Conn) *ProjectService {
 	return &ProjectService{
-		auth:    auth,
-		project: project,
-		user:    user,
-		task:    task,
-		kafka:   kafkaConn,
+		auth:     auth,
+		project:  project,
+		user:     user,
+		task:     task,
+		history:  history,
+		activity: activity,
+		kafka:    kafkaConn,
+		access:   projectAccess{project: project, task: task},
 	}
 }
 
@@ -56,41 +119,73 @@ func (ps *ProjectService) CreateProject(ctx context.Context, project entity.Proj
 		return entity.Project{}, err
 	}
 
+	ps.recordProjectChanges(ctx, project.ID, entity.NewChange(entity.FieldCreated, nil, project))
+
+	ps.recordActivity(ctx, user.ID, entity.Activity{ProjectID: project.ID, Type: entity.ActivityProjectCreated})
+
 	return project, nil
 }
 
-func (ps *ProjectService) ProjectByID(ctx context.Context, id int64) (entity.Project, error) {
+// CloneProject copies a project the authenticated user can view into a new project they own, copying members
+// requires PermInviteMembers. See ProjectRepository.CloneProject for what is copied. Copied tasks get a created
+// entry in the copy history.
+func (ps *ProjectService) CloneProject(ctx context.Context, projectID int64, clone entity.ProjectClone) (entity.Project, error) {
+	source, err := ps.access.authorize(ctx, projectID, entity.PermViewProject)
+	if err != nil {
+		return entity.Project{}, err
+	}
+
+	// copying members brings them into a project of the user, so it takes the right to invite them
+	if clone.IncludeMembers {
+		_, err = ps.access.authorize(ctx, projectID, entity.PermInviteMembers)
+		if err != nil {
+			return entity.Project{}, err
+		}
+	}
+
+	if clone.Name == "" {
+		clone.Name = source.Name + " (copy)"
+	}
+
 	user := entity.AuthUser(ctx)
 
-	project, err := ps.project.ProjectByID(ctx, id)
+	project, err := ps.project.CloneProject(ctx, projectID, entity.Project{Name: clone.Name, UserID: user.ID, CreatedAt: time.Now()}, clone)
 	if err != nil {
 		return entity.Project{}, err
 	}
 
-	if user.ID != project.UserID {
-		return entity.Project{}, fmt.Errorf("%w: not your project", entity.ErrForbidden)
+	ps.recordProjectChanges(ctx, project.ID, entity.NewChange(entity.FieldCreated, nil, project))
+
+	// the copy is committed, history of its tasks is recorded on a best-effort basis like any other
+	tasks, err := ps.task.BoardTasks(ctx, project.ID)
+	if err != nil {
+		entity.CtxLogger(ctx).Error("History record error", "project_id", project.ID, "error", err)
+	}
+
+	for _, task := range tasks {
+		ps.recordTaskChanges(ctx, task, entity.NewChange(entity.FieldCreated, nil, task))
 	}
 
+	ps.recordActivity(ctx, user.ID, entity.Activity{ProjectID: project.ID, Type: entity.ActivityProjectCreated})
+
 	return project, nil
 }
 
-func (ps *ProjectService) UserProjects(ctx context.Context) ([]entity.Project, error) {
-	user := entity.AuthUser(ctx)
-	return ps.project.UserProjects(ctx, user.ID)
+func (ps *ProjectService) ProjectByID(ctx context.Context, id int64) (entity.Project, error) {
+	return ps.access.authorize(ctx, id, entity.PermViewProject)
 }
 
-func (ps *ProjectService) DeleteProject(ctx context.Context, projectID int64) error {
+func (ps *ProjectService) UserProjects(ctx context.Context, pr entity.PageRequest) (entity.Page[entity.Project], error) {
 	user := entity.AuthUser(ctx)
+	return ps.project.UserProjects(ctx, user.ID, pr)
+}
 
-	project, err := ps.project.ProjectByID(ctx, projectID)
+func (ps *ProjectService) DeleteProject(ctx context.Context, projectID int64) error {
+	_, err := ps.access.authorize(ctx, projectID, entity.PermDeleteProject)
 	if err != nil {
 		return err
 	}
 
-	if user.ID != project.UserID {
-		return fmt.Errorf("%w: not your project", entity.ErrForbidden)
-	}
-
 	err = ps.project.DeleteProject(ctx, projectID)
 	if err != nil {
 		return err
@@ -100,136 +195,1497 @@ func (ps *ProjectService) DeleteProject(ctx context.Context, projectID int64) er
 }
 
 func (ps *ProjectService) CreateTask(ctx context.Context, cTask entity.TaskToCreate) (entity.Task, error) {
-	project, err := ps.project.ProjectByID(ctx, cTask.ProjectID)
+	project, err := ps.access.authorize(ctx, cTask.ProjectID, entity.PermEditTasks)
 	if err != nil {
 		return entity.Task{}, err
 	}
 
 	user := entity.AuthUser(ctx)
 
-	if project.UserID != user.ID {
-		return entity.Task{}, fmt.Errorf("%w: not your project", entity.ErrForbidden)
+	if cTask.Priority == 0 {
+		cTask.Priority = entity.PriorityMedium
+	}
+
+	err = cTask.Priority.Validate()
+	if err != nil {
+		return entity.Task{}, err
+	}
+
+	if cTask.ParentID != nil {
+		_, err = ps.parentTask(ctx, cTask.ProjectID, *cTask.ParentID)
+		if err != nil {
+			return entity.Task{}, err
+		}
+	}
+
+	values, err := ps.customFieldValues(ctx, project.ID, cTask.CustomFields)
+	if err != nil {
+		return entity.Task{}, err
+	}
+
+	for id, value := range values {
+		if value == nil {
+			delete(values, id)
+		}
+	}
+
+	workflow, err := ps.project.ProjectWorkflow(ctx, project.ID)
+	if err != nil {
+		return entity.Task{}, err
 	}
 
 	task := entity.Task{
-		Name:        cTask.Name,
-		UserID:      user.ID,
-		Description: cTask.Description,
-		ProjectID:   cTask.ProjectID,
-		CreatedAt:   time.Now(),
+		Name:         cTask.Name,
+		UserID:       user.ID,
+		Description:  cTask.Description,
+		ProjectID:    cTask.ProjectID,
+		ParentID:     cTask.ParentID,
+		Status:       workflow.Initial(),
+		Priority:     cTask.Priority,
+		DueAt:        cTask.DueAt,
+		CustomFields: values,
+		CreatedAt:    time.Now(),
+	}
+
+	task, err = ps.task.CreateTask(ctx, task)
+	if err != nil {
+		return entity.Task{}, err
 	}
 
-	return ps.task.CreateTask(ctx, task)
+	ps.recordTaskChanges(ctx, task, entity.NewChange(entity.FieldCreated, nil, task))
+
+	ps.recordTaskActivity(ctx, entity.ActivityTaskCreated, task)
+
+	return task, nil
 }
 
 func (ps *ProjectService) TaskByID(ctx context.Context, id int64) (entity.Task, error) {
+	return ps.access.authorizeTask(ctx, id, entity.PermViewProject)
+}
+
+func (ps *ProjectService) ProjectTasks(ctx context.Context, projectID int64, tq entity.TaskQuery) (entity.Page[entity.Task], error) {
+	_, err := ps.access.authorize(ctx, projectID, entity.PermViewProject)
+	if err != nil {
+		return entity.Page[entity.Task]{}, err
+	}
+
+	return ps.task.ProjectTasks(ctx, projectID, tq)
+}
+
+func (ps *ProjectService) UserTasks(ctx context.Context, tq entity.TaskQuery) (entity.Page[entity.Task], error) {
 	user := entity.AuthUser(ctx)
+	return ps.task.UserTasks(ctx, user.ID, tq)
+}
+
+func (ps *ProjectService) AssignedTasks(ctx context.Context, tq entity.TaskQuery) (entity.Page[entity.Task], error) {
+	user := entity.AuthUser(ctx)
+	return ps.task.AssignedTasks(ctx, user.ID, tq)
+}
+
+func (ps *ProjectService) AssignTask(ctx context.Context, taskID int64, userID int64) (entity.Task, error) {
+	task, err := ps.access.authorizeTask(ctx, taskID, entity.PermEditTasks)
+	if err != nil {
+		return entity.Task{}, err
+	}
 
-	task, err := ps.task.TaskByID(ctx, id)
+	isMember, err := ps.project.IsProjectMember(ctx, task.ProjectID, userID)
 	if err != nil {
 		return entity.Task{}, err
 	}
 
-	if user.ID != task.UserID {
-		return entity.Task{}, fmt.Errorf("%w: not your task", entity.ErrForbidden)
+	if !isMember {
+		return entity.Task{}, fmt.Errorf("%w: user is not a project member", entity.ErrBadRequest)
+	}
+
+	for _, id := range task.Assignees {
+		if id == userID {
+			return task, nil
+		}
+	}
+
+	err = ps.task.AssignTask(ctx, taskID, userID)
+	if err != nil {
+		return entity.Task{}, err
+	}
+
+	assignees := task.Assignees
+	task.Assignees = append(task.Assignees, userID)
+
+	ps.recordTaskChanges(ctx, task, entity.NewChange("assignees", assignees, task.Assignees))
+
+	// the assignment is done already, a failed notification doesn't undo it
+	err = ps.notifyAssignee(ctx, task, userID)
+	if err != nil {
+		entity.CtxLogger(ctx).Error("Notification error", "task_id", task.ID, "user_id", userID, "error", err)
 	}
 
 	return task, nil
 }
 
-func (ps *ProjectService) ProjectTasks(ctx context.Context, projectID int64) ([]entity.Task, error) {
-	project, err := ps.project.ProjectByID(ctx, projectID)
+func (ps *ProjectService) notifyAssignee(ctx context.Context, task entity.Task, userID int64) error {
+	assignee, err := ps.user.UserByID(ctx, userID)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	user := entity.AuthUser(ctx)
+	ntf := entity.Notification{
+		Subject:  "task assignment",
+		Receiver: assignee.Email,
+		Message:  fmt.Sprintf("You are assigned to task %q", task.Name),
+	}
+
+	return sendNotification(ctx, ps.kafka, ntf)
+}
 
-	if project.UserID != user.ID {
-		return nil, fmt.Errorf("%w: not your project", entity.ErrForbidden)
+func (ps *ProjectService) UnassignTask(ctx context.Context, taskID int64, userID int64) error {
+	task, err := ps.access.authorizeTask(ctx, taskID, entity.PermEditTasks)
+	if err != nil {
+		return err
 	}
 
-	tasks, err := ps.task.ProjectTasks(ctx, projectID)
+	err = ps.task.UnassignTask(ctx, taskID, userID)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	return tasks, nil
+	ps.recordTaskChanges(ctx, task, entity.NewChange("assignees", task.Assignees, without(task.Assignees, userID)))
+
+	return nil
 }
 
-func (ps *ProjectService) UserTasks(ctx context.Context) ([]entity.Task, error) {
-	user := entity.AuthUser(ctx)
+func (ps *ProjectService) UpdateTask(ctx context.Context, id int64, upd entity.TaskToUpdate) (entity.Task, error) {
+	err := upd.Validate()
+	if err != nil {
+		return entity.Task{}, err
+	}
 
-	tasks, err := ps.task.UserTasks(ctx, user.ID)
+	task, err := ps.access.authorizeTask(ctx, id, entity.PermEditTasks)
 	if err != nil {
-		return nil, err
+		return entity.Task{}, err
+	}
+
+	var (
+		changes entity.TaskToUpdate
+		changed []entity.Change
+	)
+
+	if upd.Name != nil && *upd.Name != task.Name {
+		changed = append(changed, entity.NewChange("name", task.Name, *upd.Name))
+		changes.Name = upd.Name
+		task.Name = *upd.Name
 	}
 
-	return tasks, nil
+	if upd.Description != nil && *upd.Description != task.Description {
+		changed = append(changed, entity.NewChange("description", task.Description, *upd.Description))
+		changes.Description = upd.Description
+		task.Description = *upd.Description
+	}
+
+	if upd.Status != nil && *upd.Status != task.Status {
+		err = ps.checkTransition(ctx, task, *upd.Status)
+		if err != nil {
+			return entity.Task{}, err
+		}
+
+		changed = append(changed, entity.NewChange("status", task.Status, *upd.Status))
+		changes.Status = upd.Status
+		task.Status = *upd.Status
+	}
+
+	if upd.Priority != nil && *upd.Priority != task.Priority {
+		changed = append(changed, entity.NewChange("priority", task.Priority, *upd.Priority))
+		changes.Priority = upd.Priority
+		task.Priority = *upd.Priority
+	}
+
+	if upd.DueAt.Set && !sameTime(upd.DueAt.Value, task.DueAt) {
+		changed = append(changed, entity.NewChange("due_at", task.DueAt, upd.DueAt.Value))
+		changes.DueAt = upd.DueAt
+		task.DueAt = upd.DueAt.Value
+	}
+
+	if upd.ParentID.Set && !sameID(upd.ParentID.Value, task.ParentID) {
+		if upd.ParentID.Value != nil {
+			err = ps.checkParent(ctx, task, *upd.ParentID.Value)
+			if err != nil {
+				return entity.Task{}, err
+			}
+		}
+
+		changed = append(changed, entity.NewChange("parent_id", task.ParentID, upd.ParentID.Value))
+		changes.ParentID = upd.ParentID
+		task.ParentID = upd.ParentID.Value
+	}
+
+	values, err := ps.customFieldValues(ctx, task.ProjectID, upd.CustomFields)
+	if err != nil {
+		return entity.Task{}, err
+	}
+
+	for id, value := range values {
+		if bytes.Equal(value, task.CustomFields[id]) {
+			continue
+		}
+
+		if changes.CustomFields == nil {
+			changes.CustomFields = make(map[int64]json.RawMessage)
+		}
+
+		if task.CustomFields == nil {
+			task.CustomFields = make(map[int64]json.RawMessage)
+		}
+
+		changed = append(changed, entity.NewChange(fmt.Sprintf("custom.%d", id), task.CustomFields[id], value))
+		changes.CustomFields[id] = value
+
+		if value == nil {
+			delete(task.CustomFields, id)
+		} else {
+			task.CustomFields[id] = value
+		}
+	}
+
+	if changes.IsEmpty() {
+		return task, nil
+	}
+
+	err = ps.task.UpdateTask(ctx, id, changes)
+	if err != nil {
+		return entity.Task{}, err
+	}
+
+	ps.recordTaskChanges(ctx, task, changed...)
+
+	ps.recordTaskActivity(ctx, entity.ActivityTaskUpdated, task)
+
+	return task, nil
 }
 
-func (ps *ProjectService) AddProjectMember(ctx context.Context, code string) error {
-	err := ps.project.AddProjectMember(ctx, code)
+// DeleteTask deletes the task, creators may delete their own tasks while other tasks require PermDeleteTasks,
+// subtasks mode tells whether its subtasks are deleted too or moved under its parent, empty mode means cascade.
+// Cascading over subtasks of other users requires PermDeleteTasks as well.
+func (ps *ProjectService) DeleteTask(ctx context.Context, id int64, subtasks string) error {
+	if subtasks == "" {
+		subtasks = entity.SubtasksCascade
+	}
+
+	err := entity.ValidateSubtasksMode(subtasks)
+	if err != nil {
+		return err
+	}
+
+	user := entity.AuthUser(ctx)
+
+	task, err := ps.access.authorizeTask(ctx, id, entity.PermEditTasks)
+	if err != nil {
+		return err
+	}
+
+	foreign := task.UserID != user.ID
+
+	// cascading deletes subtasks too, those of other users take the same right as their tasks
+	if !foreign && subtasks == entity.SubtasksCascade {
+		descendants, err := ps.task.TaskDescendants(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		foreign = slices.ContainsFunc(descendants, func(t entity.Task) bool { return t.UserID != user.ID })
+	}
+
+	if foreign {
+		_, err = ps.access.authorize(ctx, task.ProjectID, entity.PermDeleteTasks)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = ps.task.DeleteTask(ctx, id, subtasks == entity.SubtasksReparent)
 	if err != nil {
 		return err
 	}
 
+	ps.recordTaskChanges(ctx, task, entity.NewChange(entity.FieldDeleted, task, nil))
+
 	return nil
 }
 
-func (ps *ProjectService) InviteMemberRequest(ctx context.Context, projectID int64, email string) error {
-	requester := entity.AuthUser(ctx)
+func (ps *ProjectService) ChildTasks(ctx context.Context, id int64, tq entity.TaskQuery) (entity.Page[entity.Task], error) {
+	_, err := ps.access.authorizeTask(ctx, id, entity.PermViewProject)
+	if err != nil {
+		return entity.Page[entity.Task]{}, err
+	}
+
+	return ps.task.ChildTasks(ctx, id, tq)
+}
 
-	project, err := ps.project.ProjectByID(ctx, projectID)
+// TaskTree returns the task with all its subtasks nested under their parents.
+func (ps *ProjectService) TaskTree(ctx context.Context, id int64) (entity.TaskTree, error) {
+	task, err := ps.access.authorizeTask(ctx, id, entity.PermViewProject)
 	if err != nil {
-		return err
+		return entity.TaskTree{}, err
 	}
 
-	if requester.ID != project.UserID {
-		return fmt.Errorf("%w: not your project", entity.ErrForbidden)
+	descendants, err := ps.task.TaskDescendants(ctx, id)
+	if err != nil {
+		return entity.TaskTree{}, err
 	}
 
-	user, err := ps.auth.UserByEmail(ctx, email)
+	return entity.BuildTaskTree(task, descendants), nil
+}
+
+// AddBlocker marks the task as blocked by blockerID. The blocker may belong to any project the user can see,
+// dependencies leading back to the task are rejected.
+func (ps *ProjectService) AddBlocker(ctx context.Context, taskID int64, blockerID int64) (entity.Task, error) {
+	task, err := ps.access.authorizeTask(ctx, taskID, entity.PermEditTasks)
 	if err != nil {
-		return err
+		return entity.Task{}, err
 	}
 
-	code := uuid.NewString()
+	_, err = ps.access.authorizeTask(ctx, blockerID, entity.PermViewProject)
+	if err != nil {
+		if errors.Is(err, entity.ErrNotFound) {
+			return entity.Task{}, fmt.Errorf("%w: unknown blocker task", entity.ErrBadRequest)
+		}
 
-	err = ps.project.SaveInvitationCode(ctx, code, user.ID, projectID)
+		return entity.Task{}, err
+	}
+
+	cycle, err := ps.task.DependsOn(ctx, blockerID, taskID)
+	if err != nil {
+		return entity.Task{}, err
+	}
+
+	if cycle {
+		return entity.Task{}, fmt.Errorf("%w: dependency on task %d makes a cycle", entity.ErrBadRequest, blockerID)
+	}
+
+	err = ps.task.AddDependency(ctx, task.ID, blockerID)
+	if err != nil {
+		return entity.Task{}, err
+	}
+
+	updated, err := ps.task.TaskByID(ctx, taskID)
+	if err != nil {
+		return entity.Task{}, err
+	}
+
+	ps.recordTaskChanges(ctx, task, entity.NewChange("blocked_by", task.BlockedBy, updated.BlockedBy))
+
+	return updated, nil
+}
+
+func (ps *ProjectService) RemoveBlocker(ctx context.Context, taskID int64, blockerID int64) error {
+	task, err := ps.access.authorizeTask(ctx, taskID, entity.PermEditTasks)
 	if err != nil {
 		return err
 	}
 
-	err = ps.SendInvite(ctx, email, code, project.Name)
+	err = ps.task.RemoveDependency(ctx, taskID, blockerID)
 	if err != nil {
 		return err
 	}
 
+	ps.recordTaskChanges(ctx, task, entity.NewChange("blocked_by", task.BlockedBy, without(task.BlockedBy, blockerID)))
+
 	return nil
 }
 
-func (ps *ProjectService) SendInvite(ctx context.Context, email string, code string, projectName string) error {
-	message := map[string]string{
-		"subject":  "Invitation",
-		"receiver": email,
-		"message":  fmt.Sprintf("You are invited to %s\nFollow link to accept invitation\n http://localhost:8080/projects/invite?code=%s", projectName, code),
+// parentTask returns the task new subtasks of the project may be put under.
+func (ps *ProjectService) parentTask(ctx context.Context, projectID int64, parentID int64) (entity.Task, error) {
+	parent, err := ps.task.TaskByID(ctx, parentID)
+	if err != nil {
+		if errors.Is(err, entity.ErrNotFound) {
+			return entity.Task{}, fmt.Errorf("%w: unknown parent task", entity.ErrBadRequest)
+		}
+
+		return entity.Task{}, err
 	}
 
-	b, err := json.Marshal(message)
-	if err != nil {
-		return err
+	if parent.ProjectID != projectID {
+		return entity.Task{}, fmt.Errorf("%w: parent task belongs to another project", entity.ErrBadRequest)
 	}
 
-	msg := kafka.Message{
-		Key:   []byte(code),
-		Value: b,
+	return parent, nil
+}
+
+// checkParent validates moving the task under parentID, the task can't become a subtask of itself or its subtasks.
+func (ps *ProjectService) checkParent(ctx context.Context, task entity.Task, parentID int64) error {
+	_, err := ps.parentTask(ctx, task.ProjectID, parentID)
+	if err != nil {
+		return err
 	}
 
-	_, err = ps.kafka.WriteMessages(msg)
+	cycle, err := ps.task.IsAncestor(ctx, task.ID, parentID)
 	if err != nil {
 		return err
 	}
 
+	if cycle {
+		return fmt.Errorf("%w: task can't be a subtask of itself or its subtasks", entity.ErrBadRequest)
+	}
+
 	return nil
 }
+
+func (ps *ProjectService) ChangeTaskStatus(ctx context.Context, id int64, status string) (entity.Task, error) {
+	task, err := ps.access.authorizeTask(ctx, id, entity.PermEditTasks)
+	if err != nil {
+		return entity.Task{}, err
+	}
+
+	if task.Status == status {
+		return task, nil
+	}
+
+	err = ps.checkTransition(ctx, task, status)
+	if err != nil {
+		return entity.Task{}, err
+	}
+
+	err = ps.task.UpdateTaskStatus(ctx, id, status)
+	if err != nil {
+		return entity.Task{}, err
+	}
+
+	ps.recordTaskChanges(ctx, task, entity.NewChange("status", task.Status, status))
+
+	ps.recordTaskActivity(ctx, entity.ActivityTaskUpdated, task)
+
+	task.Status = status
+
+	return task, nil
+}
+
+// MoveTask drops the task into a board column between its new neighbours, changing the status if needed.
+func (ps *ProjectService) MoveTask(ctx context.Context, id int64, move entity.TaskMove) (entity.Task, error) {
+	task, err := ps.access.authorizeTask(ctx, id, entity.PermEditTasks)
+	if err != nil {
+		return entity.Task{}, err
+	}
+
+	if move.Status == "" {
+		move.Status = task.Status
+	}
+
+	if move.Status != task.Status {
+		err = ps.checkTransition(ctx, task, move.Status)
+		if err != nil {
+			return entity.Task{}, err
+		}
+	}
+
+	rank, err := ps.task.MoveTask(ctx, task, move)
+	if err != nil {
+		return entity.Task{}, err
+	}
+
+	if move.Status != task.Status {
+		ps.recordTaskChanges(ctx, task, entity.NewChange("status", task.Status, move.Status))
+
+		ps.recordTaskActivity(ctx, entity.ActivityTaskUpdated, task)
+	}
+
+	task.Status = move.Status
+	task.Rank = rank
+
+	return task, nil
+}
+
+// TransferTasks moves tasks with all their subtasks to another project, see TaskRepository.TransferTasks
+// for how their data comes along. Moving out takes the rights to delete the tasks, moving in the rights to edit
+// tasks of the destination, custom fields it lacks are created only for users who can manage them.
+// Statuses the destination workflow doesn't have become its initial one. Assignees are notified.
+func (ps *ProjectService) TransferTasks(ctx context.Context, transfer entity.TaskTransfer) ([]entity.Task, error) {
+	err := transfer.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	dest, err := ps.access.authorize(ctx, transfer.ProjectID, entity.PermEditTasks)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = ps.access.authorize(ctx, dest.ID, entity.PermManageFields)
+	if err != nil && !errors.Is(err, entity.ErrForbidden) {
+		return nil, err
+	}
+
+	createFields := err == nil
+
+	workflow, err := ps.project.ProjectWorkflow(ctx, dest.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	user := entity.AuthUser(ctx)
+
+	var tasks []entity.Task
+
+	moving := make(map[int64]bool)
+	deletable := make(map[int64]bool)
+
+	for _, id := range transfer.TaskIDs {
+		// listed subtasks of listed tasks are already moving
+		if moving[id] {
+			continue
+		}
+
+		task, err := ps.access.authorizeTask(ctx, id, entity.PermEditTasks)
+		if err != nil {
+			return nil, err
+		}
+
+		if task.ProjectID == dest.ID {
+			return nil, fmt.Errorf("%w: task %d already belongs to the project", entity.ErrBadRequest, id)
+		}
+
+		descendants, err := ps.task.TaskDescendants(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, t := range append([]entity.Task{task}, descendants...) {
+			if moving[t.ID] {
+				continue
+			}
+
+			if t.UserID != user.ID && !deletable[t.ProjectID] {
+				_, err = ps.access.authorize(ctx, t.ProjectID, entity.PermDeleteTasks)
+				if err != nil {
+					return nil, err
+				}
+
+				deletable[t.ProjectID] = true
+			}
+
+			moving[t.ID] = true
+			tasks = append(tasks, t)
+		}
+	}
+
+	// tasks keep their board order within columns of the destination
+	slices.SortStableFunc(tasks, func(a, b entity.Task) int { return strings.Compare(a.Rank, b.Rank) })
+
+	moved := make([]entity.Task, len(tasks))
+
+	for i, t := range tasks {
+		moved[i] = t
+		moved[i].ProjectID = dest.ID
+
+		if !workflow.HasStatus(t.Status) {
+			moved[i].Status = workflow.Initial()
+		}
+
+		if t.ParentID != nil && !moving[*t.ParentID] {
+			moved[i].ParentID = nil
+		}
+	}
+
+	err = ps.task.TransferTasks(ctx, dest.ID, moved, createFields)
+	if err != nil {
+		return nil, err
+	}
+
+	var receivers []int64
+	names := make(map[int64][]string)
+
+	for i, t := range tasks {
+		moved[i], err = ps.task.TaskByID(ctx, t.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		changes := []entity.Change{entity.NewChange("project", t.ProjectID, dest.ID)}
+
+		if moved[i].Status != t.Status {
+			changes = append(changes, entity.NewChange("status", t.Status, moved[i].Status))
+		}
+
+		if !sameID(moved[i].ParentID, t.ParentID) {
+			changes = append(changes, entity.NewChange("parent_id", t.ParentID, moved[i].ParentID))
+		}
+
+		ps.recordTaskChanges(ctx, moved[i], changes...)
+
+		// the task history follows the task, the source project keeps a note of where it went
+		ps.recordProjectChanges(ctx, t.ProjectID, entity.NewChange("moved_task", t, dest.ID))
+
+		for _, id := range moved[i].Assignees {
+			if _, ok := names[id]; !ok {
+				receivers = append(receivers, id)
+			}
+
+			names[id] = append(names[id], strconv.Quote(t.Name))
+		}
+	}
+
+	// the tasks are moved already, failed notifications don't undo it
+	for _, id := range receivers {
+		receiver, err := ps.user.UserByID(ctx, id)
+		if err != nil {
+			entity.CtxLogger(ctx).Error("Notification error", "project_id", dest.ID, "user_id", id, "error", err)
+			continue
+		}
+
+		ntf := entity.Notification{
+			Subject:  "tasks moved",
+			Receiver: receiver.Email,
+			Message:  fmt.Sprintf("Tasks %s you are assigned to were moved to project %q", strings.Join(names[id], ", "), dest.Name),
+		}
+
+		err = sendNotification(ctx, ps.kafka, ntf)
+		if err != nil {
+			entity.CtxLogger(ctx).Error("Notification error", "project_id", dest.ID, "user_id", id, "error", err)
+		}
+	}
+
+	return moved, nil
+}
+
+// checkTransition validates moving the task to status according to its project workflow.
+func (ps *ProjectService) checkTransition(ctx context.Context, task entity.Task, status string) error {
+	workflow, err := ps.project.ProjectWorkflow(ctx, task.ProjectID)
+	if err != nil {
+		return err
+	}
+
+	if !workflow.HasStatus(status) {
+		return fmt.Errorf("%w: unknown status %q", entity.ErrBadRequest, status)
+	}
+
+	if !workflow.CanTransition(task.Status, status) {
+		return fmt.Errorf("%w: transition from %q to %q is not allowed", entity.ErrBadRequest, task.Status, status)
+	}
+
+	if workflow.IsDone(status) {
+		blockers, err := ps.task.OpenBlockers(ctx, task.ID)
+		if err != nil {
+			return err
+		}
+
+		if len(blockers) != 0 {
+			return fmt.Errorf("%w: task is blocked by open tasks %v", entity.ErrBadRequest, blockers)
+		}
+	}
+
+	return nil
+}
+
+func (ps *ProjectService) ProjectWorkflow(ctx context.Context, projectID int64) (entity.Workflow, error) {
+	_, err := ps.access.authorize(ctx, projectID, entity.PermViewProject)
+	if err != nil {
+		return entity.Workflow{}, err
+	}
+
+	return ps.project.ProjectWorkflow(ctx, projectID)
+}
+
+func (ps *ProjectService) UpdateProjectWorkflow(ctx context.Context, workflow entity.Workflow) (entity.Workflow, error) {
+	_, err := ps.access.authorize(ctx, workflow.ProjectID, entity.PermManageWorkflow)
+	if err != nil {
+		return entity.Workflow{}, err
+	}
+
+	err = workflow.Validate()
+	if err != nil {
+		return entity.Workflow{}, err
+	}
+
+	previous, err := ps.project.ProjectWorkflow(ctx, workflow.ProjectID)
+	if err != nil {
+		return entity.Workflow{}, err
+	}
+
+	err = ps.project.SaveWorkflow(ctx, workflow)
+	if err != nil {
+		return entity.Workflow{}, err
+	}
+
+	ps.recordProjectChanges(ctx, workflow.ProjectID, entity.NewChange("workflow", previous, workflow))
+
+	return workflow, nil
+}
+
+func (ps *ProjectService) ProjectLabels(ctx context.Context, projectID int64) ([]entity.Label, error) {
+	_, err := ps.access.authorize(ctx, projectID, entity.PermViewProject)
+	if err != nil {
+		return nil, err
+	}
+
+	return ps.project.ProjectLabels(ctx, projectID)
+}
+
+func (ps *ProjectService) CreateLabel(ctx context.Context, label entity.Label) (entity.Label, error) {
+	if label.Color == "" {
+		label.Color = entity.DefaultLabelColor
+	}
+
+	err := label.Validate()
+	if err != nil {
+		return entity.Label{}, err
+	}
+
+	_, err = ps.access.authorize(ctx, label.ProjectID, entity.PermManageLabels)
+	if err != nil {
+		return entity.Label{}, err
+	}
+
+	label, err = ps.project.CreateLabel(ctx, label)
+	if err != nil {
+		return entity.Label{}, err
+	}
+
+	ps.recordProjectChanges(ctx, label.ProjectID, entity.NewChange("label", nil, label))
+
+	return label, nil
+}
+
+func (ps *ProjectService) UpdateLabel(ctx context.Context, projectID int64, labelID int64, upd entity.LabelToUpdate) (entity.Label, error) {
+	err := upd.Validate()
+	if err != nil {
+		return entity.Label{}, err
+	}
+
+	previous, err := ps.projectLabel(ctx, projectID, labelID, entity.PermManageLabels)
+	if err != nil {
+		return entity.Label{}, err
+	}
+
+	err = ps.project.UpdateLabel(ctx, labelID, upd)
+	if err != nil {
+		return entity.Label{}, err
+	}
+
+	label, err := ps.project.LabelByID(ctx, labelID)
+	if err != nil {
+		return entity.Label{}, err
+	}
+
+	if label != previous {
+		ps.recordProjectChanges(ctx, projectID, entity.NewChange("label", previous, label))
+	}
+
+	return label, nil
+}
+
+// DeleteLabel removes the label from the project catalog and from every task it's attached to.
+func (ps *ProjectService) DeleteLabel(ctx context.Context, projectID int64, labelID int64) error {
+	label, err := ps.projectLabel(ctx, projectID, labelID, entity.PermManageLabels)
+	if err != nil {
+		return err
+	}
+
+	err = ps.project.DeleteLabel(ctx, labelID)
+	if err != nil {
+		return err
+	}
+
+	ps.recordProjectChanges(ctx, projectID, entity.NewChange("label", label, nil))
+
+	return nil
+}
+
+// projectLabel returns the label if it belongs to the project and the authenticated user's role grants perm.
+func (ps *ProjectService) projectLabel(ctx context.Context, projectID int64, labelID int64, perm entity.Permission) (entity.Label, error) {
+	_, err := ps.access.authorize(ctx, projectID, perm)
+	if err != nil {
+		return entity.Label{}, err
+	}
+
+	label, err := ps.project.LabelByID(ctx, labelID)
+	if err != nil {
+		return entity.Label{}, err
+	}
+
+	if label.ProjectID != projectID {
+		return entity.Label{}, entity.ErrNotFound
+	}
+
+	return label, nil
+}
+
+func (ps *ProjectService) ProjectCustomFields(ctx context.Context, projectID int64) ([]entity.CustomField, error) {
+	_, err := ps.access.authorize(ctx, projectID, entity.PermViewProject)
+	if err != nil {
+		return nil, err
+	}
+
+	return ps.project.ProjectCustomFields(ctx, projectID)
+}
+
+func (ps *ProjectService) CreateCustomField(ctx context.Context, field entity.CustomField) (entity.CustomField, error) {
+	if field.Options == nil {
+		field.Options = []string{}
+	}
+
+	err := field.Validate()
+	if err != nil {
+		return entity.CustomField{}, err
+	}
+
+	_, err = ps.access.authorize(ctx, field.ProjectID, entity.PermManageFields)
+	if err != nil {
+		return entity.CustomField{}, err
+	}
+
+	field, err = ps.project.CreateCustomField(ctx, field)
+	if err != nil {
+		return entity.CustomField{}, err
+	}
+
+	ps.recordProjectChanges(ctx, field.ProjectID, entity.NewChange("custom_field", nil, field))
+
+	return field, nil
+}
+
+// UpdateCustomField renames the field or changes options of a select field,
+// values of removed options are dropped from tasks.
+func (ps *ProjectService) UpdateCustomField(ctx context.Context, projectID int64, fieldID int64, upd entity.CustomFieldToUpdate) (entity.CustomField, error) {
+	previous, err := ps.projectCustomField(ctx, projectID, fieldID, entity.PermManageFields)
+	if err != nil {
+		return entity.CustomField{}, err
+	}
+
+	err = upd.Validate(previous)
+	if err != nil {
+		return entity.CustomField{}, err
+	}
+
+	err = ps.project.UpdateCustomField(ctx, fieldID, upd)
+	if err != nil {
+		return entity.CustomField{}, err
+	}
+
+	field, err := ps.project.CustomFieldByID(ctx, fieldID)
+	if err != nil {
+		return entity.CustomField{}, err
+	}
+
+	if field.Name != previous.Name || !slices.Equal(field.Options, previous.Options) {
+		ps.recordProjectChanges(ctx, projectID, entity.NewChange("custom_field", previous, field))
+	}
+
+	return field, nil
+}
+
+// DeleteCustomField removes the field from the project together with its values on every task.
+func (ps *ProjectService) DeleteCustomField(ctx context.Context, projectID int64, fieldID int64) error {
+	field, err := ps.projectCustomField(ctx, projectID, fieldID, entity.PermManageFields)
+	if err != nil {
+		return err
+	}
+
+	err = ps.project.DeleteCustomField(ctx, fieldID)
+	if err != nil {
+		return err
+	}
+
+	ps.recordProjectChanges(ctx, projectID, entity.NewChange("custom_field", field, nil))
+
+	return nil
+}
+
+// projectCustomField returns the field if it belongs to the project and the authenticated user's role grants perm.
+func (ps *ProjectService) projectCustomField(ctx context.Context, projectID int64, fieldID int64, perm entity.Permission) (entity.CustomField, error) {
+	_, err := ps.access.authorize(ctx, projectID, perm)
+	if err != nil {
+		return entity.CustomField{}, err
+	}
+
+	field, err := ps.project.CustomFieldByID(ctx, fieldID)
+	if err != nil {
+		return entity.CustomField{}, err
+	}
+
+	if field.ProjectID != projectID {
+		return entity.CustomField{}, entity.ErrNotFound
+	}
+
+	return field, nil
+}
+
+// customFieldValues validates values against custom fields of the project and returns them normalized,
+// nil values mean removal. User fields must refer to project members.
+func (ps *ProjectService) customFieldValues(ctx context.Context, projectID int64, values map[int64]json.RawMessage) (map[int64]json.RawMessage, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	fields, err := ps.project.ProjectCustomFields(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	normalized := make(map[int64]json.RawMessage, len(values))
+
+	for id, raw := range values {
+		i := slices.IndexFunc(fields, func(f entity.CustomField) bool { return f.ID == id })
+		if i < 0 {
+			return nil, fmt.Errorf("%w: unknown custom field %d", entity.ErrBadRequest, id)
+		}
+
+		value, err := fields[i].NormalizeValue(raw)
+		if err != nil {
+			return nil, err
+		}
+
+		if userID, ok := fields[i].UserID(value); ok {
+			isMember, err := ps.project.IsProjectMember(ctx, projectID, userID)
+			if err != nil {
+				return nil, err
+			}
+
+			if !isMember {
+				return nil, fmt.Errorf("%w: field %q refers to a user who is not a project member", entity.ErrBadRequest, fields[i].Name)
+			}
+		}
+
+		normalized[id] = value
+	}
+
+	return normalized, nil
+}
+
+func (ps *ProjectService) AttachLabel(ctx context.Context, taskID int64, labelID int64) (entity.Task, error) {
+	task, err := ps.access.authorizeTask(ctx, taskID, entity.PermEditTasks)
+	if err != nil {
+		return entity.Task{}, err
+	}
+
+	label, err := ps.project.LabelByID(ctx, labelID)
+	if err != nil {
+		if errors.Is(err, entity.ErrNotFound) {
+			return entity.Task{}, fmt.Errorf("%w: unknown label", entity.ErrBadRequest)
+		}
+
+		return entity.Task{}, err
+	}
+
+	if label.ProjectID != task.ProjectID {
+		return entity.Task{}, fmt.Errorf("%w: label belongs to another project", entity.ErrBadRequest)
+	}
+
+	err = ps.task.AttachLabel(ctx, taskID, labelID)
+	if err != nil {
+		return entity.Task{}, err
+	}
+
+	updated, err := ps.task.TaskByID(ctx, taskID)
+	if err != nil {
+		return entity.Task{}, err
+	}
+
+	ps.recordTaskChanges(ctx, task, entity.NewChange("labels", labelIDs(task.Labels), labelIDs(updated.Labels)))
+
+	return updated, nil
+}
+
+func (ps *ProjectService) DetachLabel(ctx context.Context, taskID int64, labelID int64) error {
+	task, err := ps.access.authorizeTask(ctx, taskID, entity.PermEditTasks)
+	if err != nil {
+		return err
+	}
+
+	err = ps.task.DetachLabel(ctx, taskID, labelID)
+	if err != nil {
+		return err
+	}
+
+	labels := labelIDs(task.Labels)
+
+	ps.recordTaskChanges(ctx, task, entity.NewChange("labels", labels, without(labels, labelID)))
+
+	return nil
+}
+
+func (ps *ProjectService) TaskChecklist(ctx context.Context, taskID int64) ([]entity.ChecklistItem, error) {
+	_, err := ps.access.authorizeTask(ctx, taskID, entity.PermViewProject)
+	if err != nil {
+		return nil, err
+	}
+
+	return ps.task.ChecklistItems(ctx, taskID)
+}
+
+// AddChecklistItem appends an item to the task checklist, the responsible user must be a project member.
+func (ps *ProjectService) AddChecklistItem(ctx context.Context, taskID int64, ic entity.ChecklistItemToCreate) (entity.ChecklistItem, error) {
+	err := ic.Validate()
+	if err != nil {
+		return entity.ChecklistItem{}, err
+	}
+
+	task, err := ps.access.authorizeTask(ctx, taskID, entity.PermEditTasks)
+	if err != nil {
+		return entity.ChecklistItem{}, err
+	}
+
+	if ic.ResponsibleID != nil {
+		isMember, err := ps.project.IsProjectMember(ctx, task.ProjectID, *ic.ResponsibleID)
+		if err != nil {
+			return entity.ChecklistItem{}, err
+		}
+
+		if !isMember {
+			return entity.ChecklistItem{}, fmt.Errorf("%w: responsible user is not a project member", entity.ErrBadRequest)
+		}
+	}
+
+	item, err := ps.task.AddChecklistItem(ctx, entity.ChecklistItem{
+		TaskID:        taskID,
+		Text:          ic.Text,
+		ResponsibleID: ic.ResponsibleID,
+		CreatedAt:     time.Now(),
+	})
+	if err != nil {
+		return entity.ChecklistItem{}, err
+	}
+
+	ps.recordTaskChanges(ctx, task, entity.NewChange("checklist_item", nil, item))
+
+	return item, nil
+}
+
+func (ps *ProjectService) ToggleChecklistItem(ctx context.Context, taskID int64, itemID int64) (entity.ChecklistItem, error) {
+	task, err := ps.access.authorizeTask(ctx, taskID, entity.PermEditTasks)
+	if err != nil {
+		return entity.ChecklistItem{}, err
+	}
+
+	item, err := ps.task.ToggleChecklistItem(ctx, taskID, itemID)
+	if err != nil {
+		return entity.ChecklistItem{}, err
+	}
+
+	previous := item
+	previous.Checked = !item.Checked
+
+	ps.recordTaskChanges(ctx, task, entity.NewChange("checklist_item", previous, item))
+
+	return item, nil
+}
+
+// ReorderChecklist puts the task checklist in the given order, which must list every item once.
+func (ps *ProjectService) ReorderChecklist(ctx context.Context, taskID int64, order entity.ChecklistOrder) ([]entity.ChecklistItem, error) {
+	task, err := ps.access.authorizeTask(ctx, taskID, entity.PermEditTasks)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := ps.task.ChecklistItems(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = order.Validate(items)
+	if err != nil {
+		return nil, err
+	}
+
+	err = ps.task.ReorderChecklist(ctx, taskID, order.ItemIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	previous := make([]int64, len(items))
+	for i, item := range items {
+		previous[i] = item.ID
+	}
+
+	if !slices.Equal(previous, order.ItemIDs) {
+		ps.recordTaskChanges(ctx, task, entity.NewChange("checklist_order", previous, order.ItemIDs))
+	}
+
+	return ps.task.ChecklistItems(ctx, taskID)
+}
+
+func (ps *ProjectService) DeleteChecklistItem(ctx context.Context, taskID int64, itemID int64) error {
+	task, err := ps.access.authorizeTask(ctx, taskID, entity.PermEditTasks)
+	if err != nil {
+		return err
+	}
+
+	items, err := ps.task.ChecklistItems(ctx, taskID)
+	if err != nil {
+		return err
+	}
+
+	i := slices.IndexFunc(items, func(item entity.ChecklistItem) bool { return item.ID == itemID })
+	if i < 0 {
+		return entity.ErrNotFound
+	}
+
+	err = ps.task.DeleteChecklistItem(ctx, taskID, itemID)
+	if err != nil {
+		return err
+	}
+
+	ps.recordTaskChanges(ctx, task, entity.NewChange("checklist_item", items[i], nil))
+
+	return nil
+}
+
+// SendDueReminders notifies about tasks approaching their due date and tasks that became overdue.
+// Every reminder is recorded per receiver before sending, so it goes out once per task, threshold
+// and receiver even across restarts. A failed send is logged and retried on the next tick.
+func (ps *ProjectService) SendDueReminders(ctx context.Context, l slog.Logger) error {
+	now := time.Now()
+
+	for _, threshold := range entity.ReminderThresholds {
+		reminders, err := ps.task.TasksToRemind(ctx, threshold, now)
+		if err != nil {
+			return err
+		}
+
+		for _, rm := range reminders {
+			for _, receiver := range rm.Receivers {
+				marked, err := ps.task.MarkReminder(ctx, rm, receiver)
+				if err != nil {
+					l.Error("Due reminder error", "task_id", rm.TaskID, "threshold", threshold.Name, "error", err)
+					continue
+				}
+
+				if !marked {
+					continue
+				}
+
+				err = ps.sendReminder(ctx, rm, receiver)
+				if err != nil {
+					err = errors.Join(err, ps.task.UnmarkReminder(ctx, rm, receiver))
+					l.Error("Due reminder error", "task_id", rm.TaskID, "threshold", threshold.Name, "error", err)
+					continue
+				}
+
+				l.Info("due reminder sent", "task_id", rm.TaskID, "threshold", threshold.Name)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (ps *ProjectService) sendReminder(ctx context.Context, rm entity.Reminder, receiver string) error {
+	ntf := entity.Notification{
+		Subject:  "task due soon",
+		Receiver: receiver,
+		Message:  fmt.Sprintf("Task %q is due at %s", rm.TaskName, rm.DueAt.Format(time.RFC1123Z)),
+	}
+
+	if rm.Threshold.Before == 0 {
+		ntf.Subject = "task overdue"
+		ntf.Message = fmt.Sprintf("Task %q was due at %s", rm.TaskName, rm.DueAt.Format(time.RFC1123Z))
+	}
+
+	return sendNotification(ctx, ps.kafka, ntf)
+}
+
+// SetTaskRecurrence makes the task a template repeated by the RRULE, the task itself is the first occurrence
+// due at its due date or creation time. Occurrences already in the past are skipped.
+func (ps *ProjectService) SetTaskRecurrence(ctx context.Context, taskID int64, rule string) (entity.Recurrence, error) {
+	task, err := ps.access.authorizeTask(ctx, taskID, entity.PermEditTasks)
+	if err != nil {
+		return entity.Recurrence{}, err
+	}
+
+	rrule, err := entity.ParseRRule(rule)
+	if err != nil {
+		return entity.Recurrence{}, err
+	}
+
+	start := task.CreatedAt
+	if task.DueAt != nil {
+		start = *task.DueAt
+	}
+
+	rec := entity.Recurrence{
+		TaskID:     task.ID,
+		Rule:       rule,
+		StartsAt:   start,
+		LastTaskID: &task.ID,
+	}
+
+	rec.NextAt, rec.Occurrences = nextOccurrence(rrule, start, start, 1, time.Now())
+
+	err = ps.task.SetRecurrence(ctx, rec)
+	if err != nil {
+		return entity.Recurrence{}, err
+	}
+
+	return rec, nil
+}
+
+func (ps *ProjectService) TaskRecurrence(ctx context.Context, taskID int64) (entity.Recurrence, error) {
+	_, err := ps.access.authorizeTask(ctx, taskID, entity.PermViewProject)
+	if err != nil {
+		return entity.Recurrence{}, err
+	}
+
+	return ps.task.Recurrence(ctx, taskID)
+}
+
+func (ps *ProjectService) DeleteTaskRecurrence(ctx context.Context, taskID int64) error {
+	_, err := ps.access.authorizeTask(ctx, taskID, entity.PermEditTasks)
+	if err != nil {
+		return err
+	}
+
+	return ps.task.DeleteRecurrence(ctx, taskID)
+}
+
+// GenerateRecurringTasks creates the next occurrence of every recurring task whose latest occurrence
+// is done or whose next occurrence date has arrived. Occurrences copy the template with its assignees and labels.
+func (ps *ProjectService) GenerateRecurringTasks(ctx context.Context, l slog.Logger) error {
+	now := time.Now()
+
+	recs, err := ps.task.DueRecurrences(ctx, now)
+	if err != nil {
+		return err
+	}
+
+	// a broken recurrence is skipped so it doesn't hold up the others
+	for _, rec := range recs {
+		occurrence, created, err := ps.createOccurrence(ctx, rec, now)
+		if err != nil {
+			l.Error("Recurring occurrence error", "task_id", rec.TaskID, "error", err)
+			continue
+		}
+
+		if created {
+			l.Info("recurring occurrence created", "task_id", rec.TaskID, "occurrence_id", occurrence.ID)
+		}
+	}
+
+	return nil
+}
+
+// createOccurrence creates the next occurrence of the recurrence template due at its next date.
+func (ps *ProjectService) createOccurrence(ctx context.Context, rec entity.Recurrence, now time.Time) (entity.Task, bool, error) {
+	rrule, err := entity.ParseRRule(rec.Rule)
+	if err != nil {
+		return entity.Task{}, false, err
+	}
+
+	template, err := ps.task.TaskByID(ctx, rec.TaskID)
+	if err != nil {
+		return entity.Task{}, false, err
+	}
+
+	workflow, err := ps.project.ProjectWorkflow(ctx, template.ProjectID)
+	if err != nil {
+		return entity.Task{}, false, err
+	}
+
+	dueAt := *rec.NextAt
+
+	occurrence := entity.Task{
+		Name:         template.Name,
+		UserID:       template.UserID,
+		Description:  template.Description,
+		ProjectID:    template.ProjectID,
+		ParentID:     template.ParentID,
+		Status:       workflow.Initial(),
+		Priority:     template.Priority,
+		Assignees:    template.Assignees,
+		Labels:       template.Labels,
+		CustomFields: template.CustomFields,
+		DueAt:        &dueAt,
+		CreatedAt:    now,
+	}
+
+	nextAt, occurrences := nextOccurrence(rrule, rec.StartsAt, dueAt, rec.Occurrences+1, now)
+
+	return ps.task.CreateOccurrence(ctx, rec, occurrence, nextAt, occurrences)
+}
+
+// nextOccurrence returns the first occurrence after prev, the n-th one, that is later than now along with
+// the number of occurrences before it. Nil means the series ends first.
+func nextOccurrence(rrule entity.RRule, start time.Time, prev time.Time, n int, now time.Time) (*time.Time, int) {
+	for {
+		next, ok := rrule.Next(start, prev, n)
+		if !ok {
+			return nil, n
+		}
+
+		if next.After(now) {
+			return &next, n
+		}
+
+		prev, n = next, n+1
+	}
+}
+
+// AddProjectMember accepts the invitation, the invited user is recorded as the actor
+// since accepting doesn't require signing in.
+func (ps *ProjectService) AddProjectMember(ctx context.Context, code string) error {
+	membership, added, err := ps.project.AddProjectMember(ctx, code)
+	if err != nil {
+		return err
+	}
+
+	if !added {
+		return nil
+	}
+
+	recordChanges(ctx, ps.history, membership.UserID, membership.ProjectID, nil, entity.NewChange("member", nil, membership))
+
+	ps.recordActivity(ctx, membership.UserID, entity.Activity{ProjectID: membership.ProjectID, Type: entity.ActivityMemberJoined})
+
+	return nil
+}
+
+func (ps *ProjectService) InviteMemberRequest(ctx context.Context, projectID int64, email string, role entity.Role) error {
+	if role == "" {
+		role = entity.RoleMember
+	}
+
+	err := role.ValidateAssignable()
+	if err != nil {
+		return err
+	}
+
+	project, err := ps.access.authorize(ctx, projectID, entity.PermInviteMembers)
+	if err != nil {
+		return err
+	}
+
+	user, err := ps.auth.UserByEmail(ctx, email)
+	if err != nil {
+		return err
+	}
+
+	code := uuid.NewString()
+
+	err = ps.project.SaveInvitationCode(ctx, code, user.ID, projectID, role)
+	if err != nil {
+		return err
+	}
+
+	err = ps.SendInvite(ctx, email, code, project.Name)
+	if err != nil {
+		return err
+	}
+
+	inviter := entity.AuthUser(ctx)
+
+	ps.recordActivity(ctx, inviter.ID, entity.Activity{ProjectID: projectID, Type: entity.ActivityInvitationSent, Subject: user.Name})
+
+	return nil
+}
+
+func (ps *ProjectService) SendInvite(ctx context.Context, email string, code string, projectName string) error {
+	message := map[string]string{
+		"subject":  "Invitation",
+		"receiver": email,
+		"message":  fmt.Sprintf("You are invited to %s\nFollow link to accept invitation\n http://localhost:8080/projects/invite?code=%s", projectName, code),
+	}
+
+	b, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	msg := kafka.Message{
+		Key:   []byte(code),
+		Value: b,
+	}
+
+	_, err = ps.kafka.WriteMessages(msg)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func sameTime(a *time.Time, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return a.Equal(*b)
+}
+
+func (ps *ProjectService) TaskHistory(ctx context.Context, taskID int64, pr entity.PageRequest) (entity.Page[entity.Change], error) {
+	_, err := ps.access.authorizeTask(ctx, taskID, entity.PermViewProject)
+	if err != nil {
+		return entity.Page[entity.Change]{}, err
+	}
+
+	return ps.history.TaskHistory(ctx, taskID, pr)
+}
+
+func (ps *ProjectService) ProjectHistory(ctx context.Context, projectID int64, pr entity.PageRequest) (entity.Page[entity.Change], error) {
+	_, err := ps.access.authorize(ctx, projectID, entity.PermViewProject)
+	if err != nil {
+		return entity.Page[entity.Change]{}, err
+	}
+
+	return ps.history.ProjectHistory(ctx, projectID, pr)
+}
+
+func (ps *ProjectService) ProjectActivity(ctx context.Context, projectID int64, pr entity.PageRequest) (entity.Page[entity.Activity], error) {
+	_, err := ps.access.authorize(ctx, projectID, entity.PermViewProject)
+	if err != nil {
+		return entity.Page[entity.Activity]{}, err
+	}
+
+	return ps.activity.ProjectActivity(ctx, projectID, pr)
+}
+
+// UserActivity returns the feed of all projects the authenticated user is a member of.
+func (ps *ProjectService) UserActivity(ctx context.Context, pr entity.PageRequest) (entity.Page[entity.Activity], error) {
+	user := entity.AuthUser(ctx)
+	return ps.activity.UserActivity(ctx, user.ID, pr)
+}
+
+func (ps *ProjectService) recordActivity(ctx context.Context, actorID int64, a entity.Activity) {
+	recordActivity(ctx, ps.activity, actorID, a)
+}
+
+// recordTaskActivity adds an activity of the authenticated user on the task to the feed.
+func (ps *ProjectService) recordTaskActivity(ctx context.Context, kind string, task entity.Task) {
+	user := entity.AuthUser(ctx)
+	ps.recordActivity(ctx, user.ID, entity.Activity{ProjectID: task.ProjectID, Type: kind, TaskID: &task.ID, Subject: task.Name})
+}
+
+// recordTaskChanges appends changes the authenticated user made to the task to the history.
+func (ps *ProjectService) recordTaskChanges(ctx context.Context, task entity.Task, changes ...entity.Change) {
+	user := entity.AuthUser(ctx)
+	recordChanges(ctx, ps.history, user.ID, task.ProjectID, &task.ID, changes...)
+}
+
+// recordProjectChanges appends changes the authenticated user made to the project to the history.
+func (ps *ProjectService) recordProjectChanges(ctx context.Context, projectID int64, changes ...entity.Change) {
+	user := entity.AuthUser(ctx)
+	recordChanges(ctx, ps.history, user.ID, projectID, nil, changes...)
+}
+
+func labelIDs(labels []entity.Label) []int64 {
+	var ids []int64
+	for _, l := range labels {
+		ids = append(ids, l.ID)
+	}
+
+	return ids
+}
+
+// without returns a copy of ids with id removed.
+func without(ids []int64, id int64) []int64 {
+	var rest []int64
+	for _, v := range ids {
+		if v != id {
+			rest = append(rest, v)
+		}
+	}
+
+	return rest
+}
+
+func sameID(a *int64, b *int64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return *a == *b
+}
@@ -0,0 +1,32 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"task-manager/entity"
+)
+
+type SearchRepository interface {
+	Search(ctx context.Context, userID int64, text string, limit int) ([]entity.SearchResult, error)
+}
+
+type SearchService struct {
+	search SearchRepository
+}
+
+func NewSearchService(search SearchRepository) *SearchService {
+	return &SearchService{search: search}
+}
+
+// Search looks for tasks and projects matching text in projects the authenticated user is a member of.
+func (ss *SearchService) Search(ctx context.Context, text string, pr entity.PageRequest) ([]entity.SearchResult, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, fmt.Errorf("%w: empty search query", entity.ErrBadRequest)
+	}
+
+	user := entity.AuthUser(ctx)
+
+	return ss.search.Search(ctx, user.ID, text, pr.Size())
+}
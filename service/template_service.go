@@ -0,0 +1,178 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"task-manager/entity"
+	"time"
+)
+
+type TemplateRepository interface {
+	CreateTemplate(ctx context.Context, tmpl entity.ProjectTemplate) (entity.ProjectTemplate, error)
+	TemplateByID(ctx context.Context, id int64) (entity.ProjectTemplate, error)
+	UserTemplates(ctx context.Context, userID int64, pr entity.PageRequest) (entity.Page[entity.ProjectTemplate], error)
+	DeleteTemplate(ctx context.Context, id int64) error
+	CreateTemplateTasks(ctx context.Context, projectID int64, userID int64, tasks []entity.TemplateTask, createdAt time.Time) ([]entity.Task, error)
+}
+
+// TemplateService manages project templates of the authenticated user, templates are visible to their owner only.
+type TemplateService struct {
+	templates TemplateRepository
+	project   ProjectRepository
+	task      TaskRepository
+	history   HistoryRepository
+	activity  ActivityRepository
+	access    projectAccess
+}
+
+func NewTemplateService(templates TemplateRepository, project ProjectRepository, task TaskRepository, history HistoryRepository, activity ActivityRepository) *TemplateService {
+	return &TemplateService{
+		templates: templates,
+		project:   project,
+		task:      task,
+		history:   history,
+		activity:  activity,
+		access:    projectAccess{project: project, task: task},
+	}
+}
+
+// CreateTemplate saves a template described from scratch.
+func (ts *TemplateService) CreateTemplate(ctx context.Context, tmpl entity.ProjectTemplate) (entity.ProjectTemplate, error) {
+	err := tmpl.Validate()
+	if err != nil {
+		return entity.ProjectTemplate{}, err
+	}
+
+	user := entity.AuthUser(ctx)
+
+	tmpl.UserID = user.ID
+	tmpl.CreatedAt = time.Now()
+
+	return ts.templates.CreateTemplate(ctx, tmpl)
+}
+
+// SaveProjectTemplate captures the workflow and tasks of the project, with their descriptions, hierarchy
+// and board order, as a template of the authenticated user.
+func (ts *TemplateService) SaveProjectTemplate(ctx context.Context, projectID int64, name string) (entity.ProjectTemplate, error) {
+	_, err := ts.access.authorize(ctx, projectID, entity.PermViewProject)
+	if err != nil {
+		return entity.ProjectTemplate{}, err
+	}
+
+	workflow, err := ts.project.ProjectWorkflow(ctx, projectID)
+	if err != nil {
+		return entity.ProjectTemplate{}, err
+	}
+
+	tasks, err := ts.task.BoardTasks(ctx, projectID)
+	if err != nil {
+		return entity.ProjectTemplate{}, err
+	}
+
+	return ts.CreateTemplate(ctx, entity.NewProjectTemplate(name, workflow, tasks))
+}
+
+func (ts *TemplateService) TemplateByID(ctx context.Context, id int64) (entity.ProjectTemplate, error) {
+	return ts.userTemplate(ctx, id)
+}
+
+func (ts *TemplateService) UserTemplates(ctx context.Context, pr entity.PageRequest) (entity.Page[entity.ProjectTemplate], error) {
+	user := entity.AuthUser(ctx)
+	return ts.templates.UserTemplates(ctx, user.ID, pr)
+}
+
+func (ts *TemplateService) DeleteTemplate(ctx context.Context, id int64) error {
+	_, err := ts.userTemplate(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return ts.templates.DeleteTemplate(ctx, id)
+}
+
+// CreateProjectFromTemplate creates a project owned by the authenticated user with the template workflow and tasks.
+func (ts *TemplateService) CreateProjectFromTemplate(ctx context.Context, templateID int64, name string) (entity.Project, error) {
+	if name == "" {
+		return entity.Project{}, fmt.Errorf("%w: empty project name", entity.ErrBadRequest)
+	}
+
+	tmpl, err := ts.userTemplate(ctx, templateID)
+	if err != nil {
+		return entity.Project{}, err
+	}
+
+	user := entity.AuthUser(ctx)
+
+	project, tasks, err := ts.project.CreateProjectFromTemplate(ctx, entity.Project{Name: name, UserID: user.ID, CreatedAt: time.Now()}, tmpl)
+	if err != nil {
+		return entity.Project{}, err
+	}
+
+	recordChanges(ctx, ts.history, user.ID, project.ID, nil, entity.NewChange(entity.FieldCreated, nil, project))
+
+	ts.recordCreatedTasks(ctx, tasks)
+
+	recordActivity(ctx, ts.activity, user.ID, entity.Activity{ProjectID: project.ID, Type: entity.ActivityProjectCreated})
+
+	return project, nil
+}
+
+// ApplyTemplate adds tasks of the template to an existing project. Tasks whose status the project workflow
+// doesn't have start in its initial status.
+func (ts *TemplateService) ApplyTemplate(ctx context.Context, templateID int64, projectID int64) ([]entity.Task, error) {
+	tmpl, err := ts.userTemplate(ctx, templateID)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = ts.access.authorize(ctx, projectID, entity.PermEditTasks)
+	if err != nil {
+		return nil, err
+	}
+
+	workflow, err := ts.project.ProjectWorkflow(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, t := range tmpl.Tasks {
+		if !workflow.HasStatus(t.Status) {
+			tmpl.Tasks[i].Status = workflow.Initial()
+		}
+	}
+
+	user := entity.AuthUser(ctx)
+
+	tasks, err := ts.templates.CreateTemplateTasks(ctx, projectID, user.ID, tmpl.Tasks, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	ts.recordCreatedTasks(ctx, tasks)
+
+	return tasks, nil
+}
+
+func (ts *TemplateService) recordCreatedTasks(ctx context.Context, tasks []entity.Task) {
+	user := entity.AuthUser(ctx)
+
+	for _, task := range tasks {
+		recordChanges(ctx, ts.history, user.ID, task.ProjectID, &task.ID, entity.NewChange(entity.FieldCreated, nil, task))
+	}
+}
+
+// userTemplate returns the template if the authenticated user owns it, others' templates don't exist for them.
+func (ts *TemplateService) userTemplate(ctx context.Context, id int64) (entity.ProjectTemplate, error) {
+	tmpl, err := ts.templates.TemplateByID(ctx, id)
+	if err != nil {
+		return entity.ProjectTemplate{}, err
+	}
+
+	user := entity.AuthUser(ctx)
+
+	if tmpl.UserID != user.ID {
+		return entity.ProjectTemplate{}, entity.ErrNotFound
+	}
+
+	return tmpl, nil
+}
@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"task-manager/entity"
+	"time"
+)
+
+type TimeRepository interface {
+	StartTimer(ctx context.Context, e entity.TimeEntry) (entity.TimeEntry, error)
+	StopTimer(ctx context.Context, userID int64, at time.Time) (entity.TimeEntry, error)
+	RunningTimer(ctx context.Context, userID int64) (entity.TimeEntry, error)
+	CreateTimeEntry(ctx context.Context, e entity.TimeEntry) (entity.TimeEntry, error)
+	TimeEntryByID(ctx context.Context, id int64) (entity.TimeEntry, error)
+	TaskTimeEntries(ctx context.Context, taskID int64, pr entity.PageRequest) (entity.Page[entity.TimeEntry], error)
+	DeleteTimeEntry(ctx context.Context, id int64) error
+	TaskTimeTotal(ctx context.Context, taskID int64, tq entity.TimeQuery) (entity.TimeTotal, error)
+	ProjectTimeTotal(ctx context.Context, projectID int64, tq entity.TimeQuery) (entity.TimeTotal, error)
+	UserTimeTotal(ctx context.Context, userID int64, tq entity.TimeQuery) (entity.TimeTotal, error)
+}
+
+type TimeService struct {
+	entries TimeRepository
+	access  projectAccess
+}
+
+func NewTimeService(entries TimeRepository, project ProjectRepository, task TaskRepository) *TimeService {
+	return &TimeService{
+		entries: entries,
+		access:  projectAccess{project: project, task: task},
+	}
+}
+
+// StartTimer starts tracking time of the authenticated user on the task, a user can run only one timer at a time.
+func (ts *TimeService) StartTimer(ctx context.Context, taskID int64, note string) (entity.TimeEntry, error) {
+	err := entity.ValidateTimeEntryNote(note)
+	if err != nil {
+		return entity.TimeEntry{}, err
+	}
+
+	_, err = ts.access.authorizeTask(ctx, taskID, entity.PermEditTasks)
+	if err != nil {
+		return entity.TimeEntry{}, err
+	}
+
+	user := entity.AuthUser(ctx)
+	now := time.Now()
+
+	return ts.entries.StartTimer(ctx, entity.TimeEntry{
+		TaskID:    taskID,
+		UserID:    user.ID,
+		StartedAt: now,
+		Note:      note,
+		CreatedAt: now,
+	})
+}
+
+// StopTimer stops the running timer of the authenticated user.
+func (ts *TimeService) StopTimer(ctx context.Context) (entity.TimeEntry, error) {
+	user := entity.AuthUser(ctx)
+	return ts.entries.StopTimer(ctx, user.ID, time.Now())
+}
+
+func (ts *TimeService) RunningTimer(ctx context.Context) (entity.TimeEntry, error) {
+	user := entity.AuthUser(ctx)
+	return ts.entries.RunningTimer(ctx, user.ID)
+}
+
+// CreateTimeEntry records time the authenticated user spent on the task without a timer.
+func (ts *TimeService) CreateTimeEntry(ctx context.Context, taskID int64, tc entity.TimeEntryToCreate) (entity.TimeEntry, error) {
+	now := time.Now()
+
+	err := tc.Validate(now)
+	if err != nil {
+		return entity.TimeEntry{}, err
+	}
+
+	_, err = ts.access.authorizeTask(ctx, taskID, entity.PermEditTasks)
+	if err != nil {
+		return entity.TimeEntry{}, err
+	}
+
+	user := entity.AuthUser(ctx)
+	endedAt := tc.EndedAt()
+
+	return ts.entries.CreateTimeEntry(ctx, entity.TimeEntry{
+		TaskID:    taskID,
+		UserID:    user.ID,
+		StartedAt: tc.StartedAt,
+		EndedAt:   &endedAt,
+		Duration:  tc.Duration,
+		Note:      tc.Note,
+		CreatedAt: now,
+	})
+}
+
+func (ts *TimeService) TaskTimeEntries(ctx context.Context, taskID int64, pr entity.PageRequest) (entity.Page[entity.TimeEntry], error) {
+	_, err := ts.access.authorizeTask(ctx, taskID, entity.PermViewProject)
+	if err != nil {
+		return entity.Page[entity.TimeEntry]{}, err
+	}
+
+	return ts.entries.TaskTimeEntries(ctx, taskID, pr)
+}
+
+// DeleteTimeEntry removes the entry, only its owner may do it.
+func (ts *TimeService) DeleteTimeEntry(ctx context.Context, taskID int64, entryID int64) error {
+	_, err := ts.access.authorizeTask(ctx, taskID, entity.PermViewProject)
+	if err != nil {
+		return err
+	}
+
+	e, err := ts.entries.TimeEntryByID(ctx, entryID)
+	if err != nil {
+		return err
+	}
+
+	if e.TaskID != taskID {
+		return entity.ErrNotFound
+	}
+
+	user := entity.AuthUser(ctx)
+
+	if e.UserID != user.ID {
+		return fmt.Errorf("%w: only the owner may delete the time entry", entity.ErrForbidden)
+	}
+
+	return ts.entries.DeleteTimeEntry(ctx, entryID)
+}
+
+func (ts *TimeService) TaskTimeTotal(ctx context.Context, taskID int64, tq entity.TimeQuery) (entity.TimeTotal, error) {
+	_, err := ts.access.authorizeTask(ctx, taskID, entity.PermViewProject)
+	if err != nil {
+		return entity.TimeTotal{}, err
+	}
+
+	return ts.entries.TaskTimeTotal(ctx, taskID, tq)
+}
+
+func (ts *TimeService) ProjectTimeTotal(ctx context.Context, projectID int64, tq entity.TimeQuery) (entity.TimeTotal, error) {
+	_, err := ts.access.authorize(ctx, projectID, entity.PermViewProject)
+	if err != nil {
+		return entity.TimeTotal{}, err
+	}
+
+	return ts.entries.ProjectTimeTotal(ctx, projectID, tq)
+}
+
+// UserTimeTotal sums up time the authenticated user spent on tasks of all projects.
+func (ts *TimeService) UserTimeTotal(ctx context.Context, tq entity.TimeQuery) (entity.TimeTotal, error) {
+	user := entity.AuthUser(ctx)
+	return ts.entries.UserTimeTotal(ctx, user.ID, tq)
+}
@@ -2,7 +2,6 @@ package service
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"github.com/segmentio/kafka-go"
 	"log/slog"
@@ -15,7 +14,8 @@ type UserRepository interface {
 
 	UserByID(ctx context.Context, id int64) (u entity.User, err error)
 	UsersToSendVIP(ctx context.Context) (users []entity.User, err error)
-	ProjectUsers(ctx context.Context, projectID int64) (users []entity.User, err error)
+	ProjectUsers(ctx context.Context, projectID int64, pr entity.PageRequest) (entity.Page[entity.ProjectMember], error)
+	MembersByNames(ctx context.Context, projectID int64, names []string) (users []entity.User, err error)
 
 	MarkNotification(ctx context.Context, email string, notification string) error
 }
@@ -25,14 +25,18 @@ type UserService struct {
 	user    UserRepository
 	auth    AuthRepository
 	project ProjectRepository
+	history HistoryRepository
+	access  projectAccess
 }
 
-func NewUserService(user UserRepository, auth AuthRepository, project ProjectRepository, kafkaConn *kafka.Conn) *UserService {
+func NewUserService(user UserRepository, auth AuthRepository, project ProjectRepository, history HistoryRepository, kafkaConn *kafka.Conn) *UserService {
 	return &UserService{
 		kafka:   kafkaConn,
 		user:    user,
 		auth:    auth,
 		project: project,
+		history: history,
+		access:  projectAccess{project: project},
 	}
 }
 
@@ -65,33 +69,53 @@ func (us *UserService) DeleteUser(ctx context.Context, id int64) error {
 	return nil
 }
 
-func (us *UserService) ProjectUsers(ctx context.Context, projectID int64) ([]entity.User, error) {
-	user := entity.AuthUser(ctx)
+func (us *UserService) ProjectUsers(ctx context.Context, projectID int64, pr entity.PageRequest) (entity.Page[entity.ProjectMember], error) {
+	_, err := us.access.authorize(ctx, projectID, entity.PermViewProject)
+	if err != nil {
+		return entity.Page[entity.ProjectMember]{}, err
+	}
+
+	return us.user.ProjectUsers(ctx, projectID, pr)
+}
 
-	projects, err := us.project.UserProjects(ctx, user.ID)
+func (us *UserService) ChangeMemberRole(ctx context.Context, projectID int64, userID int64, role entity.Role) error {
+	err := role.ValidateAssignable()
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	result := false
+	project, err := us.access.authorize(ctx, projectID, entity.PermManageRoles)
+	if err != nil {
+		return err
+	}
 
-	for _, v := range projects {
-		if projectID == v.ID {
-			result = true
-			break
-		}
+	if project.UserID == userID {
+		return fmt.Errorf("%w: owner role can't be changed", entity.ErrForbidden)
 	}
 
-	if !result {
-		return nil, fmt.Errorf("%w: not your project", entity.ErrForbidden)
+	previous, err := us.project.MemberRole(ctx, projectID, userID)
+	if err != nil {
+		return err
 	}
 
-	users, err := us.user.ProjectUsers(ctx, projectID)
+	err = us.project.SetMemberRole(ctx, projectID, userID, role)
 	if err != nil {
-		return nil, err
+		return err
+	}
+
+	if previous == role {
+		return nil
 	}
 
-	return users, nil
+	user := entity.AuthUser(ctx)
+	change := entity.NewChange("member",
+		entity.Membership{ProjectID: projectID, UserID: userID, Role: previous},
+		entity.Membership{ProjectID: projectID, UserID: userID, Role: role},
+	)
+
+	recordChanges(ctx, us.history, user.ID, projectID, nil, change)
+
+	return nil
 }
 
 func (us *UserService) SendVIPNotification(ctx context.Context, l slog.Logger) error {
@@ -126,26 +150,5 @@ func (us *UserService) SendVIPNotification(ctx context.Context, l slog.Logger) e
 }
 
 func (us *UserService) sendNotification(ctx context.Context, ntf entity.Notification) error {
-	message := map[string]string{
-		"subject":  fmt.Sprintf("New notification: %s", ntf.Subject),
-		"receiver": ntf.Receiver,
-		"message":  ntf.Message,
-	}
-
-	b, err := json.Marshal(message)
-	if err != nil {
-		return err
-	}
-
-	msg := kafka.Message{
-		Key:   []byte("notification"),
-		Value: b,
-	}
-
-	_, err = us.kafka.WriteMessages(msg)
-	if err != nil {
-		return err
-	}
-
-	return nil
+	return sendNotification(ctx, us.kafka, ntf)
 }